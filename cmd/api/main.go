@@ -7,18 +7,22 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"github.com/user/remind-me/backend/internal/api"
 	"github.com/user/remind-me/backend/internal/config"
 	"github.com/user/remind-me/backend/internal/database"
 	gqlhandler "github.com/user/remind-me/backend/internal/graphql/handler"
 	"github.com/user/remind-me/backend/internal/graphql/resolver"
 	"github.com/user/remind-me/backend/internal/jobs"
 	"github.com/user/remind-me/backend/internal/middleware"
+	"github.com/user/remind-me/backend/internal/models"
 	"github.com/user/remind-me/backend/internal/notification"
 	"github.com/user/remind-me/backend/internal/notification/apns"
 	"github.com/user/remind-me/backend/internal/notification/fcm"
 	"github.com/user/remind-me/backend/internal/notification/slack"
+	"github.com/user/remind-me/backend/internal/outbox"
 	"github.com/user/remind-me/backend/internal/pubsub"
 	"github.com/user/remind-me/backend/internal/repository"
+	"github.com/user/remind-me/backend/internal/scheduler"
 	"github.com/user/remind-me/backend/internal/service"
 	"github.com/user/remind-me/backend/pkg/jwt"
 )
@@ -29,6 +33,9 @@ func main() {
 
 	// Load configuration
 	cfg := config.Load()
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
 
 	// Connect to database
 	db, err := database.Connect(cfg.DatabaseURL)
@@ -40,7 +47,16 @@ func main() {
 	jwtManager := jwt.NewManager(cfg.JWTSecret)
 
 	// Initialize pub/sub hub for GraphQL subscriptions
-	hub := pubsub.NewHub()
+	var hub *pubsub.Hub
+	if cfg.PubSubBackend == "postgres" {
+		hub = pubsub.NewHubWithBackend(pubsub.NewPostgresBackend(db, cfg.PubSubDatabaseURL))
+		if err := hub.Start(context.Background()); err != nil {
+			log.Fatalf("Failed to start pub/sub backend: %v", err)
+		}
+		log.Printf("Pub/sub hub using Postgres LISTEN/NOTIFY")
+	} else {
+		hub = pubsub.NewHub()
+	}
 
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
@@ -49,6 +65,15 @@ func main() {
 	reminderListRepo := repository.NewReminderListRepository(db)
 	notificationSoundRepo := repository.NewNotificationSoundRepository(db)
 	syncRepo := repository.NewSyncRepository(db)
+	idempotencyRepo := repository.NewIdempotencyRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+	notificationDeliveryRepo := repository.NewNotificationDeliveryRepository(db)
+	notificationTargetRepo := repository.NewNotificationTargetRepository(db)
+	uow := repository.NewUnitOfWork(db)
+
+	// Initialize outbox relay; commits wake it so side effects go out promptly
+	outboxRelay := outbox.NewRelay(outboxRepo, 2*time.Second)
+	uow.OnCommit(outboxRelay.Wake)
 
 	// Initialize Slack client for signup notifications
 	var slackClient *slack.Client
@@ -59,65 +84,108 @@ func main() {
 
 	// Initialize services
 	authService := service.NewAuthService(userRepo, jwtManager, slackClient)
-	reminderService := service.NewReminderService(reminderRepo, syncRepo, userRepo)
-	reminderListService := service.NewReminderListService(reminderListRepo, reminderRepo)
-	subscriptionService := service.NewSubscriptionService(cfg, userRepo)
-
-	// Initialize notification clients (may be nil if not configured)
+	reminderService := service.NewReminderService(reminderRepo, syncRepo, userRepo, uow)
+	reminderListService := service.NewReminderListService(reminderListRepo, reminderRepo, uow)
+	subscriptionService := service.NewSubscriptionService(cfg, userRepo, uow)
+	syncService := service.NewSyncService(syncRepo, reminderRepo)
+	idempotencyService := service.NewIdempotencyService(idempotencyRepo)
+	accountService := service.NewAccountService(uow)
+	deviceService := service.NewDeviceService(deviceRepo, userRepo, uow)
+	userService := service.NewUserService(userRepo, uow)
+
+	// Initialize push providers (may be nil if not configured)
 	var notificationDispatcher *notification.Dispatcher
 	var notificationJob *jobs.NotificationJob
+	var digestJob *jobs.DigestJob
+
+	var iosProvider, androidProvider notification.PushProvider
+	if cfg.PushMode == config.PushModeFake {
+		iosProvider = notification.NewFakeProvider(models.DeliveryProviderAPNs)
+		androidProvider = notification.NewFakeProvider(models.DeliveryProviderFCM)
+		log.Printf("Push notifications are recorded in memory (PUSH_MODE=fake)")
+	} else {
+		dryRun := cfg.PushMode == config.PushModeDryRun
+		if dryRun {
+			log.Printf("Push notifications are logged instead of sent (PUSH_MODE=dry-run)")
+		}
 
-	var apnsClient *apns.Client
-	if cfg.APNsKeyID != "" && cfg.APNsTeamID != "" && cfg.APNsPrivateKey != "" {
-		apnsClient, err = apns.NewClient(apns.Config{
-			KeyID:        cfg.APNsKeyID,
-			TeamID:       cfg.APNsTeamID,
-			PrivateKey:   cfg.APNsPrivateKey,
-			BundleID:     cfg.APNsBundleID,
-			IsProduction: cfg.IsProduction(),
-		})
-		if err != nil {
-			log.Printf("Warning: APNs client not initialized: %v", err)
-			apnsClient = nil
+		if dryRun || (cfg.APNsKeyID != "" && cfg.APNsTeamID != "" && cfg.APNsPrivateKey != "") {
+			apnsClient, err := apns.NewClient(apns.Config{
+				KeyID:        cfg.APNsKeyID,
+				TeamID:       cfg.APNsTeamID,
+				PrivateKey:   cfg.APNsPrivateKey,
+				BundleID:     cfg.APNsBundleID,
+				IsProduction: cfg.IsProduction(),
+				DryRun:       dryRun,
+			})
+			if err != nil {
+				log.Printf("Warning: APNs client not initialized: %v", err)
+			} else {
+				iosProvider = notification.NewAPNsProvider(apnsClient)
+			}
 		}
-	}
 
-	var fcmClient *fcm.Client
-	if cfg.FCMProjectID != "" && cfg.FCMPrivateKey != "" {
-		fcmClient, err = fcm.NewClient(fcm.Config{
-			ProjectID:       cfg.FCMProjectID,
-			CredentialsJSON: cfg.FCMPrivateKey,
-		})
-		if err != nil {
-			log.Printf("Warning: FCM client not initialized: %v", err)
-			fcmClient = nil
+		if dryRun || (cfg.FCMProjectID != "" && cfg.FCMPrivateKey != "") {
+			fcmClient, err := fcm.NewClient(fcm.Config{
+				ProjectID:       cfg.FCMProjectID,
+				CredentialsJSON: cfg.FCMPrivateKey,
+				DryRun:          dryRun,
+			})
+			if err != nil {
+				log.Printf("Warning: FCM client not initialized: %v", err)
+			} else {
+				androidProvider = notification.NewFCMProvider(fcmClient)
+			}
 		}
 	}
 
-	if apnsClient != nil || fcmClient != nil {
-		notificationDispatcher = notification.NewDispatcher(apnsClient, fcmClient, deviceRepo)
-		notificationJob = jobs.NewNotificationJob(reminderRepo, notificationDispatcher)
+	if iosProvider != nil || androidProvider != nil {
+		notificationDispatcher = notification.NewDispatcher(iosProvider, androidProvider, deviceRepo, notificationDeliveryRepo, notificationTargetRepo, deviceService, reminderService, notification.Config{
+			Workers:       cfg.NotificationWorkers,
+			APNsPerSecond: cfg.APNsRateLimit,
+			FCMPerSecond:  cfg.FCMRateLimit,
+		})
+		notificationJob = jobs.NewNotificationJob(reminderRepo, userRepo, notificationDispatcher, reminderService)
+		digestJob = jobs.NewDigestJob(userRepo, reminderRepo, notificationDispatcher)
 		log.Printf("Notification dispatcher initialized")
 	}
 
+	// Fire notifications at their due time instead of on the next cron tick
+	var reminderScheduler *scheduler.Scheduler
+	if cfg.SchedulerEnabled && notificationJob != nil {
+		reminderScheduler = scheduler.NewScheduler(scheduler.NewPostgresElection(db, cfg.PubSubDatabaseURL), reminderRepo, notificationJob)
+		go reminderScheduler.Run(context.Background())
+		log.Printf("Notification scheduler started")
+	}
+
 	// Initialize GraphQL resolver
 	gqlResolver := resolver.NewResolver(
 		authService,
 		reminderService,
 		reminderListService,
 		subscriptionService,
+		syncService,
+		accountService,
+		deviceService,
+		userService,
 		userRepo,
 		deviceRepo,
 		reminderRepo,
 		reminderListRepo,
 		notificationSoundRepo,
+		notificationDeliveryRepo,
 		jwtManager,
 		hub,
 		notificationDispatcher,
+		reminderScheduler,
 	)
 
+	// Deliver outbox events to subscriptions and push notifications
+	gqlResolver.RegisterOutboxHandlers(outboxRelay)
+	go outboxRelay.Run(context.Background())
+
 	// Initialize GraphQL handler
-	graphqlHandler := gqlhandler.NewHandler(gqlResolver, jwtManager)
+	graphqlHandler := gqlhandler.NewHandler(gqlResolver, jwtManager, idempotencyService, cfg.IsProduction())
 
 	// Set up Gin
 	if cfg.IsProduction() {
@@ -166,9 +234,37 @@ func main() {
 		c.JSON(200, gin.H{"processed": count})
 	})
 
+	// Cron endpoint for sending daily digests
+	// Called by GCP Cloud Scheduler every 5 minutes
+	r.POST("/api/cron/digests", func(c *gin.Context) {
+		// Verify cron secret
+		authHeader := c.GetHeader("Authorization")
+		if authHeader != "Bearer "+cfg.CronSecret {
+			c.JSON(401, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		if digestJob == nil {
+			c.JSON(503, gin.H{"error": "notification service not configured"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 55*time.Second)
+		defer cancel()
+
+		count, err := digestJob.SendDueDigests(ctx)
+		if err != nil {
+			log.Printf("Error sending daily digests: %v", err)
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"sent": count})
+	})
+
 	// Cron endpoint for cleaning up stale devices
 	// Called by GCP Cloud Scheduler daily
-	deviceCleanupJob := jobs.NewDeviceCleanupJob(deviceRepo)
+	deviceCleanupJob := jobs.NewDeviceCleanupJob(deviceService)
 	r.POST("/api/cron/device-cleanup", func(c *gin.Context) {
 		// Verify cron secret
 		authHeader := c.GetHeader("Authorization")
@@ -216,15 +312,119 @@ func main() {
 		c.JSON(200, gin.H{"purged": count})
 	})
 
+	// Cron endpoint for compacting and expiring the sync event log
+	// Called by GCP Cloud Scheduler daily
+	syncRetentionJob := jobs.NewSyncRetentionJob(syncRepo)
+	r.POST("/api/cron/sync-retention", func(c *gin.Context) {
+		// Verify cron secret
+		authHeader := c.GetHeader("Authorization")
+		if authHeader != "Bearer "+cfg.CronSecret {
+			c.JSON(401, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 55*time.Second)
+		defer cancel()
+
+		// Older cursors must resync
+		result, err := syncRetentionJob.CompactAndPrune(ctx, cfg.SyncRetentionDays)
+		if err != nil {
+			log.Printf("Error running sync retention: %v", err)
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, result)
+	})
+
+	// Cron endpoint for purging expired idempotency keys
+	// Called by GCP Cloud Scheduler daily
+	idempotencyCleanupJob := jobs.NewIdempotencyCleanupJob(idempotencyRepo)
+	r.POST("/api/cron/idempotency-cleanup", func(c *gin.Context) {
+		// Verify cron secret
+		authHeader := c.GetHeader("Authorization")
+		if authHeader != "Bearer "+cfg.CronSecret {
+			c.JSON(401, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 55*time.Second)
+		defer cancel()
+
+		count, err := idempotencyCleanupJob.CleanupExpiredKeys(ctx)
+		if err != nil {
+			log.Printf("Error cleaning up idempotency keys: %v", err)
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"deleted": count})
+	})
+
+	// Cron endpoint for expiring the notification delivery log
+	// Called by GCP Cloud Scheduler daily
+	deliveryRetentionJob := jobs.NewDeliveryRetentionJob(notificationDeliveryRepo, notificationTargetRepo)
+	r.POST("/api/cron/delivery-retention", func(c *gin.Context) {
+		// Verify cron secret
+		authHeader := c.GetHeader("Authorization")
+		if authHeader != "Bearer "+cfg.CronSecret {
+			c.JSON(401, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 55*time.Second)
+		defer cancel()
+
+		count, err := deliveryRetentionJob.PruneDeliveries(ctx, cfg.DeliveryRetentionDays)
+		if err != nil {
+			log.Printf("Error pruning notification deliveries: %v", err)
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"deleted": count})
+	})
+
+	// Operational metrics, protected by the cron secret
+	r.GET("/api/metrics", func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if authHeader != "Bearer "+cfg.CronSecret {
+			c.JSON(401, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		metrics := gin.H{"hub": hub.Stats()}
+		if notificationDispatcher != nil {
+			metrics["notifications"] = notificationDispatcher.Stats()
+		}
+		c.JSON(200, metrics)
+	})
+
+	// Versioned REST API with its OpenAPI document at /api/v1/openapi.json
+	apiHandler := api.NewHandler(reminderService, reminderListService, deviceService, syncService, idempotencyService)
+	apiHandler.RegisterRoutes(r, jwtManager)
+
 	// GraphQL endpoints
-	// Single endpoint that handles both HTTP and WebSocket (for subscriptions)
-	r.POST("/graphql", graphqlHandler.GraphQL)
+	// Single endpoint that handles HTTP, WebSocket and Server-Sent Events (for subscriptions)
+	r.POST("/graphql", func(c *gin.Context) {
+		// graphql-sse clients ask for an event stream
+		if gqlhandler.AcceptsEventStream(c) {
+			graphqlHandler.GraphQLSSE(c)
+			return
+		}
+		graphqlHandler.GraphQL(c)
+	})
 	r.GET("/graphql", func(c *gin.Context) {
 		// Check if this is a WebSocket upgrade request
 		if c.GetHeader("Upgrade") == "websocket" {
 			graphqlHandler.WebSocketHandler(c)
 			return
 		}
+		// Check if this is an SSE subscription (graphql-sse distinct connections mode)
+		if gqlhandler.AcceptsEventStream(c) && c.Query("query") != "" {
+			graphqlHandler.GraphQLSSE(c)
+			return
+		}
 		// Check if this is a GraphQL query via GET (some clients use this)
 		if c.Query("query") != "" {
 			graphqlHandler.GraphQLGet(c)
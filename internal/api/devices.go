@@ -0,0 +1,68 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/remind-me/backend/internal/dto"
+	"github.com/user/remind-me/backend/internal/middleware"
+)
+
+func (h *Handler) listDevices(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	devices, err := h.DeviceService.ListByUser(userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.DeviceListResponse{
+		Devices: devices,
+		Total:   len(devices),
+	})
+}
+
+func (h *Handler) registerDevice(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	var req dto.RegisterDeviceRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.DeviceService.Register(userID, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) getDevice(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.DeviceService.GetByID(userID, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) unregisterDevice(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.DeviceService.Unregister(userID, id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
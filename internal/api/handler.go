@@ -0,0 +1,104 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/user/remind-me/backend/internal/middleware"
+	"github.com/user/remind-me/backend/internal/service"
+	apperrors "github.com/user/remind-me/backend/pkg/errors"
+	"github.com/user/remind-me/backend/pkg/jwt"
+)
+
+// Handler serves the versioned REST API for integrations that can't speak GraphQL
+type Handler struct {
+	ReminderService     *service.ReminderService
+	ReminderListService *service.ReminderListService
+	DeviceService       *service.DeviceService
+	SyncService         *service.SyncService
+	IdempotencyService  *service.IdempotencyService
+}
+
+// NewHandler creates a new REST API handler
+func NewHandler(
+	reminderService *service.ReminderService,
+	reminderListService *service.ReminderListService,
+	deviceService *service.DeviceService,
+	syncService *service.SyncService,
+	idempotencyService *service.IdempotencyService,
+) *Handler {
+	return &Handler{
+		ReminderService:     reminderService,
+		ReminderListService: reminderListService,
+		DeviceService:       deviceService,
+		SyncService:         syncService,
+		IdempotencyService:  idempotencyService,
+	}
+}
+
+// RegisterRoutes mounts the API under /api/v1. The OpenAPI document is public;
+// every other route requires a bearer access token. Write routes honor the
+// Idempotency-Key header.
+func (h *Handler) RegisterRoutes(r *gin.Engine, jwtManager *jwt.Manager) {
+	routes := h.routes()
+	spec := buildOpenAPI(routes)
+
+	v1 := r.Group(basePath)
+	v1.GET("/openapi.json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, spec)
+	})
+
+	authed := v1.Group("", middleware.AuthMiddleware(jwtManager))
+	for _, rt := range routes {
+		handler := rt.handler
+		if isWriteMethod(rt.method) {
+			handler = h.idempotent(handler)
+		}
+		authed.Handle(rt.method, rt.path, handler)
+	}
+}
+
+// respondError writes err using the same {"error": {...}} shape as AuthMiddleware.
+// Errors that aren't AppErrors are logged and reported as internal errors.
+func respondError(c *gin.Context, err error) {
+	appErr := apperrors.GetAppError(err)
+	if appErr == nil {
+		log.Printf("[API] %s %s: %v", c.Request.Method, c.FullPath(), err)
+		appErr = apperrors.New(apperrors.CodeInternalError, "Internal server error", http.StatusInternalServerError)
+	}
+
+	status := appErr.StatusCode
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+	c.JSON(status, gin.H{"error": appErr})
+}
+
+// bindJSON decodes the request body, writing a validation error on failure
+func bindJSON(c *gin.Context, req interface{}) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		respondError(c, apperrors.ValidationError(err.Error()))
+		return false
+	}
+	return true
+}
+
+// pathID parses the :id path parameter, writing a bad request error on failure
+func pathID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, apperrors.New(apperrors.CodeBadRequest, "Invalid id", http.StatusBadRequest))
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// deviceID returns the device the access token was issued to, if any
+func deviceID(c *gin.Context) *uuid.UUID {
+	if id, ok := middleware.GetDeviceID(c); ok {
+		return &id
+	}
+	return nil
+}
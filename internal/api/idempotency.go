@@ -0,0 +1,122 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/remind-me/backend/internal/middleware"
+	"github.com/user/remind-me/backend/internal/service"
+	apperrors "github.com/user/remind-me/backend/pkg/errors"
+)
+
+// storedResponse is what an idempotent REST request replays on retry
+type storedResponse struct {
+	Status int    `json:"status"`
+	Body   []byte `json:"body,omitempty"`
+}
+
+// isWriteMethod reports whether requests with method change state and so honor
+// the Idempotency-Key header
+func isWriteMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// idempotent runs next at most once per user and Idempotency-Key header. Successful
+// responses are stored and replayed on retry with Idempotent-Replayed set. Each route
+// commits its change in a single transaction, so a failed request changed nothing and
+// its key is released for the client to retry with.
+func (h *Handler) idempotent(next gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := strings.TrimSpace(c.GetHeader("Idempotency-Key"))
+		if key == "" {
+			next(c)
+			return
+		}
+		userID := middleware.MustGetUserID(c)
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			respondError(c, apperrors.ValidationError("Failed to read request body"))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		requestHash := service.HashRequest([]byte(c.Request.Method), []byte(c.Request.URL.Path), body)
+
+		reservation, stored, err := h.IdempotencyService.Begin(userID, key, requestHash)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		if stored != nil {
+			var resp storedResponse
+			if err := json.Unmarshal(stored, &resp); err != nil {
+				// The key was first used for a request to another endpoint
+				respondError(c, apperrors.ErrIdempotencyKeyReused)
+				return
+			}
+			c.Header("Idempotent-Replayed", "true")
+			if len(resp.Body) == 0 {
+				c.Status(resp.Status)
+				return
+			}
+			c.Data(resp.Status, "application/json; charset=utf-8", resp.Body)
+			return
+		}
+
+		release := func() {
+			if err := h.IdempotencyService.Release(reservation); err != nil {
+				log.Printf("[API] %s %s: %v", c.Request.Method, c.FullPath(), err)
+			}
+		}
+		defer func() {
+			if r := recover(); r != nil {
+				release()
+				panic(r)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		next(c)
+
+		status := recorder.Status()
+		if status < 200 || status >= 300 {
+			release()
+			return
+		}
+		response, err := json.Marshal(storedResponse{Status: status, Body: recorder.body.Bytes()})
+		if err != nil {
+			release()
+			return
+		}
+		if err := h.IdempotencyService.Complete(reservation, response); err != nil {
+			// The response is already written; the key stays in flight until it goes stale
+			log.Printf("[API] %s %s: %v", c.Request.Method, c.FullPath(), err)
+		}
+	}
+}
+
+// responseRecorder keeps a copy of the response body written through it
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/user/remind-me/backend/internal/middleware"
+	"github.com/user/remind-me/backend/internal/service"
+	"github.com/user/remind-me/backend/internal/service/servicetest"
+)
+
+type idempotencyFixture struct {
+	router  *gin.Engine
+	keys    *servicetest.IdempotencyKeys
+	calls   atomic.Int32
+	status  atomic.Int32 // Status the route responds with
+	release chan struct{}
+}
+
+// newIdempotencyFixture serves POST /items and DELETE /items behind the idempotent
+// wrapper for a signed-in user. POST counts its calls and answers with status;
+// DELETE waits for release first.
+func newIdempotencyFixture(userID uuid.UUID) *idempotencyFixture {
+	gin.SetMode(gin.TestMode)
+	f := &idempotencyFixture{keys: servicetest.NewIdempotencyKeys(), release: make(chan struct{})}
+	f.status.Store(http.StatusCreated)
+	h := &Handler{IdempotencyService: service.NewIdempotencyService(f.keys)}
+
+	f.router = gin.New()
+	f.router.Use(func(c *gin.Context) {
+		c.Set(middleware.UserIDKey, userID)
+		c.Next()
+	})
+	f.router.POST("/items", h.idempotent(func(c *gin.Context) {
+		n := f.calls.Add(1)
+		c.JSON(int(f.status.Load()), gin.H{"call": n})
+	}))
+	f.router.DELETE("/items", h.idempotent(func(c *gin.Context) {
+		<-f.release
+		c.Status(http.StatusNoContent)
+	}))
+	return f
+}
+
+func (f *idempotencyFixture) do(method, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/items", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+	w := httptest.NewRecorder()
+	f.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotentReplaysSuccessfulResponse(t *testing.T) {
+	f := newIdempotencyFixture(uuid.New())
+
+	first := f.do(http.MethodPost, "key-1", `{"title":"x"}`)
+	if first.Code != http.StatusCreated || first.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatalf("first response = %d %v, want 201 not replayed", first.Code, first.Header())
+	}
+
+	retry := f.do(http.MethodPost, "key-1", `{"title":"x"}`)
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+		t.Errorf("retry = %d %s, want %d %s", retry.Code, retry.Body, first.Code, first.Body)
+	}
+	if retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("retry not marked as replayed")
+	}
+	if calls := f.calls.Load(); calls != 1 {
+		t.Errorf("route ran %d times, want 1", calls)
+	}
+}
+
+func TestIdempotentRejectsKeyReuse(t *testing.T) {
+	f := newIdempotencyFixture(uuid.New())
+
+	f.do(http.MethodPost, "key-1", `{"title":"x"}`)
+	if w := f.do(http.MethodPost, "key-1", `{"title":"y"}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("reuse with another body = %d, want 422", w.Code)
+	}
+	if w := f.do(http.MethodDelete, "key-1", ""); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("reuse on another route = %d, want 422", w.Code)
+	}
+	if calls := f.calls.Load(); calls != 1 {
+		t.Errorf("route ran %d times, want 1", calls)
+	}
+}
+
+func TestIdempotentRejectsRequestsInFlight(t *testing.T) {
+	f := newIdempotencyFixture(uuid.New())
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- f.do(http.MethodDelete, "key-1", "") }()
+	for f.keys.Len() == 0 {
+		time.Sleep(time.Millisecond) // Wait for the first request to reserve the key
+	}
+
+	if w := f.do(http.MethodDelete, "key-1", ""); w.Code != http.StatusConflict {
+		t.Errorf("concurrent retry = %d, want 409", w.Code)
+	}
+	close(f.release)
+	if w := <-done; w.Code != http.StatusNoContent {
+		t.Errorf("original request = %d, want 204", w.Code)
+	}
+
+	replay := f.do(http.MethodDelete, "key-1", "")
+	if replay.Code != http.StatusNoContent || replay.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("replay = %d %v, want a replayed 204", replay.Code, replay.Header())
+	}
+}
+
+func TestIdempotentReleasesKeyOnFailure(t *testing.T) {
+	f := newIdempotencyFixture(uuid.New())
+
+	f.status.Store(http.StatusInternalServerError)
+	if w := f.do(http.MethodPost, "key-1", `{}`); w.Code != http.StatusInternalServerError {
+		t.Fatalf("failed request = %d, want 500", w.Code)
+	}
+	if f.keys.Len() != 0 {
+		t.Errorf("key kept after a failed request")
+	}
+
+	f.status.Store(http.StatusCreated)
+	if w := f.do(http.MethodPost, "key-1", `{}`); w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("retry = %d %v, want the route run again", w.Code, w.Header())
+	}
+	if calls := f.calls.Load(); calls != 2 {
+		t.Errorf("route ran %d times, want 2", calls)
+	}
+}
+
+func TestIdempotentWithoutKey(t *testing.T) {
+	f := newIdempotencyFixture(uuid.New())
+
+	f.do(http.MethodPost, "", `{}`)
+	f.do(http.MethodPost, "  ", `{}`)
+	if calls := f.calls.Load(); calls != 2 || f.keys.Len() != 0 {
+		t.Errorf("route ran %d times with %d keys stored, want 2 runs and none stored", calls, f.keys.Len())
+	}
+}
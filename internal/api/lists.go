@@ -0,0 +1,101 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/remind-me/backend/internal/dto"
+	"github.com/user/remind-me/backend/internal/middleware"
+)
+
+func (h *Handler) listReminderLists(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	result, err := h.ReminderListService.List(userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) createReminderList(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	var req dto.CreateReminderListRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.ReminderListService.Create(userID, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, result)
+}
+
+func (h *Handler) reorderReminderLists(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	var req dto.ReorderReminderListsRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.ReminderListService.Reorder(userID, req.IDs)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) getReminderList(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.ReminderListService.GetByID(userID, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) updateReminderList(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateReminderListRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.ReminderListService.Update(userID, id, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) deleteReminderList(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.ReminderListService.Delete(userID, id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,279 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	uuidType = reflect.TypeOf(uuid.UUID{})
+	timeType = reflect.TypeOf(time.Time{})
+)
+
+// buildOpenAPI generates the OpenAPI 3 document for routes. Schemas are derived from
+// the DTO types by reflection, using their json and binding tags.
+func buildOpenAPI(routes []route) map[string]interface{} {
+	gen := &schemaGenerator{schemas: map[string]interface{}{}}
+	paths := map[string]map[string]interface{}{}
+
+	for _, rt := range routes {
+		var params []map[string]interface{}
+		for _, segment := range strings.Split(rt.path, "/") {
+			if strings.HasPrefix(segment, ":") {
+				params = append(params, map[string]interface{}{
+					"name":     strings.TrimPrefix(segment, ":"),
+					"in":       "path",
+					"required": true,
+					"schema":   map[string]interface{}{"type": "string", "format": "uuid"},
+				})
+			}
+		}
+		for _, q := range rt.query {
+			schema := map[string]interface{}{"type": q.schemaType}
+			if q.format != "" {
+				schema["format"] = q.format
+			}
+			params = append(params, map[string]interface{}{
+				"name":        q.name,
+				"in":          "query",
+				"description": q.description,
+				"schema":      schema,
+			})
+		}
+		if isWriteMethod(rt.method) {
+			params = append(params, map[string]interface{}{
+				"name":        "Idempotency-Key",
+				"in":          "header",
+				"description": "Retries with the same key replay the first successful response instead of repeating the change",
+				"schema":      map[string]interface{}{"type": "string", "maxLength": 255},
+			})
+		}
+
+		success := map[string]interface{}{"description": http.StatusText(rt.status)}
+		if rt.response != nil {
+			success["content"] = jsonContent(gen.schemaFor(reflect.TypeOf(rt.response)))
+		}
+
+		op := map[string]interface{}{
+			"operationId": rt.operationID,
+			"summary":     rt.summary,
+			"tags":        []string{rt.tag},
+			"responses": map[string]interface{}{
+				strconv.Itoa(rt.status): success,
+				"default":               map[string]interface{}{"$ref": "#/components/responses/Error"},
+			},
+		}
+		if len(params) > 0 {
+			op["parameters"] = params
+		}
+		if rt.request != nil {
+			op["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content":  jsonContent(gen.schemaFor(reflect.TypeOf(rt.request))),
+			}
+		}
+
+		path := openAPIPath(rt.path)
+		if paths[path] == nil {
+			paths[path] = map[string]interface{}{}
+		}
+		paths[path][strings.ToLower(rt.method)] = op
+	}
+
+	gen.schemas["Error"] = map[string]interface{}{
+		"type":     "object",
+		"required": []string{"code", "message"},
+		"properties": map[string]interface{}{
+			"code":    map[string]interface{}{"type": "string"},
+			"message": map[string]interface{}{"type": "string"},
+			"fields": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type":     "object",
+					"required": []string{"field", "message"},
+					"properties": map[string]interface{}{
+						"field":   map[string]interface{}{"type": "string"},
+						"message": map[string]interface{}{"type": "string"},
+					},
+				},
+			},
+		},
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "Zalt REST API",
+			"version": "1.0.0",
+		},
+		"servers":  []map[string]interface{}{{"url": basePath}},
+		"security": []map[string]interface{}{{"bearerAuth": []string{}}},
+		"paths":    paths,
+		"components": map[string]interface{}{
+			"schemas": gen.schemas,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{
+					"type":         "http",
+					"scheme":       "bearer",
+					"bearerFormat": "JWT",
+				},
+			},
+			"responses": map[string]interface{}{
+				"Error": map[string]interface{}{
+					"description": "Error",
+					"content": jsonContent(map[string]interface{}{
+						"type":     "object",
+						"required": []string{"error"},
+						"properties": map[string]interface{}{
+							"error": map[string]interface{}{"$ref": "#/components/schemas/Error"},
+						},
+					}),
+				},
+			},
+		},
+	}
+}
+
+// openAPIPath converts gin path parameters (:id) to OpenAPI templates ({id})
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + strings.TrimPrefix(segment, ":") + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func jsonContent(schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"application/json": map[string]interface{}{"schema": schema},
+	}
+}
+
+// schemaGenerator collects named struct schemas into components/schemas as it walks types
+type schemaGenerator struct {
+	schemas map[string]interface{}
+}
+
+func (g *schemaGenerator) schemaFor(t reflect.Type) map[string]interface{} {
+	switch t {
+	case uuidType:
+		return map[string]interface{}{"type": "string", "format": "uuid"}
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := g.schemaFor(t.Elem())
+		if _, isRef := schema["$ref"]; isRef {
+			return map[string]interface{}{"allOf": []interface{}{schema}, "nullable": true}
+		}
+		schema["nullable"] = true
+		return schema
+	case reflect.Struct:
+		name := t.Name()
+		if _, seen := g.schemas[name]; !seen {
+			g.schemas[name] = nil // Reserve the name so recursive types terminate
+			g.schemas[name] = g.objectSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": true}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+func (g *schemaGenerator) objectSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+	g.collectFields(t, properties, &required)
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// collectFields adds t's JSON fields to properties, flattening embedded structs
+func (g *schemaGenerator) collectFields(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			g.collectFields(field.Type, properties, required)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema := g.schemaFor(field.Type)
+		for _, rule := range strings.Split(field.Tag.Get("binding"), ",") {
+			key, value, _ := strings.Cut(rule, "=")
+			switch key {
+			case "required":
+				*required = append(*required, name)
+			case "oneof":
+				schema["enum"] = strings.Fields(value)
+			case "min", "max":
+				applyBound(schema, key, value)
+			}
+		}
+		properties[name] = schema
+	}
+}
+
+// applyBound maps a binding min/max rule onto the length or value bound of a schema
+func applyBound(schema map[string]interface{}, key, value string) {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return
+	}
+
+	switch schema["type"] {
+	case "string":
+		if key == "min" {
+			schema["minLength"] = n
+		} else {
+			schema["maxLength"] = n
+		}
+	case "integer", "number":
+		if key == "min" {
+			schema["minimum"] = n
+		} else {
+			schema["maximum"] = n
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/remind-me/backend/internal/dto"
+	"github.com/user/remind-me/backend/internal/middleware"
+	apperrors "github.com/user/remind-me/backend/pkg/errors"
+)
+
+func (h *Handler) listReminders(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	page, _ := strconv.Atoi(c.Query("page"))
+	pageSize, _ := strconv.Atoi(c.Query("page_size"))
+
+	var status *string
+	if s := c.Query("status"); s != "" {
+		status = &s
+	}
+
+	fromDate, ok := queryTime(c, "from")
+	if !ok {
+		return
+	}
+	toDate, ok := queryTime(c, "to")
+	if !ok {
+		return
+	}
+
+	result, err := h.ReminderService.List(userID, page, pageSize, status, fromDate, toDate)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) createReminder(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	var req dto.CreateReminderRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.ReminderService.Create(userID, req, deviceID(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, result)
+}
+
+func (h *Handler) getReminder(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.ReminderService.GetByID(userID, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) updateReminder(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateReminderRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.ReminderService.Update(userID, id, req, deviceID(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) deleteReminder(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.ReminderService.Delete(userID, id, deviceID(c)); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) snoozeReminder(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.SnoozeReminderRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.ReminderService.Snooze(userID, id, req.Minutes, deviceID(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) completeReminder(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.ReminderService.Complete(userID, id, deviceID(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) dismissReminder(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.ReminderService.Dismiss(userID, id, deviceID(c)); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// queryTime parses an optional RFC 3339 query parameter, writing a validation error on failure
+func queryTime(c *gin.Context, name string) (*time.Time, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		respondError(c, apperrors.ValidationError(name+" must be an RFC 3339 timestamp"))
+		return nil, false
+	}
+	return &t, true
+}
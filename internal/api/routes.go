@@ -0,0 +1,170 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/remind-me/backend/internal/dto"
+)
+
+const basePath = "/api/v1"
+
+// route describes one endpoint. The same table drives routing and the OpenAPI
+// document, so the published spec can't drift from what is served.
+type route struct {
+	method      string
+	path        string // gin syntax, e.g. /reminders/:id
+	tag         string
+	operationID string
+	summary     string
+	query       []queryParam
+	request     interface{} // sample request body, nil if none
+	response    interface{} // sample response body, nil for 204
+	status      int
+	handler     gin.HandlerFunc
+}
+
+// queryParam documents an optional query string parameter
+type queryParam struct {
+	name        string
+	schemaType  string
+	format      string
+	description string
+}
+
+func (h *Handler) routes() []route {
+	return []route{
+		// Reminders
+		{
+			method: http.MethodGet, path: "/reminders", tag: "Reminders",
+			operationID: "listReminders", summary: "List reminders",
+			query: []queryParam{
+				{name: "page", schemaType: "integer", description: "Page number, starting at 1"},
+				{name: "page_size", schemaType: "integer", description: "Results per page (max 100)"},
+				{name: "status", schemaType: "string", description: "Filter by status"},
+				{name: "from", schemaType: "string", format: "date-time", description: "Only reminders due at or after this time"},
+				{name: "to", schemaType: "string", format: "date-time", description: "Only reminders due at or before this time"},
+			},
+			response: dto.ReminderListResponse{}, status: http.StatusOK,
+			handler: h.listReminders,
+		},
+		{
+			method: http.MethodPost, path: "/reminders", tag: "Reminders",
+			operationID: "createReminder", summary: "Create a reminder",
+			request: dto.CreateReminderRequest{}, response: dto.ReminderDTO{}, status: http.StatusCreated,
+			handler: h.createReminder,
+		},
+		{
+			method: http.MethodGet, path: "/reminders/:id", tag: "Reminders",
+			operationID: "getReminder", summary: "Get a reminder",
+			response: dto.ReminderDTO{}, status: http.StatusOK,
+			handler: h.getReminder,
+		},
+		{
+			method: http.MethodPatch, path: "/reminders/:id", tag: "Reminders",
+			operationID: "updateReminder", summary: "Update a reminder",
+			request: dto.UpdateReminderRequest{}, response: dto.ReminderDTO{}, status: http.StatusOK,
+			handler: h.updateReminder,
+		},
+		{
+			method: http.MethodDelete, path: "/reminders/:id", tag: "Reminders",
+			operationID: "deleteReminder", summary: "Delete a reminder",
+			status:  http.StatusNoContent,
+			handler: h.deleteReminder,
+		},
+		{
+			method: http.MethodPost, path: "/reminders/:id/snooze", tag: "Reminders",
+			operationID: "snoozeReminder", summary: "Snooze a reminder",
+			request: dto.SnoozeReminderRequest{}, response: dto.ReminderDTO{}, status: http.StatusOK,
+			handler: h.snoozeReminder,
+		},
+		{
+			method: http.MethodPost, path: "/reminders/:id/complete", tag: "Reminders",
+			operationID: "completeReminder", summary: "Complete a reminder",
+			response: dto.ReminderDTO{}, status: http.StatusOK,
+			handler: h.completeReminder,
+		},
+		{
+			method: http.MethodPost, path: "/reminders/:id/dismiss", tag: "Reminders",
+			operationID: "dismissReminder", summary: "Dismiss a reminder's notification",
+			status:  http.StatusNoContent,
+			handler: h.dismissReminder,
+		},
+
+		// Lists
+		{
+			method: http.MethodGet, path: "/lists", tag: "Lists",
+			operationID: "listReminderLists", summary: "List reminder lists",
+			response: []dto.ReminderListDTO{}, status: http.StatusOK,
+			handler: h.listReminderLists,
+		},
+		{
+			method: http.MethodPost, path: "/lists", tag: "Lists",
+			operationID: "createReminderList", summary: "Create a reminder list",
+			request: dto.CreateReminderListRequest{}, response: dto.ReminderListDTO{}, status: http.StatusCreated,
+			handler: h.createReminderList,
+		},
+		{
+			method: http.MethodPut, path: "/lists/order", tag: "Lists",
+			operationID: "reorderReminderLists", summary: "Reorder reminder lists",
+			request: dto.ReorderReminderListsRequest{}, response: []dto.ReminderListDTO{}, status: http.StatusOK,
+			handler: h.reorderReminderLists,
+		},
+		{
+			method: http.MethodGet, path: "/lists/:id", tag: "Lists",
+			operationID: "getReminderList", summary: "Get a reminder list",
+			response: dto.ReminderListDTO{}, status: http.StatusOK,
+			handler: h.getReminderList,
+		},
+		{
+			method: http.MethodPatch, path: "/lists/:id", tag: "Lists",
+			operationID: "updateReminderList", summary: "Update a reminder list",
+			request: dto.UpdateReminderListRequest{}, response: dto.ReminderListDTO{}, status: http.StatusOK,
+			handler: h.updateReminderList,
+		},
+		{
+			method: http.MethodDelete, path: "/lists/:id", tag: "Lists",
+			operationID: "deleteReminderList", summary: "Delete a reminder list and its reminders",
+			status:  http.StatusNoContent,
+			handler: h.deleteReminderList,
+		},
+
+		// Devices
+		{
+			method: http.MethodGet, path: "/devices", tag: "Devices",
+			operationID: "listDevices", summary: "List registered devices",
+			response: dto.DeviceListResponse{}, status: http.StatusOK,
+			handler: h.listDevices,
+		},
+		{
+			method: http.MethodPost, path: "/devices", tag: "Devices",
+			operationID: "registerDevice", summary: "Register a device for push notifications",
+			request: dto.RegisterDeviceRequest{}, response: dto.DeviceDTO{}, status: http.StatusOK,
+			handler: h.registerDevice,
+		},
+		{
+			method: http.MethodGet, path: "/devices/:id", tag: "Devices",
+			operationID: "getDevice", summary: "Get a device",
+			response: dto.DeviceDTO{}, status: http.StatusOK,
+			handler: h.getDevice,
+		},
+		{
+			method: http.MethodDelete, path: "/devices/:id", tag: "Devices",
+			operationID: "unregisterDevice", summary: "Unregister a device",
+			status:  http.StatusNoContent,
+			handler: h.unregisterDevice,
+		},
+
+		// Sync
+		{
+			method: http.MethodGet, path: "/sync/changes", tag: "Sync",
+			operationID: "getChanges", summary: "Get changes after a sync cursor",
+			query: []queryParam{
+				{name: "cursor", schemaType: "string", description: "Cursor from the previous response; omit for a full history"},
+				{name: "limit", schemaType: "integer", description: "Maximum number of changes to return"},
+			},
+			response: dto.SyncResponse{}, status: http.StatusOK,
+			handler: h.getChanges,
+		},
+	}
+}
@@ -0,0 +1,21 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user/remind-me/backend/internal/middleware"
+)
+
+func (h *Handler) getChanges(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	result, err := h.SyncService.GetChangesSince(userID, c.Query("cursor"), limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
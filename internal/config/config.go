@@ -1,7 +1,16 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"strconv"
+)
+
+// Push modes
+const (
+	PushModeLive   = "live"
+	PushModeDryRun = "dry-run"
+	PushModeFake   = "fake"
 )
 
 type Config struct {
@@ -19,6 +28,21 @@ type Config struct {
 	APNsBundleID   string
 	FCMProjectID   string
 	FCMPrivateKey  string
+	// "live" sends through APNs/FCM, "dry-run" logs the requests that would be sent and
+	// "fake" records pushes in memory; the last two need no credentials. Defaults to
+	// live in production and dry-run elsewhere.
+	PushMode string
+
+	// Days of per-device notification delivery history to keep
+	DeliveryRetentionDays int
+
+	// Days of sync history to keep; clients with older cursors must resync
+	SyncRetentionDays int
+
+	// Push dispatch: sends in flight at once and per-provider sends per second (0 = unlimited)
+	NotificationWorkers int
+	APNsRateLimit       int
+	FCMRateLimit        int
 
 	// RevenueCat
 	RevenueCatAPIKey string
@@ -29,12 +53,25 @@ type Config struct {
 	// Cron
 	CronSecret string
 
+	// Run the in-process notification scheduler; the cron endpoint stays available as a fallback
+	SchedulerEnabled bool
+
+	// PubSub
+	PubSubBackend     string // "memory" (single instance) or "postgres"
+	PubSubDatabaseURL string // Direct (unpooled) connection used for LISTEN and the scheduler lock
+
 	// Server
 	Port        string
 	Environment string
 }
 
 func Load() *Config {
+	environment := getEnv("ENVIRONMENT", "development")
+	defaultPushMode := PushModeDryRun
+	if environment == "production" {
+		defaultPushMode = PushModeLive
+	}
+
 	return &Config{
 		// Database
 		DatabaseURL: getEnv("DATABASE_URL", ""),
@@ -50,6 +87,15 @@ func Load() *Config {
 		APNsBundleID:   getEnv("APNS_BUNDLE_ID", "com.remindme.app"),
 		FCMProjectID:   getEnv("FCM_PROJECT_ID", ""),
 		FCMPrivateKey:  getEnv("FCM_PRIVATE_KEY", ""),
+		PushMode:       getEnv("PUSH_MODE", defaultPushMode),
+
+		DeliveryRetentionDays: getEnvInt("DELIVERY_RETENTION_DAYS", 30),
+
+		SyncRetentionDays: getEnvInt("SYNC_RETENTION_DAYS", 30),
+
+		NotificationWorkers: getEnvInt("NOTIFICATION_WORKERS", 50),
+		APNsRateLimit:       getEnvInt("APNS_RATE_LIMIT", 0),
+		FCMRateLimit:        getEnvInt("FCM_RATE_LIMIT", 0),
 
 		// RevenueCat
 		RevenueCatAPIKey: getEnv("REVENUECAT_API_KEY", ""),
@@ -60,12 +106,31 @@ func Load() *Config {
 		// Cron
 		CronSecret: getEnv("CRON_SECRET", ""),
 
+		SchedulerEnabled: getEnvBool("SCHEDULER_ENABLED", false),
+
+		// PubSub
+		PubSubBackend:     getEnv("PUBSUB_BACKEND", "memory"),
+		PubSubDatabaseURL: getEnv("PUBSUB_DATABASE_URL", getEnv("DATABASE_URL", "")),
+
 		// Server
 		Port:        getEnv("PORT", "8080"),
-		Environment: getEnv("ENVIRONMENT", "development"),
+		Environment: environment,
 	}
 }
 
+// Validate rejects settings the server can't run with
+func (c *Config) Validate() error {
+	switch c.PushMode {
+	case PushModeLive, PushModeDryRun, PushModeFake:
+	default:
+		return fmt.Errorf("PUSH_MODE must be one of %s, %s, %s; got %q", PushModeLive, PushModeDryRun, PushModeFake, c.PushMode)
+	}
+	if c.SyncRetentionDays < 1 {
+		return fmt.Errorf("SYNC_RETENTION_DAYS must be at least 1; got %d", c.SyncRetentionDays)
+	}
+	return nil
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -73,6 +138,20 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil && value > 0 {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
 func (c *Config) IsProduction() bool {
 	return c.Environment == "production"
 }
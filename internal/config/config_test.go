@@ -0,0 +1,27 @@
+package config
+
+import "testing"
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   Config
+		valid bool
+	}{
+		{"live", Config{PushMode: PushModeLive, SyncRetentionDays: 30}, true},
+		{"dry run", Config{PushMode: PushModeDryRun, SyncRetentionDays: 1}, true},
+		{"fake", Config{PushMode: PushModeFake, SyncRetentionDays: 30}, true},
+		{"unknown push mode", Config{PushMode: "silent", SyncRetentionDays: 30}, false},
+		{"empty push mode", Config{SyncRetentionDays: 30}, false},
+		{"no sync retention", Config{PushMode: PushModeLive, SyncRetentionDays: 0}, false},
+		{"negative sync retention", Config{PushMode: PushModeLive, SyncRetentionDays: -7}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err == nil) != tt.valid {
+				t.Errorf("Validate() = %v, want valid %t", err, tt.valid)
+			}
+		})
+	}
+}
@@ -55,6 +55,12 @@ func AutoMigrate(db *gorm.DB) error {
 		&models.Reminder{},
 		&models.ReminderInstance{},
 		&models.SyncEvent{},
+		&models.SyncWatermark{},
+		&models.IdempotencyKey{},
+		&models.OutboxEvent{},
+		&models.PubSubMessage{},
+		&models.NotificationDelivery{},
+		&models.NotificationTarget{},
 	)
 }
 
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS sync_watermarks;
+DROP INDEX IF EXISTS idx_sync_events_user_entity_seq;
+DROP INDEX IF EXISTS idx_sync_events_user_seq;
+DROP INDEX IF EXISTS idx_sync_events_seq;
+ALTER TABLE sync_events DROP COLUMN IF EXISTS seq;
+DROP SEQUENCE IF EXISTS sync_events_seq_seq;
@@ -0,0 +1,32 @@
+-- Monotonic sequence for sync events, used as the client sync cursor.
+-- Existing events are numbered in the order they happened, so replaying from an old
+-- cursor follows time order; new events draw from the sequence.
+CREATE SEQUENCE IF NOT EXISTS sync_events_seq_seq;
+ALTER TABLE sync_events ADD COLUMN IF NOT EXISTS seq BIGINT;
+
+UPDATE sync_events
+SET seq = numbered.rn
+FROM (
+    SELECT id, ROW_NUMBER() OVER (ORDER BY created_at, id) AS rn
+    FROM sync_events
+) numbered
+WHERE sync_events.id = numbered.id AND sync_events.seq IS NULL;
+
+SELECT setval('sync_events_seq_seq', COALESCE((SELECT MAX(seq) FROM sync_events), 0) + 1, false);
+ALTER TABLE sync_events ALTER COLUMN seq SET DEFAULT nextval('sync_events_seq_seq');
+ALTER TABLE sync_events ALTER COLUMN seq SET NOT NULL;
+ALTER SEQUENCE sync_events_seq_seq OWNED BY sync_events.seq;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sync_events_seq ON sync_events(seq);
+CREATE INDEX IF NOT EXISTS idx_sync_events_user_seq ON sync_events(user_id, seq);
+
+-- Entity index used when compacting per-entity history
+CREATE INDEX IF NOT EXISTS idx_sync_events_user_entity_seq ON sync_events(user_id, entity_type, entity_id, seq DESC);
+
+-- Highest sequence removed by retention for each user.
+-- Clients whose cursor is below this value must perform a full resync.
+CREATE TABLE IF NOT EXISTS sync_watermarks (
+    user_id             UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    pruned_through_seq  BIGINT NOT NULL DEFAULT 0,
+    updated_at          TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
@@ -0,0 +1,14 @@
+-- Stored mutation responses keyed by client-supplied Idempotency-Key.
+-- A NULL response marks a request that is still in flight.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    user_id      UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    key          VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    response     JSONB,
+    created_at   TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    expires_at   TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (user_id, key)
+);
+
+-- Index for purging expired keys
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
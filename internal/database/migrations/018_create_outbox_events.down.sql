@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_outbox_events_processed_at;
+DROP INDEX IF EXISTS idx_outbox_events_pending;
+DROP TABLE IF EXISTS outbox_events;
@@ -0,0 +1,20 @@
+-- Transactional outbox for side effects (subscription broadcasts, cross-device pushes).
+-- Rows are written in the same transaction as the change and delivered by a background relay.
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id           BIGSERIAL PRIMARY KEY,
+    user_id      UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind         VARCHAR(50) NOT NULL,
+    payload      JSONB NOT NULL,
+    status       VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts     INTEGER NOT NULL DEFAULT 0,
+    last_error   TEXT,
+    available_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    processed_at TIMESTAMP WITH TIME ZONE,
+    created_at   TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Index for the relay picking up due events
+CREATE INDEX IF NOT EXISTS idx_outbox_events_pending ON outbox_events(available_at, id) WHERE status = 'pending';
+
+-- Index for purging delivered events
+CREATE INDEX IF NOT EXISTS idx_outbox_events_processed_at ON outbox_events(processed_at) WHERE status = 'done';
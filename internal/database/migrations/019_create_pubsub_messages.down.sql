@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_pubsub_messages_created_at;
+DROP TABLE IF EXISTS pubsub_messages;
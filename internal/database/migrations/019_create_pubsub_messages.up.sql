@@ -0,0 +1,12 @@
+-- Hub events shared between instances. NOTIFY only carries the row id,
+-- which keeps notifications far below the 8KB payload limit.
+CREATE TABLE IF NOT EXISTS pubsub_messages (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    UUID NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    payload    JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Index for purging delivered messages
+CREATE INDEX IF NOT EXISTS idx_pubsub_messages_created_at ON pubsub_messages(created_at);
@@ -0,0 +1 @@
+ALTER TABLE pubsub_messages DROP COLUMN IF EXISTS topic;
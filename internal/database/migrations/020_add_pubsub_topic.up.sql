@@ -0,0 +1,2 @@
+-- Hub events are routed by topic so subscribers only receive what they asked for
+ALTER TABLE pubsub_messages ADD COLUMN IF NOT EXISTS topic VARCHAR(50) NOT NULL DEFAULT '';
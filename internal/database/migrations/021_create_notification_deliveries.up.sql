@@ -0,0 +1,20 @@
+-- One row per push attempt to one device, so support can see why a reminder did or didn't fire.
+-- device_id has no foreign key: the log outlives devices pruned for invalid tokens.
+CREATE TABLE IF NOT EXISTS notification_deliveries (
+    id                  BIGSERIAL PRIMARY KEY,
+    reminder_id         UUID NOT NULL REFERENCES reminders(id) ON DELETE CASCADE,
+    user_id             UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    device_id           UUID NOT NULL,
+    provider            VARCHAR(10) NOT NULL,
+    provider_message_id VARCHAR(255),
+    status              VARCHAR(20) NOT NULL,
+    error               TEXT,
+    latency_ms          INTEGER NOT NULL DEFAULT 0,
+    created_at          TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Index for listing a reminder's deliveries, newest first
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_reminder ON notification_deliveries(reminder_id, created_at DESC);
+
+-- Index for purging deliveries past the retention window
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_created_at ON notification_deliveries(created_at);
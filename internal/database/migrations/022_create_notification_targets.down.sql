@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_notification_targets_updated_at;
+DROP INDEX IF EXISTS idx_notification_targets_occurrence;
+DROP TABLE IF EXISTS notification_targets;
@@ -0,0 +1,21 @@
+-- Delivery state of one occurrence of a reminder on one device. Lets the notification job
+-- retry failed devices with backoff without re-alerting devices that already got the push.
+-- device_id has no foreign key, matching notification_deliveries.
+CREATE TABLE IF NOT EXISTS notification_targets (
+    id              BIGSERIAL PRIMARY KEY,
+    reminder_id     UUID NOT NULL REFERENCES reminders(id) ON DELETE CASCADE,
+    device_id       UUID NOT NULL,
+    due_at          TIMESTAMP WITH TIME ZONE NOT NULL,
+    status          VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE,
+    last_error      TEXT,
+    created_at      TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at      TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- One target per device per occurrence
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notification_targets_occurrence ON notification_targets(reminder_id, due_at, device_id);
+
+-- Index for purging targets past the retention window
+CREATE INDEX IF NOT EXISTS idx_notification_targets_updated_at ON notification_targets(updated_at);
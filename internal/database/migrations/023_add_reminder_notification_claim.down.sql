@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_reminders_notification_due;
+ALTER TABLE reminders DROP COLUMN IF EXISTS notification_claimed_until;
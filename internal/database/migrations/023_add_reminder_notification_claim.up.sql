@@ -0,0 +1,9 @@
+-- Lease on a due reminder held by the notification worker sending it. Workers claim due
+-- reminders with UPDATE ... FOR UPDATE SKIP LOCKED, so concurrent runs never pick the same
+-- row; a worker that dies mid-send leaves the lease to expire and the reminder is claimed again.
+-- Reminders waiting on a push retry are leased until the retry is due.
+ALTER TABLE reminders ADD COLUMN IF NOT EXISTS notification_claimed_until TIMESTAMP WITH TIME ZONE;
+
+-- Index for finding due reminders that haven't been notified yet
+CREATE INDEX IF NOT EXISTS idx_reminders_notification_due ON reminders(due_at)
+    WHERE notification_sent_at IS NULL AND deleted_at IS NULL;
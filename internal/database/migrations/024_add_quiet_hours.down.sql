@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_notification_targets_deferred;
+ALTER TABLE devices DROP COLUMN IF EXISTS quiet_hours_end;
+ALTER TABLE devices DROP COLUMN IF EXISTS quiet_hours_start;
+ALTER TABLE devices DROP COLUMN IF EXISTS quiet_hours_override;
+ALTER TABLE users DROP COLUMN IF EXISTS quiet_hours_end;
+ALTER TABLE users DROP COLUMN IF EXISTS quiet_hours_start;
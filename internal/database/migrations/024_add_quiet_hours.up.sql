@@ -0,0 +1,14 @@
+-- Quiet hours: a daily window, in minutes after midnight in the user's timezone, during
+-- which non-alarm pushes are held back and summarized when it ends. A window whose end is
+-- before its start spans midnight. NULL means no quiet hours.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS quiet_hours_start SMALLINT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS quiet_hours_end SMALLINT;
+
+-- A device can replace the user's window with its own, or with none at all
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS quiet_hours_override BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS quiet_hours_start SMALLINT;
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS quiet_hours_end SMALLINT;
+
+-- Index for finding deferred notifications whose quiet hours have ended
+CREATE INDEX IF NOT EXISTS idx_notification_targets_deferred ON notification_targets(next_attempt_at)
+    WHERE status = 'deferred';
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_users_digest_next_at;
+ALTER TABLE users DROP COLUMN IF EXISTS digest_next_at;
+ALTER TABLE users DROP COLUMN IF EXISTS digest_list_ids;
+ALTER TABLE users DROP COLUMN IF EXISTS digest_days;
+ALTER TABLE users DROP COLUMN IF EXISTS digest_time;
+ALTER TABLE users DROP COLUMN IF EXISTS digest_enabled;
@@ -0,0 +1,15 @@
+-- Daily digest preferences: a morning summary of the day's and overdue reminders, sent at
+-- digest_time (minutes after midnight in the user's timezone) on the weekdays set in the
+-- digest_days bitmask (bit 0 = Sunday). An empty digest_list_ids includes every list.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS digest_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS digest_time SMALLINT NOT NULL DEFAULT 480;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS digest_days SMALLINT NOT NULL DEFAULT 127;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS digest_list_ids TEXT[] DEFAULT '{}';
+
+-- When the next digest is due, kept up to date whenever the preferences or timezone change
+-- and after each digest. The digest job leases users by pushing it forward while it sends.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS digest_next_at TIMESTAMP WITH TIME ZONE;
+
+-- Index for finding users whose digest is due
+CREATE INDEX IF NOT EXISTS idx_users_digest_next_at ON users(digest_next_at)
+    WHERE digest_enabled AND deleted_at IS NULL;
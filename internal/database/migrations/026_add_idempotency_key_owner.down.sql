@@ -0,0 +1,2 @@
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS heartbeat_at;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS owner;
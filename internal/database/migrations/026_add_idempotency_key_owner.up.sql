@@ -0,0 +1,5 @@
+-- The request holding an in-flight key refreshes heartbeat_at while it runs, so a retry
+-- only takes the key over once its owner has stopped. Owner identifies the reservation;
+-- a request that lost its key can no longer store a response or release it.
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS owner UUID;
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS heartbeat_at TIMESTAMP WITH TIME ZONE DEFAULT NOW();
@@ -1,5 +1,7 @@
 package dto
 
+import "github.com/google/uuid"
+
 // GoogleAuthRequest is the request body for Google OAuth login
 type GoogleAuthRequest struct {
 	IDToken string `json:"id_token" binding:"required"`
@@ -34,3 +36,20 @@ type UpdateUserRequest struct {
 	DisplayName *string `json:"display_name,omitempty"`
 	Timezone    *string `json:"timezone,omitempty"`
 }
+
+// UpdateQuietHoursRequest sets a user's quiet hours as HH:MM times of day.
+// Start and End are set together; both nil turns quiet hours off.
+type UpdateQuietHoursRequest struct {
+	Start    *string `json:"start,omitempty"`
+	End      *string `json:"end,omitempty"`
+	Timezone *string `json:"timezone,omitempty"` // nil keeps the current timezone
+}
+
+// UpdateDigestSettingsRequest sets a user's daily digest preferences.
+// Nil fields keep their current value.
+type UpdateDigestSettingsRequest struct {
+	Enabled bool        `json:"enabled"`
+	Time    *string     `json:"time,omitempty"`     // HH:MM in the user's timezone
+	Days    []int       `json:"days,omitempty"`     // 0=Sunday, 1=Monday, etc.
+	ListIDs []uuid.UUID `json:"list_ids,omitempty"` // Empty for every list
+}
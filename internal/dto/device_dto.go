@@ -9,11 +9,20 @@ import (
 
 // RegisterDeviceRequest is the request body for registering a device
 type RegisterDeviceRequest struct {
-	Platform   string `json:"platform" binding:"required,oneof=ios android"`
-	PushToken  string `json:"push_token" binding:"required"`
-	DeviceName string `json:"device_name,omitempty"`
-	AppVersion string `json:"app_version,omitempty"`
-	OSVersion  string `json:"os_version,omitempty"`
+	DeviceIdentifier string `json:"device_identifier" binding:"required,max=255"`
+	Platform         string `json:"platform" binding:"required,oneof=ios android"`
+	PushToken        string `json:"push_token" binding:"required"`
+	DeviceName       string `json:"device_name,omitempty"`
+	AppVersion       string `json:"app_version,omitempty"`
+	OSVersion        string `json:"os_version,omitempty"`
+}
+
+// UpdateDeviceQuietHoursRequest sets whether a device overrides its user's quiet hours,
+// and with what. Start and End are set together; both nil means no quiet hours.
+type UpdateDeviceQuietHoursRequest struct {
+	Override bool    `json:"override"`
+	Start    *string `json:"start,omitempty"`
+	End      *string `json:"end,omitempty"`
 }
 
 // DeviceDTO represents a device in responses
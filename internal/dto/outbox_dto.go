@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReminderChangedPayload is the outbox payload for a reminder change broadcast
+type ReminderChangedPayload struct {
+	Action         string       `json:"action"` // create, update or delete
+	ReminderID     uuid.UUID    `json:"reminder_id"`
+	ListID         *uuid.UUID   `json:"list_id,omitempty"`
+	PreviousListID *uuid.UUID   `json:"previous_list_id,omitempty"` // set when an update moved the reminder
+	Reminder       *ReminderDTO `json:"reminder,omitempty"`
+}
+
+// ReminderListChangedPayload is the outbox payload for a reminder list change broadcast
+type ReminderListChangedPayload struct {
+	Action         string           `json:"action"` // create, update or delete
+	ReminderListID uuid.UUID        `json:"reminder_list_id"`
+	ReminderList   *ReminderListDTO `json:"reminder_list,omitempty"`
+}
+
+// UserChangedPayload is the outbox payload for a user change broadcast.
+// The relay loads the current user when delivering.
+type UserChangedPayload struct {
+	Action string `json:"action"`
+}
+
+// CrossDeviceActionPayload is the outbox payload for a silent push to the user's other devices
+type CrossDeviceActionPayload struct {
+	Action         string     `json:"action"` // snooze, complete, dismiss or delete
+	ReminderID     uuid.UUID  `json:"reminder_id"`
+	SourceDeviceID *uuid.UUID `json:"source_device_id,omitempty"`
+}
+
+// DeviceChangedPayload is the outbox payload for a device registration change.
+// The relay loads the current device when delivering anything but a delete.
+type DeviceChangedPayload struct {
+	Action   string    `json:"action"` // create, update or delete
+	DeviceID uuid.UUID `json:"device_id"`
+}
+
+// ReminderNotifiedPayload is the outbox payload announcing that a reminder's push was sent
+type ReminderNotifiedPayload struct {
+	ReminderID uuid.UUID  `json:"reminder_id"`
+	DueAt      *time.Time `json:"due_at,omitempty"`
+	NotifiedAt time.Time  `json:"notified_at"`
+}
@@ -62,3 +62,8 @@ func ReminderListsToDTO(lists []models.ReminderList, getCounts func(uuid.UUID) i
 	}
 	return dtos
 }
+
+// ReorderReminderListsRequest is the request body for reordering reminder lists
+type ReorderReminderListsRequest struct {
+	IDs []uuid.UUID `json:"ids" binding:"required"`
+}
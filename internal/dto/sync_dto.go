@@ -23,15 +23,18 @@ type SyncChange struct {
 
 // SyncResponse is the response for sync operations
 type SyncResponse struct {
-	Changes     []SyncEvent `json:"changes"`
-	LastSyncAt  time.Time   `json:"last_sync_at"`
-	HasMore     bool        `json:"has_more"`
-	NextCursor  *string     `json:"next_cursor,omitempty"`
+	Changes        []SyncEvent `json:"changes"`
+	LastSyncAt     time.Time   `json:"last_sync_at"`
+	HasMore        bool        `json:"has_more"`
+	NextCursor     *string     `json:"next_cursor,omitempty"`
+	Cursor         string      `json:"cursor"`          // Resume point for the next call
+	ResyncRequired bool        `json:"resync_required"` // Cursor predates retained history; refetch everything
 }
 
 // SyncEvent represents a change event from the server
 type SyncEvent struct {
 	ID         uuid.UUID              `json:"id"`
+	Seq        int64                  `json:"seq"`
 	EntityType string                 `json:"entity_type"`
 	EntityID   uuid.UUID              `json:"entity_id"`
 	Action     string                 `json:"action"`
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	apperrors "github.com/user/remind-me/backend/pkg/errors"
+)
+
+const internalErrorMessage = "Internal server error"
+
+// graphQLError converts err into a GraphQL error located at path. Every error carries
+// an extensions.code; validation errors also list the offending input fields. Internal
+// errors are logged under a correlation ID that is returned to the client, and their
+// message is masked when MaskInternalErrors is set.
+func (h *Handler) graphQLError(err error, path ...interface{}) GraphQLError {
+	appErr := apperrors.GetAppError(err)
+	if appErr == nil || appErr.Code == apperrors.CodeInternalError || appErr.StatusCode >= http.StatusInternalServerError {
+		correlationID := uuid.NewString()
+		log.Printf("[GraphQL] correlationId=%s path=%v: %v", correlationID, path, err)
+
+		message := err.Error()
+		if h.MaskInternalErrors {
+			message = internalErrorMessage
+		}
+		return GraphQLError{
+			Message: message,
+			Path:    path,
+			Extensions: map[string]interface{}{
+				"code":          apperrors.CodeInternalError,
+				"correlationId": correlationID,
+			},
+		}
+	}
+
+	extensions := map[string]interface{}{"code": appErr.Code}
+	if len(appErr.Fields) > 0 {
+		extensions["fields"] = appErr.Fields
+	}
+	return GraphQLError{
+		Message:    appErr.Message,
+		Path:       path,
+		Extensions: extensions,
+	}
+}
+
+// executionResult collects the root fields of one operation. It applies the spec's
+// null propagation: an error on a nullable field resolves that field to null and keeps
+// its siblings, while an error on a non-null field propagates to the root and nulls
+// the whole data object.
+type executionResult struct {
+	h        *Handler
+	data     map[string]interface{}
+	errs     []GraphQLError
+	nulled   bool
+	resolved int // Root fields that completed without error
+}
+
+func (h *Handler) newExecutionResult() *executionResult {
+	return &executionResult{h: h, data: make(map[string]interface{})}
+}
+
+// resolve records the outcome of a root field
+func (r *executionResult) resolve(field string, nullable bool, value interface{}, err error) {
+	if err == nil {
+		r.data[field] = value
+		r.resolved++
+		return
+	}
+
+	r.errs = append(r.errs, r.h.graphQLError(err, field))
+	if nullable {
+		r.data[field] = nil
+	} else {
+		r.nulled = true
+	}
+}
+
+// fieldError records an error on a nullable field below a root field, which resolves
+// to null without affecting its siblings
+func (r *executionResult) fieldError(err error, path ...interface{}) {
+	r.errs = append(r.errs, r.h.graphQLError(err, path...))
+}
+
+// aborted reports whether a non-null field has failed. Mutation fields run serially,
+// so once the result is nulled the remaining fields are not executed.
+func (r *executionResult) aborted() bool {
+	return r.nulled
+}
+
+// executed reports whether any root field completed. For mutations this means a change
+// may have been committed, so the operation must not simply be run again.
+func (r *executionResult) executed() bool {
+	return r.resolved > 0
+}
+
+func (r *executionResult) response() GraphQLResponse {
+	if r.nulled {
+		return GraphQLResponse{Data: nil, Errors: r.errs}
+	}
+	return GraphQLResponse{Data: r.data, Errors: r.errs}
+}
+
+// uuidVariable reads a required UUID argument
+func uuidVariable(variables map[string]interface{}, name string) (uuid.UUID, error) {
+	value, ok := variables[name].(string)
+	if !ok {
+		return uuid.Nil, apperrors.InvalidFieldsError("Invalid input",
+			apperrors.FieldError{Field: name, Message: "is required"})
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, apperrors.InvalidFieldsError("Invalid input",
+			apperrors.FieldError{Field: name, Message: "must be a valid UUID"})
+	}
+	return id, nil
+}
+
+var (
+	// deliveriesFieldPattern matches a Reminder.deliveries selection and its arguments
+	deliveriesFieldPattern = regexp.MustCompile(`\bdeliveries\b\s*(?:\(([^)]*)\))?`)
+	limitArgumentPattern   = regexp.MustCompile(`\blimit\s*:\s*(\$?\w+)`)
+)
+
+// deliveriesSelection reports whether query selects Reminder.deliveries and returns its
+// limit argument, given inline as in deliveries(limit: 5) or as a variable
+func deliveriesSelection(query string, variables map[string]interface{}) (bool, *int, error) {
+	match := deliveriesFieldPattern.FindStringSubmatch(query)
+	if match == nil {
+		return false, nil, nil
+	}
+	arg := limitArgumentPattern.FindStringSubmatch(match[1])
+	if arg == nil {
+		return true, nil, nil
+	}
+
+	if name, ok := strings.CutPrefix(arg[1], "$"); ok {
+		value, ok := variables[name].(float64)
+		if !ok {
+			return true, nil, nil // Unset variables leave the default limit
+		}
+		limit := int(value)
+		return true, &limit, nil
+	}
+	limit, err := strconv.Atoi(arg[1])
+	if err != nil {
+		return true, nil, apperrors.InvalidFieldsError("Invalid input",
+			apperrors.FieldError{Field: "limit", Message: "must be an integer"})
+	}
+	return true, &limit, nil
+}
+
+// decodeVariable decodes a required input object argument into dst. Type mismatches
+// are reported against the nested input field that caused them.
+func decodeVariable(variables map[string]interface{}, name string, dst interface{}) error {
+	value, ok := variables[name]
+	if !ok || value == nil {
+		return apperrors.InvalidFieldsError("Invalid input",
+			apperrors.FieldError{Field: name, Message: "is required"})
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return apperrors.InvalidFieldsError("Invalid input",
+			apperrors.FieldError{Field: name, Message: "could not be read"})
+	}
+	if err := json.Unmarshal(raw, dst); err != nil {
+		field := apperrors.FieldError{Field: name, Message: err.Error()}
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			if typeErr.Field != "" {
+				field.Field = name + "." + typeErr.Field
+			}
+			field.Message = fmt.Sprintf("must be of type %s", typeErr.Type)
+		}
+		return apperrors.InvalidFieldsError("Invalid input", field)
+	}
+	return nil
+}
@@ -6,14 +6,13 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/gorilla/websocket"
 	gqlmiddleware "github.com/user/remind-me/backend/internal/graphql/middleware"
 	"github.com/user/remind-me/backend/internal/graphql/model"
 	"github.com/user/remind-me/backend/internal/graphql/resolver"
+	"github.com/user/remind-me/backend/internal/service"
 	apperrors "github.com/user/remind-me/backend/pkg/errors"
 	"github.com/user/remind-me/backend/pkg/jwt"
 )
@@ -23,6 +22,7 @@ type GraphQLRequest struct {
 	Query         string                 `json:"query"`
 	OperationName string                 `json:"operationName"`
 	Variables     map[string]interface{} `json:"variables"`
+	Extensions    map[string]interface{} `json:"extensions,omitempty"`
 }
 
 // GraphQLResponse represents a GraphQL response
@@ -40,15 +40,24 @@ type GraphQLError struct {
 
 // Handler holds the GraphQL handler dependencies
 type Handler struct {
-	Resolver   *resolver.Resolver
-	JWTManager *jwt.Manager
+	Resolver           *resolver.Resolver
+	JWTManager         *jwt.Manager
+	IdempotencyService *service.IdempotencyService
+	// Users is checked for deleted accounts on streaming connections
+	Users UserChecker
+	// MaskInternalErrors hides internal error messages from clients, who get a
+	// correlation ID to quote instead
+	MaskInternalErrors bool
 }
 
 // NewHandler creates a new GraphQL handler
-func NewHandler(r *resolver.Resolver, jwtManager *jwt.Manager) *Handler {
+func NewHandler(r *resolver.Resolver, jwtManager *jwt.Manager, idempotencyService *service.IdempotencyService, maskInternalErrors bool) *Handler {
 	return &Handler{
-		Resolver:   r,
-		JWTManager: jwtManager,
+		Resolver:           r,
+		JWTManager:         jwtManager,
+		IdempotencyService: idempotencyService,
+		Users:              r.UserRepo,
+		MaskInternalErrors: maskInternalErrors,
 	}
 }
 
@@ -65,17 +74,98 @@ func (h *Handler) GraphQL(c *gin.Context) {
 	// Create context with auth info
 	ctx := h.contextWithAuth(c)
 
-	// Execute the query
-	result := h.execute(ctx, req)
-
 	// Set no-cache headers to prevent any caching
 	c.Header("Cache-Control", "no-store, no-cache, must-revalidate, max-age=0")
 	c.Header("Pragma", "no-cache")
 	c.Header("Expires", "0")
 
+	// Execute the query. Mutations carrying an idempotency key replay the stored
+	// response on retry.
+	result, replayed := h.executeOperation(ctx, req, idempotencyKey(c, req))
+	if replayed {
+		c.Header("Idempotent-Replayed", "true")
+	}
+
 	c.JSON(http.StatusOK, result)
 }
 
+// idempotencyKey reads the key from the Idempotency-Key header, falling back to
+// the "idempotencyKey" request extension for clients that cannot set headers
+func idempotencyKey(c *gin.Context, req GraphQLRequest) string {
+	if key := strings.TrimSpace(c.GetHeader("Idempotency-Key")); key != "" {
+		return key
+	}
+	return extensionIdempotencyKey(req)
+}
+
+// extensionIdempotencyKey reads the key from the "idempotencyKey" request extension,
+// which is the only place WebSocket clients can send it
+func extensionIdempotencyKey(req GraphQLRequest) string {
+	if key, ok := req.Extensions["idempotencyKey"].(string); ok {
+		return strings.TrimSpace(key)
+	}
+	return ""
+}
+
+func isMutation(req GraphQLRequest) bool {
+	return strings.HasPrefix(strings.TrimSpace(req.Query), "mutation")
+}
+
+// executeOperation runs a query or mutation over any transport. A mutation carrying an
+// idempotency key runs at most once per user and key; replayed reports whether the
+// result is the stored response of an earlier attempt.
+func (h *Handler) executeOperation(ctx context.Context, req GraphQLRequest, key string) (result interface{}, replayed bool) {
+	if key != "" && isMutation(req) {
+		if userID, ok := gqlmiddleware.GetUserID(ctx); ok {
+			return h.executeIdempotent(ctx, userID, key, req)
+		}
+	}
+	return h.execute(ctx, req), false
+}
+
+// executeIdempotent runs a mutation at most once per user and key.
+// Keys are scoped to the authenticated user; unauthenticated mutations ignore them so a
+// replay can never hand one caller's tokens to another. The response is stored as soon
+// as any mutation field has executed, even if a later one failed, because a retry would
+// run the earlier fields again. Only when no field executed is the key released so the
+// client can retry with it.
+func (h *Handler) executeIdempotent(ctx context.Context, userID uuid.UUID, key string, req GraphQLRequest) (interface{}, bool) {
+	variables, _ := json.Marshal(req.Variables)
+	requestHash := service.HashRequest([]byte(req.OperationName), []byte(req.Query), variables)
+
+	reservation, stored, err := h.IdempotencyService.Begin(userID, key, requestHash)
+	if err != nil {
+		return GraphQLResponse{Errors: []GraphQLError{h.graphQLError(err)}}, false
+	}
+	if stored != nil {
+		return json.RawMessage(stored), true
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			if releaseErr := h.IdempotencyService.Release(reservation); releaseErr != nil {
+				fmt.Printf("executeIdempotent: failed to release key: %v\n", releaseErr)
+			}
+			panic(r)
+		}
+	}()
+
+	res := h.runMutation(ctx, req)
+	result := res.response()
+
+	body, err := json.Marshal(result)
+	if err != nil || !res.executed() {
+		if releaseErr := h.IdempotencyService.Release(reservation); releaseErr != nil {
+			fmt.Printf("executeIdempotent: failed to release key: %v\n", releaseErr)
+		}
+		return result, false
+	}
+
+	if err := h.IdempotencyService.Complete(reservation, body); err != nil {
+		fmt.Printf("executeIdempotent: failed to store response: %v\n", err)
+	}
+	return json.RawMessage(body), false
+}
+
 // GraphQLGet handles GraphQL HTTP GET requests (query passed as URL parameter)
 func (h *Handler) GraphQLGet(c *gin.Context) {
 	req := GraphQLRequest{
@@ -143,14 +233,13 @@ func (h *Handler) execute(ctx context.Context, req GraphQLRequest) GraphQLRespon
 	}
 
 	return GraphQLResponse{
-		Errors: []GraphQLError{{Message: "Subscriptions are only supported over WebSocket"}},
+		Errors: []GraphQLError{{Message: "Subscriptions are only supported over WebSocket or Server-Sent Events"}},
 	}
 }
 
 // executeQuery handles query operations
 func (h *Handler) executeQuery(ctx context.Context, req GraphQLRequest) GraphQLResponse {
-	data := make(map[string]interface{})
-	var errs []GraphQLError
+	res := h.newExecutionResult()
 
 	opName := strings.ToLower(req.OperationName)
 	query := strings.ToLower(req.Query)
@@ -168,42 +257,31 @@ func (h *Handler) executeQuery(ctx context.Context, req GraphQLRequest) GraphQLR
 	// "reminders" contains "me" so we can't just use strings.Contains
 	if opName == "me" || opName == "getme" {
 		result, err := h.Resolver.Me(ctx)
-		if err != nil {
-			fmt.Printf("Me query error: %v\n", err)
-			errs = append(errs, errorToGraphQLError(err))
-			// For non-nullable return type, return early with null data
-			return GraphQLResponse{Data: nil, Errors: errs}
-		} else {
-			fmt.Printf("Me query result: %+v\n", result)
-			data["me"] = result
-		}
+		res.resolve("me", false, result, err)
 	}
 
 	if opName == "devices" || opName == "getdevices" {
 		result, err := h.Resolver.Devices(ctx)
-		if err != nil {
-			errs = append(errs, errorToGraphQLError(err))
-			// For non-nullable return type, return early with null data
-			return GraphQLResponse{Data: nil, Errors: errs}
-		} else {
-			data["devices"] = result
-		}
+		res.resolve("devices", false, result, err)
 	}
 
 	if opName == "reminder" || opName == "getreminder" {
-		if idVar, ok := req.Variables["id"]; ok {
-			if idStr, ok := idVar.(string); ok {
-				id, err := uuid.Parse(idStr)
-				if err == nil {
-					result, err := h.Resolver.Reminder(ctx, id)
-					if err != nil {
-						errs = append(errs, errorToGraphQLError(err))
-						data["reminder"] = nil
-					} else {
-						data["reminder"] = result
-					}
+		id, err := uuidVariable(req.Variables, "id")
+		if err != nil {
+			res.resolve("reminder", true, nil, err)
+		} else {
+			result, err := h.Resolver.Reminder(ctx, id)
+			if err == nil {
+				// deliveries is nullable, so a failure there leaves the rest of the reminder
+				selected, limit, deliveriesErr := deliveriesSelection(req.Query, req.Variables)
+				if selected && deliveriesErr == nil {
+					result.Deliveries, deliveriesErr = h.Resolver.ReminderDeliveries(ctx, id, limit)
+				}
+				if deliveriesErr != nil {
+					res.fieldError(deliveriesErr, "reminder", "deliveries")
 				}
 			}
+			res.resolve("reminder", true, result, err)
 		}
 	}
 
@@ -211,47 +289,34 @@ func (h *Handler) executeQuery(ctx context.Context, req GraphQLRequest) GraphQLR
 		var filter *model.ReminderFilter
 		var pagination *model.PaginationInput
 
-		if filterVar, ok := req.Variables["filter"]; ok {
-			filterBytes, _ := json.Marshal(filterVar)
-			json.Unmarshal(filterBytes, &filter)
+		var err error
+		if _, ok := req.Variables["filter"]; ok {
+			err = decodeVariable(req.Variables, "filter", &filter)
 		}
-		if paginationVar, ok := req.Variables["pagination"]; ok {
-			paginationBytes, _ := json.Marshal(paginationVar)
-			json.Unmarshal(paginationBytes, &pagination)
+		if _, ok := req.Variables["pagination"]; ok && err == nil {
+			err = decodeVariable(req.Variables, "pagination", &pagination)
 		}
 
-		result, err := h.Resolver.Reminders(ctx, filter, pagination)
+		var result *model.ReminderConnection
+		if err == nil {
+			result, err = h.Resolver.Reminders(ctx, filter, pagination)
+		}
 		if err != nil {
 			fmt.Printf("Reminders query error: %v\n", err)
-			errs = append(errs, errorToGraphQLError(err))
-			// For non-nullable return type, don't set data["reminders"] = nil
-			// GraphQL spec: if a non-nullable field errors, data should be null
-			// So we return early with null data
-			return GraphQLResponse{Data: nil, Errors: errs}
 		} else {
 			fmt.Printf("Reminders query result: %d edges\n", len(result.Edges))
-			// Debug: print full JSON of result
-			resultJSON, _ := json.MarshalIndent(result, "", "  ")
-			fmt.Printf("Reminders query result JSON:\n%s\n", string(resultJSON))
-			data["reminders"] = result
 		}
+		res.resolve("reminders", false, result, err)
 	}
 
 	// ReminderList query - get single list by ID
 	if opName == "reminderlist" || opName == "getreminderlist" {
-		if idVar, ok := req.Variables["id"]; ok {
-			if idStr, ok := idVar.(string); ok {
-				id, err := uuid.Parse(idStr)
-				if err == nil {
-					result, err := h.Resolver.ReminderList(ctx, id)
-					if err != nil {
-						errs = append(errs, errorToGraphQLError(err))
-						data["reminderList"] = nil
-					} else {
-						data["reminderList"] = result
-					}
-				}
-			}
+		id, err := uuidVariable(req.Variables, "id")
+		if err != nil {
+			res.resolve("reminderList", true, nil, err)
+		} else {
+			result, err := h.Resolver.ReminderList(ctx, id)
+			res.resolve("reminderList", true, result, err)
 		}
 	}
 
@@ -260,34 +325,40 @@ func (h *Handler) executeQuery(ctx context.Context, req GraphQLRequest) GraphQLR
 		result, err := h.Resolver.ReminderLists(ctx)
 		if err != nil {
 			fmt.Printf("ReminderLists query error: %v\n", err)
-			errs = append(errs, errorToGraphQLError(err))
-			return GraphQLResponse{Data: nil, Errors: errs}
 		} else {
 			fmt.Printf("ReminderLists query result: %d lists\n", len(result))
-			data["reminderLists"] = result
 		}
+		res.resolve("reminderLists", false, result, err)
 	}
 
-	// Per GraphQL spec: if any error occurs on a non-nullable field,
-	// data should be null. We handle this case above for reminders.
-	keys := make([]string, 0, len(data))
-	for k := range data {
-		keys = append(keys, k)
-	}
-	fmt.Printf("executeQuery response: data keys=%v, errors=%d\n", keys, len(errs))
+	// ChangesSince query - incremental sync from a cursor
+	if opName == "changessince" || opName == "getchangessince" {
+		var cursor *string
+		var limit *int
+		if cursorVar, ok := req.Variables["cursor"].(string); ok {
+			cursor = &cursorVar
+		}
+		if limitVar, ok := req.Variables["limit"].(float64); ok {
+			l := int(limitVar)
+			limit = &l
+		}
 
-	// Debug: print full response JSON
-	resp := GraphQLResponse{Data: data, Errors: errs}
-	respJSON, _ := json.MarshalIndent(resp, "", "  ")
-	fmt.Printf("executeQuery full response JSON:\n%s\n", string(respJSON))
+		result, err := h.Resolver.ChangesSince(ctx, cursor, limit)
+		res.resolve("changesSince", false, result, err)
+	}
 
-	return resp
+	return res.response()
 }
 
-// executeMutation handles mutation operations
+// executeMutation handles mutation operations. Root mutation fields run serially
+// and execution stops at the first non-null field that fails.
 func (h *Handler) executeMutation(ctx context.Context, req GraphQLRequest) GraphQLResponse {
-	data := make(map[string]interface{})
-	var errs []GraphQLError
+	return h.runMutation(ctx, req).response()
+}
+
+// runMutation executes the root fields of a mutation and returns their results
+func (h *Handler) runMutation(ctx context.Context, req GraphQLRequest) *executionResult {
+	res := h.newExecutionResult()
 
 	query := strings.ToLower(req.Query)
 	fmt.Printf("executeMutation: opName=%q, query=%q\n", req.OperationName, query)
@@ -295,35 +366,27 @@ func (h *Handler) executeMutation(ctx context.Context, req GraphQLRequest) Graph
 	if strings.Contains(query, "authenticatewithgoogle") {
 		idToken, _ := req.Variables["idToken"].(string)
 		result, err := h.Resolver.AuthenticateWithGoogle(ctx, idToken)
-		if err != nil {
-			errs = append(errs, errorToGraphQLError(err))
-			// Don't set data for non-nullable return type on error
-		} else {
-			data["authenticateWithGoogle"] = result
-		}
+		res.resolve("authenticateWithGoogle", false, result, err)
 	}
 
-	if strings.Contains(query, "authenticatewithapple") {
+	if !res.aborted() && strings.Contains(query, "authenticatewithapple") {
 		var input model.AuthenticateWithAppleInput
-		if inputVar, ok := req.Variables["input"]; ok {
-			inputBytes, _ := json.Marshal(inputVar)
-			json.Unmarshal(inputBytes, &input)
+		err := decodeVariable(req.Variables, "input", &input)
+		var result *model.AuthPayload
+		if err == nil {
+			result, err = h.Resolver.AuthenticateWithApple(ctx, input)
 		}
-		result, err := h.Resolver.AuthenticateWithApple(ctx, input)
 		if err != nil {
 			fmt.Printf("AuthenticateWithApple error: %v\n", err)
-			errs = append(errs, errorToGraphQLError(err))
-			// Don't set data for non-nullable return type on error
-		} else {
-			data["authenticateWithApple"] = result
 		}
+		res.resolve("authenticateWithApple", false, result, err)
 	}
 
 	// Check for refreshToken mutation - must handle various formatting (newlines, spaces)
 	// Match when refreshtoken is used as a mutation operation, not as a field in response selection
 	isRefreshTokenMutation := strings.Contains(query, "mutation") &&
 		(strings.Contains(query, "refreshtoken(") || strings.Contains(query, "refreshtoken ("))
-	if isRefreshTokenMutation {
+	if !res.aborted() && isRefreshTokenMutation {
 		// Try both camelCase and lowercase variable names
 		refreshToken, _ := req.Variables["refreshToken"].(string)
 		if refreshToken == "" {
@@ -333,294 +396,250 @@ func (h *Handler) executeMutation(ctx context.Context, req GraphQLRequest) Graph
 		result, err := h.Resolver.RefreshToken(ctx, refreshToken)
 		if err != nil {
 			fmt.Printf("RefreshToken error: %v\n", err)
-			errs = append(errs, errorToGraphQLError(err))
-			// Don't set data for non-nullable return type on error
-		} else {
-			fmt.Printf("RefreshToken success, new accessToken length=%d\n", len(result.AccessToken))
-			data["refreshToken"] = result
 		}
+		res.resolve("refreshToken", false, result, err)
 	}
 
-	if strings.Contains(query, "logout") {
+	if !res.aborted() && strings.Contains(query, "logout") {
 		result, err := h.Resolver.Logout(ctx)
-		if err != nil {
-			errs = append(errs, errorToGraphQLError(err))
-			data["logout"] = nil
-		} else {
-			data["logout"] = result
-		}
+		res.resolve("logout", false, result, err)
 	}
 
-	if strings.Contains(query, "deleteaccount") {
+	if !res.aborted() && strings.Contains(query, "deleteaccount") {
 		result, err := h.Resolver.DeleteAccount(ctx)
-		if err != nil {
-			errs = append(errs, errorToGraphQLError(err))
-			data["deleteAccount"] = nil
-		} else {
-			data["deleteAccount"] = result
-		}
+		res.resolve("deleteAccount", false, result, err)
 	}
 
-	if strings.Contains(query, "restoreaccount") {
+	if !res.aborted() && strings.Contains(query, "restoreaccount") {
 		result, err := h.Resolver.RestoreAccount(ctx)
-		if err != nil {
-			errs = append(errs, errorToGraphQLError(err))
-			data["restoreAccount"] = nil
-		} else {
-			data["restoreAccount"] = result
-		}
+		res.resolve("restoreAccount", false, result, err)
 	}
 
-	if strings.Contains(query, "verifysubscription") {
+	if !res.aborted() && strings.Contains(query, "verifysubscription") {
 		result, err := h.Resolver.VerifySubscription(ctx)
-		if err != nil {
-			errs = append(errs, errorToGraphQLError(err))
-			// Don't set data for non-nullable return type on error
-		} else {
-			data["verifySubscription"] = result
-		}
+		res.resolve("verifySubscription", false, result, err)
 	}
 
-	if strings.Contains(query, "createreminder") && !strings.Contains(query, "createreminderlist") {
+	if !res.aborted() && strings.Contains(query, "createreminder") && !strings.Contains(query, "createreminderlist") {
 		var input model.CreateReminderInput
-		if inputVar, ok := req.Variables["input"]; ok {
-			inputBytes, _ := json.Marshal(inputVar)
-			if err := json.Unmarshal(inputBytes, &input); err != nil {
-				fmt.Printf("CreateReminder input unmarshal error: %v\n", err)
-				fmt.Printf("CreateReminder raw input: %s\n", string(inputBytes))
-			}
+		err := decodeVariable(req.Variables, "input", &input)
+		var result *model.Reminder
+		if err == nil {
+			result, err = h.Resolver.CreateReminder(ctx, input)
 		}
-		result, err := h.Resolver.CreateReminder(ctx, input)
 		if err != nil {
 			fmt.Printf("CreateReminder error: %v\n", err)
-			errs = append(errs, errorToGraphQLError(err))
-			// Don't set data for non-nullable return type on error
-		} else {
-			// Debug: print the result as JSON
-			resultJSON, _ := json.Marshal(result)
-			fmt.Printf("CreateReminder result: %s\n", string(resultJSON))
-			data["createReminder"] = result
 		}
+		res.resolve("createReminder", false, result, err)
 	}
 
-	if strings.Contains(query, "updatereminder") && !strings.Contains(query, "updatereminderlist") {
-		fmt.Printf("UpdateReminder mutation detected\n")
-		idStr, _ := req.Variables["id"].(string)
-		fmt.Printf("UpdateReminder id: %q\n", idStr)
-		id, parseErr := uuid.Parse(idStr)
-		if parseErr != nil {
-			fmt.Printf("UpdateReminder id parse error: %v\n", parseErr)
-		}
+	if !res.aborted() && strings.Contains(query, "updatereminder") && !strings.Contains(query, "updatereminderlist") {
 		var input model.UpdateReminderInput
-		if inputVar, ok := req.Variables["input"]; ok {
-			inputBytes, _ := json.Marshal(inputVar)
-			fmt.Printf("UpdateReminder raw input: %s\n", string(inputBytes))
-			if unmarshalErr := json.Unmarshal(inputBytes, &input); unmarshalErr != nil {
-				fmt.Printf("UpdateReminder input unmarshal error: %v\n", unmarshalErr)
-			}
-			fmt.Printf("UpdateReminder parsed input: %+v\n", input)
-		} else {
-			fmt.Printf("UpdateReminder: no input variable found\n")
+		id, err := uuidVariable(req.Variables, "id")
+		if err == nil {
+			err = decodeVariable(req.Variables, "input", &input)
+		}
+		var result *model.Reminder
+		if err == nil {
+			result, err = h.Resolver.UpdateReminder(ctx, id, input)
 		}
-		result, err := h.Resolver.UpdateReminder(ctx, id, input)
 		if err != nil {
 			fmt.Printf("UpdateReminder error: %v\n", err)
-			errs = append(errs, errorToGraphQLError(err))
-			// Don't include data["updateReminder"] = nil for non-nullable return type
-			// GraphQL spec: on error for non-nullable, omit the data field entirely
-		} else {
-			// Debug: print the result as JSON
-			resultJSON, _ := json.Marshal(result)
-			fmt.Printf("UpdateReminder result: %s\n", string(resultJSON))
-			data["updateReminder"] = result
 		}
+		res.resolve("updateReminder", false, result, err)
 	}
 
-	if strings.Contains(query, "deletereminder") && !strings.Contains(query, "deletereminderlist") {
-		idStr, _ := req.Variables["id"].(string)
-		id, _ := uuid.Parse(idStr)
-		result, err := h.Resolver.DeleteReminder(ctx, id)
-		if err != nil {
-			errs = append(errs, errorToGraphQLError(err))
-			data["deleteReminder"] = nil
-		} else {
-			data["deleteReminder"] = result
+	if !res.aborted() && strings.Contains(query, "deletereminder") && !strings.Contains(query, "deletereminderlist") {
+		id, err := uuidVariable(req.Variables, "id")
+		var result bool
+		if err == nil {
+			result, err = h.Resolver.DeleteReminder(ctx, id)
 		}
+		res.resolve("deleteReminder", false, result, err)
 	}
 
-	if strings.Contains(query, "snoozereminder") {
-		idStr, _ := req.Variables["id"].(string)
-		id, _ := uuid.Parse(idStr)
+	if !res.aborted() && strings.Contains(query, "snoozereminder") {
+		id, err := uuidVariable(req.Variables, "id")
 		minutes, _ := req.Variables["minutes"].(float64)
-		result, err := h.Resolver.SnoozeReminder(ctx, id, int(minutes))
-		if err != nil {
-			errs = append(errs, errorToGraphQLError(err))
-			// Don't set data for non-nullable return type on error
-		} else {
-			data["snoozeReminder"] = result
+		var result *model.Reminder
+		if err == nil {
+			result, err = h.Resolver.SnoozeReminder(ctx, id, int(minutes))
 		}
+		res.resolve("snoozeReminder", false, result, err)
 	}
 
-	if strings.Contains(query, "completereminder") {
-		idStr, _ := req.Variables["id"].(string)
-		id, _ := uuid.Parse(idStr)
-		result, err := h.Resolver.CompleteReminder(ctx, id)
-		if err != nil {
-			errs = append(errs, errorToGraphQLError(err))
-			// Don't set data for non-nullable return type on error
-		} else {
-			data["completeReminder"] = result
+	if !res.aborted() && strings.Contains(query, "completereminder") {
+		id, err := uuidVariable(req.Variables, "id")
+		var result *model.Reminder
+		if err == nil {
+			result, err = h.Resolver.CompleteReminder(ctx, id)
 		}
+		res.resolve("completeReminder", false, result, err)
 	}
 
-	if strings.Contains(query, "dismissreminder") {
-		idStr, _ := req.Variables["id"].(string)
-		id, _ := uuid.Parse(idStr)
-		result, err := h.Resolver.DismissReminder(ctx, id)
-		if err != nil {
-			errs = append(errs, errorToGraphQLError(err))
-			data["dismissReminder"] = nil
-		} else {
-			data["dismissReminder"] = result
+	if !res.aborted() && strings.Contains(query, "dismissreminder") {
+		id, err := uuidVariable(req.Variables, "id")
+		var result bool
+		if err == nil {
+			result, err = h.Resolver.DismissReminder(ctx, id)
 		}
+		res.resolve("dismissReminder", false, result, err)
 	}
 
 	// Note: Check for "unregisterdevice" first to avoid false positive,
 	// since "unregisterdevice" contains "registerdevice" as a substring
-	if strings.Contains(query, "registerdevice") && !strings.Contains(query, "unregisterdevice") {
-		fmt.Printf("RegisterDevice mutation detected\n")
+	if !res.aborted() && strings.Contains(query, "registerdevice") && !strings.Contains(query, "unregisterdevice") {
 		var input model.RegisterDeviceInput
-		if inputVar, ok := req.Variables["input"]; ok {
-			inputBytes, _ := json.Marshal(inputVar)
-			fmt.Printf("RegisterDevice raw input: %s\n", string(inputBytes))
-			if err := json.Unmarshal(inputBytes, &input); err != nil {
-				fmt.Printf("RegisterDevice input unmarshal error: %v\n", err)
-			}
-		} else {
-			fmt.Printf("RegisterDevice: no input variable found in request\n")
+		err := decodeVariable(req.Variables, "input", &input)
+		var result *model.Device
+		if err == nil {
+			result, err = h.Resolver.RegisterDevice(ctx, input)
 		}
-		fmt.Printf("RegisterDevice parsed input: platform=%v, pushToken=%s\n", input.Platform, input.PushToken)
-		result, err := h.Resolver.RegisterDevice(ctx, input)
 		if err != nil {
 			fmt.Printf("RegisterDevice error: %v\n", err)
-			errs = append(errs, errorToGraphQLError(err))
-			// Don't set data for non-nullable return type on error
 		} else {
 			fmt.Printf("RegisterDevice success: device ID=%s\n", result.ID)
-			data["registerDevice"] = result
 		}
+		res.resolve("registerDevice", false, result, err)
 	}
 
 	// ReminderList mutations
-	if strings.Contains(query, "createreminderlist") {
+	if !res.aborted() && strings.Contains(query, "createreminderlist") {
 		var input model.CreateReminderListInput
-		if inputVar, ok := req.Variables["input"]; ok {
-			inputBytes, _ := json.Marshal(inputVar)
-			json.Unmarshal(inputBytes, &input)
+		err := decodeVariable(req.Variables, "input", &input)
+		var result *model.ReminderList
+		if err == nil {
+			result, err = h.Resolver.CreateReminderList(ctx, input)
 		}
-		result, err := h.Resolver.CreateReminderList(ctx, input)
 		if err != nil {
 			fmt.Printf("CreateReminderList error: %v\n", err)
-			errs = append(errs, errorToGraphQLError(err))
-		} else {
-			data["createReminderList"] = result
 		}
+		res.resolve("createReminderList", false, result, err)
 	}
 
-	if strings.Contains(query, "updatereminderlist") {
-		idStr, _ := req.Variables["id"].(string)
-		id, _ := uuid.Parse(idStr)
+	if !res.aborted() && strings.Contains(query, "updatereminderlist") {
 		var input model.UpdateReminderListInput
-		if inputVar, ok := req.Variables["input"]; ok {
-			inputBytes, _ := json.Marshal(inputVar)
-			json.Unmarshal(inputBytes, &input)
+		id, err := uuidVariable(req.Variables, "id")
+		if err == nil {
+			err = decodeVariable(req.Variables, "input", &input)
+		}
+		var result *model.ReminderList
+		if err == nil {
+			result, err = h.Resolver.UpdateReminderList(ctx, id, input)
 		}
-		result, err := h.Resolver.UpdateReminderList(ctx, id, input)
 		if err != nil {
 			fmt.Printf("UpdateReminderList error: %v\n", err)
-			errs = append(errs, errorToGraphQLError(err))
-		} else {
-			data["updateReminderList"] = result
 		}
+		res.resolve("updateReminderList", false, result, err)
 	}
 
-	if strings.Contains(query, "deletereminderlist") {
-		idStr, _ := req.Variables["id"].(string)
-		id, _ := uuid.Parse(idStr)
-		result, err := h.Resolver.DeleteReminderList(ctx, id)
+	if !res.aborted() && strings.Contains(query, "deletereminderlist") {
+		id, err := uuidVariable(req.Variables, "id")
+		var result bool
+		if err == nil {
+			result, err = h.Resolver.DeleteReminderList(ctx, id)
+		}
 		if err != nil {
 			fmt.Printf("DeleteReminderList error: %v\n", err)
-			errs = append(errs, errorToGraphQLError(err))
-			data["deleteReminderList"] = false
-		} else {
-			data["deleteReminderList"] = result
 		}
+		res.resolve("deleteReminderList", false, result, err)
 	}
 
-	if strings.Contains(query, "reorderreminderlists") {
+	if !res.aborted() && strings.Contains(query, "reorderreminderlists") {
 		var ids []uuid.UUID
-		if idsVar, ok := req.Variables["ids"].([]interface{}); ok {
-			for _, idVar := range idsVar {
-				if idStr, ok := idVar.(string); ok {
-					if id, err := uuid.Parse(idStr); err == nil {
-						ids = append(ids, id)
-					}
-				}
-			}
+		err := decodeVariable(req.Variables, "ids", &ids)
+		var result []*model.ReminderList
+		if err == nil {
+			result, err = h.Resolver.ReorderReminderLists(ctx, ids)
 		}
-		result, err := h.Resolver.ReorderReminderLists(ctx, ids)
 		if err != nil {
 			fmt.Printf("ReorderReminderLists error: %v\n", err)
-			errs = append(errs, errorToGraphQLError(err))
-		} else {
-			data["reorderReminderLists"] = result
 		}
+		res.resolve("reorderReminderLists", false, result, err)
 	}
 
-	if strings.Contains(query, "moveremindertolist") {
-		reminderIDStr, _ := req.Variables["reminderId"].(string)
-		reminderID, _ := uuid.Parse(reminderIDStr)
-		listIDStr, _ := req.Variables["listId"].(string)
-		listID, _ := uuid.Parse(listIDStr)
-		result, err := h.Resolver.MoveReminderToList(ctx, reminderID, listID)
+	if !res.aborted() && strings.Contains(query, "moveremindertolist") {
+		reminderID, err := uuidVariable(req.Variables, "reminderId")
+		var listID uuid.UUID
+		if err == nil {
+			listID, err = uuidVariable(req.Variables, "listId")
+		}
+		var result *model.Reminder
+		if err == nil {
+			result, err = h.Resolver.MoveReminderToList(ctx, reminderID, listID)
+		}
 		if err != nil {
 			fmt.Printf("MoveReminderToList error: %v\n", err)
-			errs = append(errs, errorToGraphQLError(err))
-		} else {
-			data["moveReminderToList"] = result
 		}
+		res.resolve("moveReminderToList", false, result, err)
 	}
 
-	if strings.Contains(query, "unregisterdevice") {
-		idStr, _ := req.Variables["id"].(string)
-		id, _ := uuid.Parse(idStr)
-		result, err := h.Resolver.UnregisterDevice(ctx, id)
-		if err != nil {
-			errs = append(errs, errorToGraphQLError(err))
-			data["unregisterDevice"] = nil
-		} else {
-			data["unregisterDevice"] = result
+	if !res.aborted() && strings.Contains(query, "unregisterdevice") {
+		id, err := uuidVariable(req.Variables, "id")
+		var result bool
+		if err == nil {
+			result, err = h.Resolver.UnregisterDevice(ctx, id)
 		}
+		res.resolve("unregisterDevice", false, result, err)
 	}
 
-	// Per GraphQL spec: if any error occurs on a non-nullable field,
-	// data should be null (not an empty object or object with null field)
-	if len(errs) > 0 && len(data) == 0 {
-		return GraphQLResponse{Data: nil, Errors: errs}
+	// Note: "updatedevicequiethours" doesn't contain "updatequiethours", so the two can't collide
+	if !res.aborted() && strings.Contains(query, "updatequiethours") {
+		var input model.UpdateQuietHoursInput
+		err := decodeVariable(req.Variables, "input", &input)
+		var result *model.User
+		if err == nil {
+			result, err = h.Resolver.UpdateQuietHours(ctx, input)
+		}
+		res.resolve("updateQuietHours", false, result, err)
 	}
-	return GraphQLResponse{Data: data, Errors: errs}
+
+	if !res.aborted() && strings.Contains(query, "updatedevicequiethours") {
+		var input model.UpdateDeviceQuietHoursInput
+		id, err := uuidVariable(req.Variables, "id")
+		if err == nil {
+			err = decodeVariable(req.Variables, "input", &input)
+		}
+		var result *model.Device
+		if err == nil {
+			result, err = h.Resolver.UpdateDeviceQuietHours(ctx, id, input)
+		}
+		res.resolve("updateDeviceQuietHours", false, result, err)
+	}
+
+	if !res.aborted() && strings.Contains(query, "updatedigestsettings") {
+		var input model.UpdateDigestSettingsInput
+		err := decodeVariable(req.Variables, "input", &input)
+		var result *model.User
+		if err == nil {
+			result, err = h.Resolver.UpdateDigestSettings(ctx, input)
+		}
+		res.resolve("updateDigestSettings", false, result, err)
+	}
+
+	return res
 }
 
-func errorToGraphQLError(err error) GraphQLError {
-	if appErr := apperrors.GetAppError(err); appErr != nil {
-		return GraphQLError{
-			Message: appErr.Message,
-			Extensions: map[string]interface{}{
-				"code": appErr.Code,
-			},
+// subscriptionPayload builds the execution result for one subscription event.
+// Dropped events are reported as an error without data, which keeps the subscription
+// open and tells the client to refetch.
+func subscriptionPayload(field string, event interface{}) GraphQLResponse {
+	if dropped, ok := event.(*model.EventsDropped); ok {
+		return GraphQLResponse{
+			Data: nil,
+			Errors: []GraphQLError{{
+				Message: fmt.Sprintf("%d events were dropped because the client fell behind; refetch to resync", dropped.Dropped),
+				Path:    []interface{}{field},
+				Extensions: map[string]interface{}{
+					"code": apperrors.CodeEventsDropped,
+				},
+			}},
 		}
 	}
-	return GraphQLError{Message: err.Error()}
+
+	return GraphQLResponse{
+		Data: map[string]interface{}{field: event},
+	}
 }
 
 // Playground serves the GraphQL Playground UI
@@ -693,6 +712,21 @@ func getSchemaTypes() []map[string]interface{} {
 				{"name": "ANDROID", "description": "Android platform"},
 			},
 		},
+		{
+			"kind": "ENUM", "name": "DeliveryProvider", "description": "Push provider a notification was sent through",
+			"enumValues": []map[string]interface{}{
+				{"name": "APNS", "description": "Apple Push Notification service"},
+				{"name": "FCM", "description": "Firebase Cloud Messaging"},
+			},
+		},
+		{
+			"kind": "ENUM", "name": "DeliveryStatus", "description": "Outcome of a push notification attempt",
+			"enumValues": []map[string]interface{}{
+				{"name": "SENT", "description": "Accepted by the provider"},
+				{"name": "FAILED", "description": "Rejected or not reached"},
+				{"name": "INVALID_TOKEN", "description": "Provider rejected the token as permanently invalid"},
+			},
+		},
 		{
 			"kind": "ENUM", "name": "Priority", "description": "Reminder priority",
 			"enumValues": []map[string]interface{}{
@@ -729,6 +763,13 @@ func getSchemaTypes() []map[string]interface{} {
 				{"name": "DELETED", "description": "Deleted"},
 			},
 		},
+		{
+			"kind": "ENUM", "name": "SyncStatus", "description": "Whether a sync cursor can be resumed",
+			"enumValues": []map[string]interface{}{
+				{"name": "OK", "description": "Changes continue from the cursor"},
+				{"name": "RESYNC_REQUIRED", "description": "The cursor predates retained history; fetch everything again"},
+			},
+		},
 		// Objects
 		{
 			"kind": "OBJECT", "name": "User", "description": "User account",
@@ -740,6 +781,8 @@ func getSchemaTypes() []map[string]interface{} {
 				{"name": "timezone", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "String"}}},
 				{"name": "isPremium", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "Boolean"}}},
 				{"name": "premiumUntil", "type": map[string]interface{}{"kind": "SCALAR", "name": "DateTime"}},
+				{"name": "quietHours", "type": map[string]interface{}{"kind": "OBJECT", "name": "QuietHours"}},
+				{"name": "digest", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "OBJECT", "name": "DigestSettings"}}},
 				{"name": "createdAt", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "DateTime"}}},
 				{"name": "updatedAt", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "DateTime"}}},
 			},
@@ -764,6 +807,8 @@ func getSchemaTypes() []map[string]interface{} {
 				{"name": "appVersion", "type": map[string]interface{}{"kind": "SCALAR", "name": "String"}},
 				{"name": "osVersion", "type": map[string]interface{}{"kind": "SCALAR", "name": "String"}},
 				{"name": "lastSeenAt", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "DateTime"}}},
+				{"name": "quietHoursOverride", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "Boolean"}}},
+				{"name": "quietHours", "type": map[string]interface{}{"kind": "OBJECT", "name": "QuietHours"}},
 				{"name": "createdAt", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "DateTime"}}},
 			},
 		},
@@ -786,6 +831,20 @@ func getSchemaTypes() []map[string]interface{} {
 				{"name": "version", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "Int"}}},
 				{"name": "createdAt", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "DateTime"}}},
 				{"name": "updatedAt", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "DateTime"}}},
+				{"name": "deliveries", "description": "Recent push attempts; only resolved on Query.reminder", "args": []map[string]interface{}{{"name": "limit", "type": map[string]interface{}{"kind": "SCALAR", "name": "Int"}}}, "type": map[string]interface{}{"kind": "LIST", "ofType": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "OBJECT", "name": "NotificationDelivery"}}}},
+			},
+		},
+		{
+			"kind": "OBJECT", "name": "NotificationDelivery", "description": "One push notification attempt to a device",
+			"fields": []map[string]interface{}{
+				{"name": "id", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "ID"}}},
+				{"name": "deviceId", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "UUID"}}},
+				{"name": "provider", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "ENUM", "name": "DeliveryProvider"}}},
+				{"name": "providerMessageId", "type": map[string]interface{}{"kind": "SCALAR", "name": "String"}},
+				{"name": "status", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "ENUM", "name": "DeliveryStatus"}}},
+				{"name": "error", "type": map[string]interface{}{"kind": "SCALAR", "name": "String"}},
+				{"name": "latencyMs", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "Int"}}},
+				{"name": "attemptedAt", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "DateTime"}}},
 			},
 		},
 		{
@@ -830,9 +889,98 @@ func getSchemaTypes() []map[string]interface{} {
 				{"name": "action", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "ENUM", "name": "ChangeAction"}}},
 				{"name": "reminder", "type": map[string]interface{}{"kind": "OBJECT", "name": "Reminder"}},
 				{"name": "reminderId", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "UUID"}}},
+				{"name": "listId", "type": map[string]interface{}{"kind": "SCALAR", "name": "UUID"}},
+				{"name": "previousListId", "type": map[string]interface{}{"kind": "SCALAR", "name": "UUID"}},
+				{"name": "timestamp", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "DateTime"}}},
+			},
+		},
+		{
+			"kind": "OBJECT", "name": "ReminderList", "description": "Reminder list",
+			"fields": []map[string]interface{}{
+				{"name": "id", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "UUID"}}},
+				{"name": "name", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "String"}}},
+				{"name": "colorHex", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "String"}}},
+				{"name": "iconName", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "String"}}},
+				{"name": "sortOrder", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "Int"}}},
+				{"name": "isDefault", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "Boolean"}}},
+				{"name": "reminderCount", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "Int"}}},
+				{"name": "createdAt", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "DateTime"}}},
+				{"name": "updatedAt", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "DateTime"}}},
+			},
+		},
+		{
+			"kind": "OBJECT", "name": "NotificationSound", "description": "Notification sound",
+			"fields": []map[string]interface{}{
+				{"name": "id", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "UUID"}}},
+				{"name": "name", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "String"}}},
+				{"name": "filename", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "String"}}},
+				{"name": "isFree", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "Boolean"}}},
+			},
+		},
+		{
+			"kind": "OBJECT", "name": "ReminderListChangeEvent", "description": "Reminder list subscription event",
+			"fields": []map[string]interface{}{
+				{"name": "action", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "ENUM", "name": "ChangeAction"}}},
+				{"name": "reminderList", "type": map[string]interface{}{"kind": "OBJECT", "name": "ReminderList"}},
+				{"name": "reminderListId", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "UUID"}}},
+				{"name": "timestamp", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "DateTime"}}},
+			},
+		},
+		{
+			"kind": "OBJECT", "name": "UserChangeEvent", "description": "User subscription event",
+			"fields": []map[string]interface{}{
+				{"name": "action", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "ENUM", "name": "ChangeAction"}}},
+				{"name": "user", "type": map[string]interface{}{"kind": "OBJECT", "name": "User"}},
+				{"name": "userId", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "UUID"}}},
+				{"name": "timestamp", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "DateTime"}}},
+			},
+		},
+		{
+			"kind": "OBJECT", "name": "DeviceChangeEvent", "description": "Device subscription event",
+			"fields": []map[string]interface{}{
+				{"name": "action", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "ENUM", "name": "ChangeAction"}}},
+				{"name": "device", "type": map[string]interface{}{"kind": "OBJECT", "name": "Device"}},
+				{"name": "deviceId", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "UUID"}}},
+				{"name": "timestamp", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "DateTime"}}},
+			},
+		},
+		{
+			"kind": "OBJECT", "name": "ReminderNotifiedEvent", "description": "Sent once a reminder notification was pushed",
+			"fields": []map[string]interface{}{
+				{"name": "reminderId", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "UUID"}}},
+				{"name": "dueAt", "type": map[string]interface{}{"kind": "SCALAR", "name": "DateTime"}},
+				{"name": "notifiedAt", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "DateTime"}}},
+			},
+		},
+		{
+			"kind": "OBJECT", "name": "SyncChange", "description": "Change to a synced entity",
+			"fields": []map[string]interface{}{
+				{"name": "cursor", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "String"}}},
+				{"name": "entityType", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "String"}}},
+				{"name": "entityId", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "UUID"}}},
+				{"name": "action", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "ENUM", "name": "ChangeAction"}}},
+				{"name": "reminder", "type": map[string]interface{}{"kind": "OBJECT", "name": "Reminder"}},
+				{"name": "deviceId", "type": map[string]interface{}{"kind": "SCALAR", "name": "UUID"}},
 				{"name": "timestamp", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "DateTime"}}},
 			},
 		},
+		{
+			"kind": "OBJECT", "name": "SyncStreamEvent", "description": "Sync stream item",
+			"fields": []map[string]interface{}{
+				{"name": "status", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "ENUM", "name": "SyncStatus"}}},
+				{"name": "cursor", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "String"}}},
+				{"name": "change", "type": map[string]interface{}{"kind": "OBJECT", "name": "SyncChange"}},
+			},
+		},
+		{
+			"kind": "OBJECT", "name": "SyncChangesPayload", "description": "Page of sync changes",
+			"fields": []map[string]interface{}{
+				{"name": "status", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "ENUM", "name": "SyncStatus"}}},
+				{"name": "changes", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "LIST", "ofType": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "OBJECT", "name": "SyncChange"}}}}},
+				{"name": "cursor", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "String"}}},
+				{"name": "hasMore", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "Boolean"}}},
+			},
+		},
 		// Input types
 		{
 			"kind": "INPUT_OBJECT", "name": "PaginationInput", "description": "Pagination input",
@@ -853,6 +1001,47 @@ func getSchemaTypes() []map[string]interface{} {
 				{"name": "osVersion", "type": map[string]interface{}{"kind": "SCALAR", "name": "String"}},
 			},
 		},
+		{
+			"kind": "OBJECT", "name": "QuietHours", "description": "Daily window, as HH:MM in the user's timezone, during which non-alarm notifications are held back",
+			"fields": []map[string]interface{}{
+				{"name": "start", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "String"}}},
+				{"name": "end", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "String"}}},
+			},
+		},
+		{
+			"kind": "OBJECT", "name": "DigestSettings", "description": "Daily digest preferences",
+			"fields": []map[string]interface{}{
+				{"name": "enabled", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "Boolean"}}},
+				{"name": "time", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "String"}}},
+				{"name": "days", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "LIST", "ofType": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "Int"}}}}},
+				{"name": "listIds", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "LIST", "ofType": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "UUID"}}}}},
+			},
+		},
+		{
+			"kind": "INPUT_OBJECT", "name": "UpdateDigestSettingsInput", "description": "Update daily digest input; omitted fields keep their current value",
+			"inputFields": []map[string]interface{}{
+				{"name": "enabled", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "Boolean"}}},
+				{"name": "time", "type": map[string]interface{}{"kind": "SCALAR", "name": "String"}},
+				{"name": "days", "type": map[string]interface{}{"kind": "LIST", "ofType": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "Int"}}}},
+				{"name": "listIds", "type": map[string]interface{}{"kind": "LIST", "ofType": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "UUID"}}}},
+			},
+		},
+		{
+			"kind": "INPUT_OBJECT", "name": "UpdateQuietHoursInput", "description": "Update quiet hours input; omit start and end to turn quiet hours off",
+			"inputFields": []map[string]interface{}{
+				{"name": "start", "type": map[string]interface{}{"kind": "SCALAR", "name": "String"}},
+				{"name": "end", "type": map[string]interface{}{"kind": "SCALAR", "name": "String"}},
+				{"name": "timezone", "type": map[string]interface{}{"kind": "SCALAR", "name": "String"}},
+			},
+		},
+		{
+			"kind": "INPUT_OBJECT", "name": "UpdateDeviceQuietHoursInput", "description": "Update device quiet hours input",
+			"inputFields": []map[string]interface{}{
+				{"name": "override", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "Boolean"}}},
+				{"name": "start", "type": map[string]interface{}{"kind": "SCALAR", "name": "String"}},
+				{"name": "end", "type": map[string]interface{}{"kind": "SCALAR", "name": "String"}},
+			},
+		},
 		{
 			"kind": "INPUT_OBJECT", "name": "RecurrenceRuleInput", "description": "Recurrence rule input",
 			"inputFields": []map[string]interface{}{
@@ -908,6 +1097,10 @@ func getSchemaTypes() []map[string]interface{} {
 				{"name": "reminder", "description": "Get reminder by ID", "args": []map[string]interface{}{{"name": "id", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "UUID"}}}}, "type": map[string]interface{}{"kind": "OBJECT", "name": "Reminder"}},
 				{"name": "reminders", "description": "Get reminders", "args": []map[string]interface{}{{"name": "filter", "type": map[string]interface{}{"kind": "INPUT_OBJECT", "name": "ReminderFilter"}}, {"name": "pagination", "type": map[string]interface{}{"kind": "INPUT_OBJECT", "name": "PaginationInput"}}}, "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "OBJECT", "name": "ReminderConnection"}}},
 				{"name": "devices", "description": "Get user devices", "args": []interface{}{}, "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "LIST", "ofType": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "OBJECT", "name": "Device"}}}}},
+				{"name": "reminderList", "description": "Get reminder list by ID", "args": []map[string]interface{}{{"name": "id", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "UUID"}}}}, "type": map[string]interface{}{"kind": "OBJECT", "name": "ReminderList"}},
+				{"name": "reminderLists", "description": "Get reminder lists", "args": []interface{}{}, "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "LIST", "ofType": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "OBJECT", "name": "ReminderList"}}}}},
+				{"name": "notificationSounds", "description": "Get notification sounds", "args": []interface{}{}, "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "LIST", "ofType": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "OBJECT", "name": "NotificationSound"}}}}},
+				{"name": "changesSince", "description": "Get sync changes after a cursor", "args": []map[string]interface{}{{"name": "cursor", "type": map[string]interface{}{"kind": "SCALAR", "name": "String"}}, {"name": "limit", "type": map[string]interface{}{"kind": "SCALAR", "name": "Int"}}}, "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "OBJECT", "name": "SyncChangesPayload"}}},
 			},
 		},
 		// Mutation type
@@ -926,6 +1119,9 @@ func getSchemaTypes() []map[string]interface{} {
 				{"name": "dismissReminder", "description": "Dismiss reminder", "args": []map[string]interface{}{{"name": "id", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "UUID"}}}}, "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "Boolean"}}},
 				{"name": "registerDevice", "description": "Register device", "args": []map[string]interface{}{{"name": "input", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "INPUT_OBJECT", "name": "RegisterDeviceInput"}}}}, "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "OBJECT", "name": "Device"}}},
 				{"name": "unregisterDevice", "description": "Unregister device", "args": []map[string]interface{}{{"name": "id", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "UUID"}}}}, "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "Boolean"}}},
+				{"name": "updateQuietHours", "description": "Set the user's quiet hours", "args": []map[string]interface{}{{"name": "input", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "INPUT_OBJECT", "name": "UpdateQuietHoursInput"}}}}, "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "OBJECT", "name": "User"}}},
+				{"name": "updateDeviceQuietHours", "description": "Set whether a device overrides the user's quiet hours", "args": []map[string]interface{}{{"name": "id", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "UUID"}}}, {"name": "input", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "INPUT_OBJECT", "name": "UpdateDeviceQuietHoursInput"}}}}, "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "OBJECT", "name": "Device"}}},
+				{"name": "updateDigestSettings", "description": "Set the user's daily digest preferences", "args": []map[string]interface{}{{"name": "input", "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "INPUT_OBJECT", "name": "UpdateDigestSettingsInput"}}}}, "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "OBJECT", "name": "User"}}},
 				{"name": "deleteAccount", "description": "Delete account", "args": []interface{}{}, "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "Boolean"}}},
 				{"name": "restoreAccount", "description": "Restore account after deletion", "args": []interface{}{}, "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "Boolean"}}},
 			},
@@ -934,252 +1130,13 @@ func getSchemaTypes() []map[string]interface{} {
 		{
 			"kind": "OBJECT", "name": "Subscription", "description": "Root subscription type",
 			"fields": []map[string]interface{}{
-				{"name": "reminderChanged", "description": "Subscribe to reminder changes", "args": []interface{}{}, "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "OBJECT", "name": "ReminderChangeEvent"}}},
+				{"name": "reminderChanged", "description": "Subscribe to reminder changes", "args": []map[string]interface{}{{"name": "listIds", "type": map[string]interface{}{"kind": "LIST", "ofType": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "UUID"}}}}, {"name": "actions", "type": map[string]interface{}{"kind": "LIST", "ofType": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "ENUM", "name": "ChangeAction"}}}}}, "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "OBJECT", "name": "ReminderChangeEvent"}}},
+				{"name": "reminderListChanged", "description": "Subscribe to reminder list changes", "args": []map[string]interface{}{{"name": "listIds", "type": map[string]interface{}{"kind": "LIST", "ofType": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "SCALAR", "name": "UUID"}}}}, {"name": "actions", "type": map[string]interface{}{"kind": "LIST", "ofType": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "ENUM", "name": "ChangeAction"}}}}}, "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "OBJECT", "name": "ReminderListChangeEvent"}}},
+				{"name": "userChanged", "description": "Subscribe to changes to the current user", "args": []interface{}{}, "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "OBJECT", "name": "UserChangeEvent"}}},
+				{"name": "deviceChanged", "description": "Subscribe to device changes", "args": []interface{}{}, "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "OBJECT", "name": "DeviceChangeEvent"}}},
+				{"name": "reminderNotified", "description": "Subscribe to pushed reminder notifications", "args": []interface{}{}, "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "OBJECT", "name": "ReminderNotifiedEvent"}}},
+				{"name": "syncStream", "description": "Stream sync changes after a cursor", "args": []map[string]interface{}{{"name": "afterCursor", "type": map[string]interface{}{"kind": "SCALAR", "name": "String"}}}, "type": map[string]interface{}{"kind": "NON_NULL", "ofType": map[string]interface{}{"kind": "OBJECT", "name": "SyncStreamEvent"}}},
 			},
 		},
 	}
 }
-
-// WebSocket upgrader for graphql-ws protocol
-var upgrader = websocket.Upgrader{
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
-	CheckOrigin: func(r *http.Request) bool {
-		return true // Allow all origins for development
-	},
-	Subprotocols: []string{"graphql-transport-ws"},
-}
-
-// WebSocketHandler handles GraphQL subscriptions over WebSocket using graphql-transport-ws protocol
-func (h *Handler) WebSocketHandler(c *gin.Context) {
-	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
-	if err != nil {
-		return
-	}
-	defer conn.Close()
-
-	var userID uuid.UUID
-	var authenticated bool
-	subscriptions := make(map[string]context.CancelFunc)
-
-	// Keep-alive ticker
-	ticker := time.NewTicker(30 * time.Second)
-	defer ticker.Stop()
-
-	// Handle keep-alive in background
-	go func() {
-		for range ticker.C {
-			conn.WriteJSON(map[string]string{"type": "ka"})
-		}
-	}()
-
-	for {
-		_, message, err := conn.ReadMessage()
-		if err != nil {
-			// Clean up all subscriptions on disconnect
-			for _, cancel := range subscriptions {
-				cancel()
-			}
-			break
-		}
-
-		var msg map[string]interface{}
-		if err := json.Unmarshal(message, &msg); err != nil {
-			continue
-		}
-
-		msgType, _ := msg["type"].(string)
-
-		switch msgType {
-		case "connection_init":
-			// Handle authentication from connection params
-			if payload, ok := msg["payload"].(map[string]interface{}); ok {
-				// Try different auth header formats
-				var authToken string
-				if auth, ok := payload["Authorization"].(string); ok {
-					authToken = auth
-				} else if auth, ok := payload["authorization"].(string); ok {
-					authToken = auth
-				} else if headers, ok := payload["headers"].(map[string]interface{}); ok {
-					if auth, ok := headers["Authorization"].(string); ok {
-						authToken = auth
-					}
-				}
-
-				if authToken != "" {
-					token := strings.TrimPrefix(authToken, "Bearer ")
-					claims, err := h.JWTManager.ValidateToken(token)
-					if err == nil {
-						userID = claims.UserID
-						authenticated = true
-					}
-				}
-			}
-			conn.WriteJSON(map[string]string{"type": "connection_ack"})
-
-		case "subscribe":
-			if !authenticated {
-				conn.WriteJSON(map[string]interface{}{
-					"type":    "error",
-					"id":      msg["id"],
-					"payload": []map[string]string{{"message": "Unauthorized"}},
-				})
-				continue
-			}
-
-			id, _ := msg["id"].(string)
-
-			// Create context with user ID and cancellation
-			ctx, cancel := context.WithCancel(context.Background())
-			ctx = gqlmiddleware.WithUserID(ctx, userID)
-			subscriptions[id] = cancel
-
-			// Determine subscription type from the query
-			payload, _ := msg["payload"].(map[string]interface{})
-			query, _ := payload["query"].(string)
-			queryLower := strings.ToLower(query)
-
-			if strings.Contains(queryLower, "userchanged") {
-				// Handle userChanged subscription
-				eventChan, err := h.Resolver.UserChanged(ctx)
-				if err != nil {
-					conn.WriteJSON(map[string]interface{}{
-						"type":    "error",
-						"id":      id,
-						"payload": []map[string]string{{"message": err.Error()}},
-					})
-					cancel()
-					delete(subscriptions, id)
-					continue
-				}
-
-				go func(subID string) {
-					defer func() {
-						delete(subscriptions, subID)
-					}()
-
-					for {
-						select {
-						case <-ctx.Done():
-							return
-						case event, ok := <-eventChan:
-							if !ok {
-								conn.WriteJSON(map[string]interface{}{
-									"type": "complete",
-									"id":   subID,
-								})
-								return
-							}
-							conn.WriteJSON(map[string]interface{}{
-								"type": "next",
-								"id":   subID,
-								"payload": map[string]interface{}{
-									"data": map[string]interface{}{
-										"userChanged": event,
-									},
-								},
-							})
-						}
-					}
-				}(id)
-			} else if strings.Contains(queryLower, "reminderlistchanged") {
-				// Handle reminderListChanged subscription
-				eventChan, err := h.Resolver.ReminderListChanged(ctx)
-				if err != nil {
-					conn.WriteJSON(map[string]interface{}{
-						"type":    "error",
-						"id":      id,
-						"payload": []map[string]string{{"message": err.Error()}},
-					})
-					cancel()
-					delete(subscriptions, id)
-					continue
-				}
-
-				go func(subID string) {
-					defer func() {
-						delete(subscriptions, subID)
-					}()
-
-					for {
-						select {
-						case <-ctx.Done():
-							return
-						case event, ok := <-eventChan:
-							if !ok {
-								conn.WriteJSON(map[string]interface{}{
-									"type": "complete",
-									"id":   subID,
-								})
-								return
-							}
-							conn.WriteJSON(map[string]interface{}{
-								"type": "next",
-								"id":   subID,
-								"payload": map[string]interface{}{
-									"data": map[string]interface{}{
-										"reminderListChanged": event,
-									},
-								},
-							})
-						}
-					}
-				}(id)
-			} else {
-				// Default: handle reminderChanged subscription
-				eventChan, err := h.Resolver.ReminderChanged(ctx)
-				if err != nil {
-					conn.WriteJSON(map[string]interface{}{
-						"type":    "error",
-						"id":      id,
-						"payload": []map[string]string{{"message": err.Error()}},
-					})
-					cancel()
-					delete(subscriptions, id)
-					continue
-				}
-
-				go func(subID string) {
-					defer func() {
-						delete(subscriptions, subID)
-					}()
-
-					for {
-						select {
-						case <-ctx.Done():
-							return
-						case event, ok := <-eventChan:
-							if !ok {
-								conn.WriteJSON(map[string]interface{}{
-									"type": "complete",
-									"id":   subID,
-								})
-								return
-							}
-							conn.WriteJSON(map[string]interface{}{
-								"type": "next",
-								"id":   subID,
-								"payload": map[string]interface{}{
-									"data": map[string]interface{}{
-										"reminderChanged": event,
-									},
-								},
-							})
-						}
-					}
-				}(id)
-			}
-
-		case "complete":
-			// Client wants to unsubscribe
-			id, _ := msg["id"].(string)
-			if cancel, ok := subscriptions[id]; ok {
-				cancel()
-				delete(subscriptions, id)
-			}
-
-		case "ping":
-			conn.WriteJSON(map[string]string{"type": "pong"})
-		}
-	}
-}
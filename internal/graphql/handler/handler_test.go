@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	gqlmiddleware "github.com/user/remind-me/backend/internal/graphql/middleware"
+	"github.com/user/remind-me/backend/internal/graphql/resolver"
+	"github.com/user/remind-me/backend/internal/pubsub"
+	"github.com/user/remind-me/backend/internal/service"
+	"github.com/user/remind-me/backend/internal/service/servicetest"
+	apperrors "github.com/user/remind-me/backend/pkg/errors"
+	"github.com/user/remind-me/backend/pkg/jwt"
+)
+
+const (
+	testSecret     = "test-secret"
+	logoutMutation = `mutation { logout }`
+)
+
+// fakeUsers reports the users it maps to true as existing
+type fakeUsers map[uuid.UUID]bool
+
+func (u fakeUsers) Exists(id uuid.UUID) (bool, error) {
+	return u[id], nil
+}
+
+type handlerFixture struct {
+	handler *Handler
+	jwt     *jwt.Manager
+	hub     *pubsub.Hub
+	keys    *servicetest.IdempotencyKeys
+	router  *gin.Engine
+	userID  uuid.UUID
+	token   string
+}
+
+// newHandlerFixture serves the GraphQL transports with a resolver that has no services,
+// so only operations that need no database (logout, unknown fields, subscriptions) can
+// execute. POST and GET /graphql are the plain HTTP handlers, /ws the WebSocket handler
+// and /sse the Server-Sent Events handler.
+func newHandlerFixture(t *testing.T) *handlerFixture {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	jwtManager := jwt.NewManager(testSecret)
+	hub := pubsub.NewHub()
+	keys := servicetest.NewIdempotencyKeys()
+	h := NewHandler(&resolver.Resolver{JWTManager: jwtManager, Hub: hub}, jwtManager, service.NewIdempotencyService(keys), false)
+
+	userID := uuid.New()
+	h.Users = fakeUsers{userID: true}
+	tokens, err := jwtManager.GenerateTokenPair(userID, "user@example.com", nil)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair: %v", err)
+	}
+
+	router := gin.New()
+	router.POST("/graphql", h.GraphQL)
+	router.GET("/graphql", h.GraphQLGet)
+	router.GET("/ws", h.WebSocketHandler)
+	router.GET("/sse", h.GraphQLSSE)
+	router.POST("/sse", h.GraphQLSSE)
+	return &handlerFixture{handler: h, jwt: jwtManager, hub: hub, keys: keys, router: router, userID: userID, token: tokens.AccessToken}
+}
+
+// tokenExpiringAt signs an access token for the fixture's user that expires at expiresAt
+func (f *handlerFixture) tokenExpiringAt(t *testing.T, expiresAt time.Time) string {
+	t.Helper()
+	claims := &jwt.Claims{
+		UserID: f.userID,
+		Email:  "user@example.com",
+		RegisteredClaims: gojwt.RegisteredClaims{
+			ExpiresAt: gojwt.NewNumericDate(expiresAt),
+			IssuedAt:  gojwt.NewNumericDate(expiresAt.Add(-15 * time.Minute)),
+		},
+	}
+	token, err := gojwt.NewWithClaims(gojwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+// post sends a GraphQL request as the fixture's user with an optional Idempotency-Key
+func (f *handlerFixture) post(t *testing.T, query, key string) (*httptest.ResponseRecorder, GraphQLResponse) {
+	t.Helper()
+	body, _ := json.Marshal(GraphQLRequest{Query: query})
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+f.token)
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+	w := httptest.NewRecorder()
+	f.router.ServeHTTP(w, req)
+
+	var resp GraphQLResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response %q: %v", w.Body, err)
+	}
+	return w, resp
+}
+
+func errorCode(resp GraphQLResponse) string {
+	if len(resp.Errors) == 0 {
+		return ""
+	}
+	code, _ := resp.Errors[0].Extensions["code"].(string)
+	return code
+}
+
+func TestIdempotentMutationReplaysStoredResponse(t *testing.T) {
+	f := newHandlerFixture(t)
+
+	first, _ := f.post(t, logoutMutation, "key-1")
+	if first.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatalf("first attempt marked as replayed")
+	}
+	if f.keys.Len() != 1 {
+		t.Fatalf("stored %d keys, want 1", f.keys.Len())
+	}
+
+	retry, _ := f.post(t, logoutMutation, "key-1")
+	if retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("retry not marked as replayed")
+	}
+	if retry.Body.String() != first.Body.String() {
+		t.Errorf("retry = %s, want %s", retry.Body, first.Body)
+	}
+}
+
+func TestIdempotentMutationRejectsReusedKey(t *testing.T) {
+	f := newHandlerFixture(t)
+
+	f.post(t, logoutMutation, "key-1")
+	_, resp := f.post(t, `mutation Other { logout }`, "key-1")
+	if code := errorCode(resp); code != apperrors.CodeIdempotencyKeyReused {
+		t.Errorf("error code = %q, want %s", code, apperrors.CodeIdempotencyKeyReused)
+	}
+}
+
+func TestIdempotentMutationRejectsKeyInFlight(t *testing.T) {
+	f := newHandlerFixture(t)
+
+	// Hold the key as a concurrent attempt of the same mutation would
+	variables, _ := json.Marshal(map[string]interface{}(nil))
+	hash := service.HashRequest(nil, []byte(logoutMutation), variables)
+	reservation, _, err := f.handler.IdempotencyService.Begin(f.userID, "key-1", hash)
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	defer f.handler.IdempotencyService.Release(reservation)
+
+	_, resp := f.post(t, logoutMutation, "key-1")
+	if code := errorCode(resp); code != apperrors.CodeIdempotencyKeyInFlight {
+		t.Errorf("error code = %q, want %s", code, apperrors.CodeIdempotencyKeyInFlight)
+	}
+}
+
+func TestIdempotentMutationReleasesKeyWhenNothingExecuted(t *testing.T) {
+	f := newHandlerFixture(t)
+
+	f.post(t, `mutation { unknownField }`, "key-1")
+	if f.keys.Len() != 0 {
+		t.Errorf("stored %d keys after a mutation that executed nothing, want the key released", f.keys.Len())
+	}
+
+	// The client can then retry with the same key
+	w, resp := f.post(t, logoutMutation, "key-1")
+	if len(resp.Errors) > 0 || w.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("retry = %s, want a fresh execution", w.Body)
+	}
+}
+
+func TestExecuteOperationReadsExtensionKey(t *testing.T) {
+	f := newHandlerFixture(t)
+	ctx := gqlmiddleware.WithUserID(context.Background(), f.userID)
+	req := GraphQLRequest{Query: logoutMutation, Extensions: map[string]interface{}{"idempotencyKey": "ws-key"}}
+
+	// WebSocket operations carry the key only as an extension
+	if _, replayed := f.handler.executeOperation(ctx, req, extensionIdempotencyKey(req)); replayed {
+		t.Fatalf("first attempt replayed")
+	}
+	result, replayed := f.handler.executeOperation(ctx, req, extensionIdempotencyKey(req))
+	if !replayed {
+		t.Errorf("retry not replayed")
+	}
+	if _, ok := result.(json.RawMessage); !ok {
+		t.Errorf("replayed result = %T, want the stored response", result)
+	}
+}
+
+func TestIdempotencyKeyIgnoredWithoutUser(t *testing.T) {
+	f := newHandlerFixture(t)
+	req := GraphQLRequest{Query: logoutMutation}
+
+	for i := 0; i < 2; i++ {
+		if _, replayed := f.handler.executeOperation(context.Background(), req, "key-1"); replayed {
+			t.Fatalf("unauthenticated mutation replayed")
+		}
+	}
+	if f.keys.Len() != 0 {
+		t.Errorf("stored %d keys for an unauthenticated caller, want none", f.keys.Len())
+	}
+}
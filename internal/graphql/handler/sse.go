@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	gqlmiddleware "github.com/user/remind-me/backend/internal/graphql/middleware"
+	apperrors "github.com/user/remind-me/backend/pkg/errors"
+	"github.com/user/remind-me/backend/pkg/jwt"
+)
+
+// Comment lines keep proxies from closing idle streams. It is a variable so tests can
+// shorten it.
+var sseKeepAlive = 15 * time.Second
+
+// AcceptsEventStream reports whether the client asked for a text/event-stream response
+func AcceptsEventStream(c *gin.Context) bool {
+	return strings.Contains(c.GetHeader("Accept"), "text/event-stream")
+}
+
+// GraphQLSSE serves an operation over Server-Sent Events using the graphql-sse
+// "distinct connections" mode: one request per operation, results streamed as
+// `next` events and terminated by a `complete` event. Errors that occur before
+// the stream starts are returned as a regular JSON response.
+func (h *Handler) GraphQLSSE(c *gin.Context) {
+	req, ok := sseRequest(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, GraphQLResponse{
+			Errors: []GraphQLError{{Message: "Invalid request body"}},
+		})
+		return
+	}
+
+	// graphql-sse only allows queries and subscriptions over GET, so a link or image
+	// tag cannot trigger a mutation
+	if c.Request.Method == http.MethodGet && isMutation(req) {
+		c.Header("Allow", http.MethodPost)
+		c.JSON(http.StatusMethodNotAllowed, GraphQLResponse{
+			Errors: []GraphQLError{{Message: "Mutations must be sent with POST"}},
+		})
+		return
+	}
+
+	// Same credentials as the WebSocket transport, taken from the Authorization header
+	claims, err := h.authenticateToken(c.GetHeader("Authorization"))
+	if err != nil {
+		status, appErr := sseAuthError(err)
+		c.JSON(status, GraphQLResponse{Errors: []GraphQLError{h.graphQLError(appErr)}})
+		return
+	}
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+	ctx = gqlmiddleware.WithUserID(ctx, claims.UserID)
+	if claims.DeviceID != nil {
+		ctx = gqlmiddleware.WithDeviceID(ctx, *claims.DeviceID)
+	}
+
+	// The stream ends when the token lapses; the client reconnects with a fresh one
+	if claims.ExpiresAt != nil {
+		var cancelExpiry context.CancelFunc
+		ctx, cancelExpiry = context.WithDeadline(ctx, claims.ExpiresAt.Time)
+		defer cancelExpiry()
+	}
+
+	var field string
+	var events <-chan interface{}
+	if isSubscription(req) {
+		field, events, err = h.resolveSubscription(ctx, req)
+		if err != nil {
+			c.JSON(http.StatusOK, GraphQLResponse{Errors: []GraphQLError{h.graphQLError(err, field)}})
+			return
+		}
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	// Queries and mutations produce a single result
+	if events == nil {
+		result, _ := h.executeOperation(ctx, req, idempotencyKey(c, req))
+		writeSSE(c.Writer, "next", result)
+		writeSSE(c.Writer, "complete", nil)
+		return
+	}
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+	revalidate := time.NewTicker(authRevalidateInterval)
+	defer revalidate.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(c.Writer, ":\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case <-revalidate.C:
+			if h.isRevoked(claims.UserID) {
+				return
+			}
+		case event, ok := <-events:
+			if !ok {
+				writeSSE(c.Writer, "complete", nil)
+				return
+			}
+			if err := writeSSE(c.Writer, "next", subscriptionPayload(field, event)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// sseRequest reads the operation from the JSON body, or from the URL for GET requests
+func sseRequest(c *gin.Context) (GraphQLRequest, bool) {
+	var req GraphQLRequest
+	if c.Request.Method == http.MethodGet {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if varsStr := c.Query("variables"); varsStr != "" {
+			if err := json.Unmarshal([]byte(varsStr), &req.Variables); err != nil {
+				return req, false
+			}
+		}
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		return req, false
+	}
+
+	return req, strings.TrimSpace(req.Query) != ""
+}
+
+// sseAuthError maps an authentication failure to an HTTP status and AppError
+func sseAuthError(err error) (int, error) {
+	switch {
+	case errors.Is(err, errAccessRevoked):
+		return http.StatusForbidden, apperrors.ErrForbidden
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return http.StatusUnauthorized, apperrors.ErrTokenExpired
+	default:
+		return http.StatusUnauthorized, apperrors.ErrUnauthorized
+	}
+}
+
+// writeSSE writes one event and flushes it to the client
+func writeSSE(w gin.ResponseWriter, event string, payload interface{}) error {
+	data := ""
+	if payload != nil {
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		data = string(body)
+	}
+
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+		return err
+	}
+	w.Flush()
+	return nil
+}
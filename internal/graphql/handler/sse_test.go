@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/user/remind-me/backend/internal/graphql/model"
+	"github.com/user/remind-me/backend/internal/pubsub"
+	apperrors "github.com/user/remind-me/backend/pkg/errors"
+)
+
+const introspectionQuery = `query { __schema { queryType { name } } }`
+
+// sseEvent is one event read from a stream. Comment lines are returned with comment set.
+type sseEvent struct {
+	name    string
+	data    string
+	comment bool
+}
+
+type sseStream struct {
+	reader *bufio.Reader
+}
+
+// openSSE starts a streaming request against the fixture's SSE handler. A GET sends the
+// operation in the URL, a POST in the body.
+func (f *handlerFixture) openSSE(t *testing.T, method, query, token string) *sseStream {
+	t.Helper()
+	server := httptest.NewServer(f.router)
+	t.Cleanup(server.Close)
+
+	var body io.Reader
+	target := server.URL + "/sse"
+	if method == http.MethodGet {
+		target += "?query=" + url.QueryEscape(query)
+	} else {
+		payload, _ := json.Marshal(GraphQLRequest{Query: query})
+		body = strings.NewReader(string(payload))
+	}
+	req, _ := http.NewRequest(method, target, body)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("%s /sse: %v", method, err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("%s /sse = %d %s, want an event stream", method, resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	return &sseStream{reader: bufio.NewReader(resp.Body)}
+}
+
+// next reads the next event or comment, returning io.EOF once the stream has ended
+func (s *sseStream) next() (sseEvent, error) {
+	var event sseEvent
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			return event, err
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			return event, nil
+		case strings.HasPrefix(line, ":"):
+			event.comment = true
+		case strings.HasPrefix(line, "event: "):
+			event.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			event.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+// nextEvent skips keep-alive comments and returns the next event
+func (s *sseStream) nextEvent(t *testing.T) sseEvent {
+	t.Helper()
+	for {
+		event, err := s.next()
+		if err != nil {
+			t.Fatalf("read event: %v", err)
+		}
+		if !event.comment {
+			return event
+		}
+	}
+}
+
+// sseRequestRecorder sends a request that is answered before any stream starts
+func (f *handlerFixture) sseRequestRecorder(method, query, token string) *httptest.ResponseRecorder {
+	var req *http.Request
+	if method == http.MethodGet {
+		req = httptest.NewRequest(method, "/sse?query="+url.QueryEscape(query), nil)
+	} else {
+		payload, _ := json.Marshal(GraphQLRequest{Query: query})
+		req = httptest.NewRequest(method, "/sse", strings.NewReader(string(payload)))
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	f.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestSSERejectsFailedAuthentication(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  func(t *testing.T, f *handlerFixture) string
+		status int
+		code   string
+	}{
+		{"missing token", func(t *testing.T, f *handlerFixture) string { return "" }, http.StatusUnauthorized, apperrors.ErrUnauthorized.Code},
+		{"invalid token", func(t *testing.T, f *handlerFixture) string { return "not-a-token" }, http.StatusUnauthorized, apperrors.ErrUnauthorized.Code},
+		{"expired token", func(t *testing.T, f *handlerFixture) string {
+			return f.tokenExpiringAt(t, time.Now().Add(-time.Minute))
+		}, http.StatusUnauthorized, apperrors.ErrTokenExpired.Code},
+		{"deleted user", func(t *testing.T, f *handlerFixture) string {
+			f.handler.Users = fakeUsers{}
+			return f.token
+		}, http.StatusForbidden, apperrors.ErrForbidden.Code},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newHandlerFixture(t)
+			w := f.sseRequestRecorder(http.MethodPost, reminderChangedSubscription, tt.token(t, f))
+
+			var resp GraphQLResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response %q: %v", w.Body, err)
+			}
+			if w.Code != tt.status || errorCode(resp) != tt.code {
+				t.Errorf("got %d %s, want %d %s", w.Code, errorCode(resp), tt.status, tt.code)
+			}
+		})
+	}
+}
+
+func TestSSERejectsMutationsOverGet(t *testing.T) {
+	f := newHandlerFixture(t)
+
+	w := f.sseRequestRecorder(http.MethodGet, logoutMutation, f.token)
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != http.MethodPost {
+		t.Errorf("got %d with Allow %q, want 405 allowing POST", w.Code, w.Header().Get("Allow"))
+	}
+}
+
+func TestSSEExecutesQueriesAndMutations(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		query  string
+		field  string
+	}{
+		{"query over GET", http.MethodGet, introspectionQuery, "__schema"},
+		{"mutation over POST", http.MethodPost, logoutMutation, "logout"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newHandlerFixture(t)
+			stream := f.openSSE(t, tt.method, tt.query, f.token)
+
+			next := stream.nextEvent(t)
+			var result GraphQLResponse
+			if err := json.Unmarshal([]byte(next.data), &result); err != nil || next.name != "next" {
+				t.Fatalf("got %s %s, want the result", next.name, next.data)
+			}
+			if data, _ := result.Data.(map[string]interface{}); data[tt.field] == nil || len(result.Errors) > 0 {
+				t.Errorf("result = %s, want %s resolved", next.data, tt.field)
+			}
+			if event := stream.nextEvent(t); event.name != "complete" {
+				t.Errorf("got %s, want complete", event.name)
+			}
+			if _, err := stream.next(); !errors.Is(err, io.EOF) {
+				t.Errorf("stream still open after complete: %v", err)
+			}
+		})
+	}
+}
+
+func TestSSEStreamsSubscriptionWithKeepAlive(t *testing.T) {
+	defer func(interval time.Duration) { sseKeepAlive = interval }(sseKeepAlive)
+	sseKeepAlive = 20 * time.Millisecond
+	f := newHandlerFixture(t)
+
+	stream := f.openSSE(t, http.MethodPost, reminderChangedSubscription, f.token)
+	if event, err := stream.next(); err != nil || !event.comment {
+		t.Fatalf("got %+v (%v), want a keep-alive comment", event, err)
+	}
+
+	waitForSubscribers(t, f.hub, 1)
+	f.hub.Publish(f.userID, pubsub.TopicReminders, &model.ReminderChangeEvent{Action: model.ChangeActionCreated, ReminderID: uuid.New()})
+	if event := stream.nextEvent(t); event.name != "next" || !strings.Contains(event.data, `"reminderChanged"`) {
+		t.Errorf("got %s %s, want the reminderChanged event", event.name, event.data)
+	}
+}
+
+func TestSSEEndsStreamWhenTokenExpires(t *testing.T) {
+	f := newHandlerFixture(t)
+	token := f.tokenExpiringAt(t, time.Now().Add(time.Second))
+
+	stream := f.openSSE(t, http.MethodPost, reminderChangedSubscription, token)
+	for {
+		event, err := stream.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("stream failed instead of ending: %v", err)
+		}
+		if !event.comment {
+			t.Fatalf("got %s %s, want the stream to end without events", event.name, event.data)
+		}
+	}
+	waitForSubscribers(t, f.hub, 0)
+}
@@ -0,0 +1,599 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	gqlmiddleware "github.com/user/remind-me/backend/internal/graphql/middleware"
+	"github.com/user/remind-me/backend/internal/graphql/model"
+	apperrors "github.com/user/remind-me/backend/pkg/errors"
+	"github.com/user/remind-me/backend/pkg/jwt"
+)
+
+// Subprotocols accepted on /graphql. graphql-transport-ws is the current protocol;
+// graphql-ws is the legacy subscriptions-transport-ws protocol still used by older clients.
+const (
+	protocolTransportWS = "graphql-transport-ws"
+	protocolLegacyWS    = "graphql-ws"
+)
+
+// wsInitTimeout is how long a client has to send connection_init. It is a variable so
+// tests can shorten it.
+var wsInitTimeout = 10 * time.Second
+
+const (
+	wsWriteTimeout         = 10 * time.Second
+	wsLegacyKeepAlive      = 30 * time.Second
+	authRevalidateInterval = time.Minute
+)
+
+// Close codes defined by graphql-transport-ws
+const (
+	closeBadRequest       = 4400
+	closeUnauthorized     = 4401
+	closeForbidden        = 4403
+	closeInitTimeout      = 4408
+	closeSubscriberExists = 4409
+	closeTooManyInits     = 4429
+)
+
+// UserChecker reports whether a user account still exists
+type UserChecker interface {
+	Exists(id uuid.UUID) (bool, error)
+}
+
+var (
+	errMissingToken  = errors.New("missing authorization token")
+	errAccessRevoked = errors.New("access revoked")
+)
+
+// WebSocket upgrader for GraphQL subscriptions
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin: func(r *http.Request) bool {
+		return true // Allow all origins for development
+	},
+	Subprotocols: []string{protocolTransportWS, protocolLegacyWS},
+}
+
+// wsIncoming is a message received from the client
+type wsIncoming struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// wsOutgoing is a message sent to the client
+type wsOutgoing struct {
+	ID      string      `json:"id,omitempty"`
+	Type    string      `json:"type"`
+	Payload interface{} `json:"payload,omitempty"`
+}
+
+// wsConnection is the state of one GraphQL WebSocket.
+// Writes are serialized because operations stream from their own goroutines.
+type wsConnection struct {
+	conn   *websocket.Conn
+	legacy bool
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	writeMu sync.Mutex
+	closed  bool
+
+	subsMu sync.Mutex
+	subs   map[string]context.CancelFunc
+
+	// Only touched by the read loop
+	initReceived bool
+	userID       uuid.UUID
+	deviceID     *uuid.UUID
+	expiryTimer  *time.Timer
+
+	acknowledged atomic.Bool
+}
+
+func newWSConnection(conn *websocket.Conn) *wsConnection {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &wsConnection{
+		conn:   conn,
+		legacy: conn.Subprotocol() == protocolLegacyWS,
+		ctx:    ctx,
+		cancel: cancel,
+		subs:   make(map[string]context.CancelFunc),
+	}
+}
+
+// send writes a message, translating graphql-transport-ws types for legacy clients
+func (ws *wsConnection) send(msgType, id string, payload interface{}) error {
+	if ws.legacy && msgType == "next" {
+		msgType = "data"
+	}
+
+	ws.writeMu.Lock()
+	defer ws.writeMu.Unlock()
+
+	if ws.closed {
+		return websocket.ErrCloseSent
+	}
+	ws.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	return ws.conn.WriteJSON(wsOutgoing{ID: id, Type: msgType, Payload: payload})
+}
+
+// sendErrors reports an operation failure. Legacy clients expect a single error object.
+func (ws *wsConnection) sendErrors(id string, errs []GraphQLError) error {
+	if ws.legacy && len(errs) > 0 {
+		return ws.send("error", id, errs[0])
+	}
+	return ws.send("error", id, errs)
+}
+
+// close sends a close frame with the given code and tears down the socket
+func (ws *wsConnection) close(code int, reason string) {
+	ws.writeMu.Lock()
+	defer ws.writeMu.Unlock()
+
+	if ws.closed {
+		return
+	}
+	ws.closed = true
+	ws.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(wsWriteTimeout))
+	ws.conn.Close()
+}
+
+// setExpiry schedules the connection to close when its access token lapses,
+// replacing any earlier schedule
+func (ws *wsConnection) setExpiry(claims *jwt.Claims) {
+	if ws.expiryTimer != nil {
+		ws.expiryTimer.Stop()
+		ws.expiryTimer = nil
+	}
+	if claims.ExpiresAt == nil {
+		return
+	}
+	ws.expiryTimer = time.AfterFunc(time.Until(claims.ExpiresAt.Time), func() {
+		ws.close(closeUnauthorized, "Token expired")
+	})
+}
+
+// shutdown cancels every operation and closes the socket
+func (ws *wsConnection) shutdown() {
+	ws.cancel()
+	if ws.expiryTimer != nil {
+		ws.expiryTimer.Stop()
+	}
+
+	ws.writeMu.Lock()
+	ws.closed = true
+	ws.writeMu.Unlock()
+
+	ws.conn.Close()
+}
+
+// addOperation reserves an operation ID, returning false if it is already in use
+func (ws *wsConnection) addOperation(id string, cancel context.CancelFunc) bool {
+	ws.subsMu.Lock()
+	defer ws.subsMu.Unlock()
+
+	if _, exists := ws.subs[id]; exists {
+		return false
+	}
+	ws.subs[id] = cancel
+	return true
+}
+
+// removeOperation releases an operation ID and cancels its context.
+// It returns false if the operation was already gone, e.g. completed by the client.
+func (ws *wsConnection) removeOperation(id string) bool {
+	ws.subsMu.Lock()
+	cancel, ok := ws.subs[id]
+	delete(ws.subs, id)
+	ws.subsMu.Unlock()
+
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+// keepAlive sends the periodic "ka" message required by the legacy protocol
+func (ws *wsConnection) keepAlive() {
+	ticker := time.NewTicker(wsLegacyKeepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ws.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := ws.send("ka", "", nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// WebSocketHandler handles GraphQL operations over WebSocket.
+// It speaks graphql-transport-ws and falls back to the legacy graphql-ws protocol
+// when the client negotiates it.
+func (h *Handler) WebSocketHandler(c *gin.Context) {
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+
+	ws := newWSConnection(conn)
+	defer ws.shutdown()
+
+	// The client must initialise the connection promptly
+	initTimer := time.AfterFunc(wsInitTimeout, func() {
+		if !ws.acknowledged.Load() {
+			ws.close(closeInitTimeout, "Connection initialisation timeout")
+		}
+	})
+	defer initTimer.Stop()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var msg wsIncoming
+		if err := json.Unmarshal(data, &msg); err != nil || msg.Type == "" {
+			ws.close(closeBadRequest, "Invalid message received")
+			return
+		}
+
+		if !h.handleWSMessage(c, ws, msg) {
+			return
+		}
+	}
+}
+
+// handleWSMessage processes one client message. It returns false once the connection is closed.
+func (h *Handler) handleWSMessage(c *gin.Context, ws *wsConnection, msg wsIncoming) bool {
+	msgType := msg.Type
+	if ws.legacy {
+		switch msgType {
+		case "start":
+			msgType = "subscribe"
+		case "stop":
+			msgType = "complete"
+		}
+	}
+
+	switch msgType {
+	case "connection_init":
+		if ws.initReceived {
+			ws.close(closeTooManyInits, "Too many initialisation requests")
+			return false
+		}
+		ws.initReceived = true
+
+		token := tokenFromPayload(msg.Payload)
+		if token == "" {
+			token = c.GetHeader("Authorization")
+		}
+		claims, err := h.authenticateToken(token)
+		if err != nil {
+			if ws.legacy {
+				ws.send("connection_error", "", map[string]string{"message": "Forbidden"})
+			}
+			// graphql-transport-ws rejects every failed initialisation with 4403; the
+			// reason still tells the client when its token has merely expired
+			_, reason := authCloseFrame(err)
+			ws.close(closeForbidden, reason)
+			return false
+		}
+		ws.userID = claims.UserID
+		ws.deviceID = claims.DeviceID
+		ws.setExpiry(claims)
+		ws.acknowledged.Store(true)
+
+		ws.send("connection_ack", "", tokenExpiryPayload(claims))
+		go h.watchRevocation(ws, claims.UserID)
+		if ws.legacy {
+			ws.send("ka", "", nil)
+			go ws.keepAlive()
+		}
+
+	case "connection_auth":
+		// Swaps in a refreshed access token without dropping active operations
+		if !ws.acknowledged.Load() {
+			ws.close(closeUnauthorized, "Unauthorized")
+			return false
+		}
+
+		claims, err := h.authenticateToken(tokenFromPayload(msg.Payload))
+		if err != nil {
+			ws.close(authCloseFrame(err))
+			return false
+		}
+		// A connection is bound to one user for its lifetime
+		if claims.UserID != ws.userID {
+			ws.close(closeForbidden, "Forbidden")
+			return false
+		}
+		ws.deviceID = claims.DeviceID
+		ws.setExpiry(claims)
+
+		ws.send("connection_auth_ack", "", tokenExpiryPayload(claims))
+
+	case "ping":
+		if ws.legacy {
+			break
+		}
+		var payload interface{}
+		if len(msg.Payload) > 0 {
+			payload = msg.Payload
+		}
+		ws.send("pong", "", payload)
+
+	case "pong":
+		// Keep-alive response; nothing to do
+
+	case "subscribe":
+		if !ws.acknowledged.Load() {
+			ws.close(closeUnauthorized, "Unauthorized")
+			return false
+		}
+		if msg.ID == "" {
+			ws.close(closeBadRequest, "Subscribe message requires an id")
+			return false
+		}
+
+		var req GraphQLRequest
+		if err := json.Unmarshal(msg.Payload, &req); err != nil || strings.TrimSpace(req.Query) == "" {
+			ws.close(closeBadRequest, "Invalid subscribe payload")
+			return false
+		}
+
+		ctx, cancel := context.WithCancel(ws.ctx)
+		ctx = gqlmiddleware.WithUserID(ctx, ws.userID)
+		if ws.deviceID != nil {
+			ctx = gqlmiddleware.WithDeviceID(ctx, *ws.deviceID)
+		}
+
+		if !ws.addOperation(msg.ID, cancel) {
+			cancel()
+			ws.close(closeSubscriberExists, fmt.Sprintf("Subscriber for %s already exists", msg.ID))
+			return false
+		}
+
+		go h.runWSOperation(ctx, ws, msg.ID, req)
+
+	case "complete":
+		// Client no longer wants results; no complete is echoed back
+		ws.removeOperation(msg.ID)
+
+	case "connection_terminate":
+		if !ws.legacy {
+			ws.close(closeBadRequest, "Unknown message type")
+			return false
+		}
+		ws.close(websocket.CloseNormalClosure, "")
+		return false
+
+	default:
+		ws.close(closeBadRequest, "Unknown message type")
+		return false
+	}
+
+	return true
+}
+
+// tokenFromPayload extracts the bearer token from a connection_init or connection_auth payload
+func tokenFromPayload(rawPayload json.RawMessage) string {
+	var payload map[string]interface{}
+	if len(rawPayload) == 0 || json.Unmarshal(rawPayload, &payload) != nil {
+		return ""
+	}
+
+	if auth, ok := payload["Authorization"].(string); ok {
+		return auth
+	} else if auth, ok := payload["authorization"].(string); ok {
+		return auth
+	} else if headers, ok := payload["headers"].(map[string]interface{}); ok {
+		if auth, ok := headers["Authorization"].(string); ok {
+			return auth
+		} else if auth, ok := headers["authorization"].(string); ok {
+			return auth
+		}
+	}
+	return ""
+}
+
+// authenticateToken validates an access token for a streaming transport and checks
+// that its account is still active
+func (h *Handler) authenticateToken(authToken string) (*jwt.Claims, error) {
+	if authToken == "" {
+		return nil, errMissingToken
+	}
+
+	claims, err := h.JWTManager.ValidateToken(strings.TrimPrefix(authToken, "Bearer "))
+	if err != nil {
+		return nil, err
+	}
+
+	if h.isRevoked(claims.UserID) {
+		return nil, errAccessRevoked
+	}
+
+	return claims, nil
+}
+
+// isRevoked reports whether the user has been deleted since their token was issued.
+// A database hiccup should not lock out a valid token, so lookup failures count as active
+// and are retried by the next revalidation.
+func (h *Handler) isRevoked(userID uuid.UUID) bool {
+	exists, err := h.Users.Exists(userID)
+	if err != nil {
+		log.Printf("[GraphQL] isRevoked: failed to check user %s: %v", userID, err)
+		return false
+	}
+	return !exists
+}
+
+// watchRevocation closes the connection once its user has been deleted
+func (h *Handler) watchRevocation(ws *wsConnection, userID uuid.UUID) {
+	ticker := time.NewTicker(authRevalidateInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ws.ctx.Done():
+			return
+		case <-ticker.C:
+			if h.isRevoked(userID) {
+				ws.close(closeForbidden, "Access revoked")
+				return
+			}
+		}
+	}
+}
+
+// tokenExpiryPayload tells the client when to send connection_auth with a fresh token
+func tokenExpiryPayload(claims *jwt.Claims) interface{} {
+	if claims.ExpiresAt == nil {
+		return nil
+	}
+	return map[string]string{"expiresAt": claims.ExpiresAt.Time.UTC().Format(time.RFC3339)}
+}
+
+// authCloseFrame maps an authentication failure on an established connection to a
+// close code and reason. Expired tokens get 4401 so the client refreshes and reconnects.
+func authCloseFrame(err error) (int, string) {
+	switch {
+	case errors.Is(err, errAccessRevoked):
+		return closeForbidden, "Access revoked"
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return closeUnauthorized, "Token expired"
+	default:
+		return closeForbidden, "Forbidden"
+	}
+}
+
+// runWSOperation executes one operation and streams its results until it
+// completes, fails, or the client completes it
+func (h *Handler) runWSOperation(ctx context.Context, ws *wsConnection, id string, req GraphQLRequest) {
+	if !isSubscription(req) {
+		result, _ := h.executeOperation(ctx, req, extensionIdempotencyKey(req))
+		if ws.removeOperation(id) {
+			ws.send("next", id, result)
+			ws.send("complete", id, nil)
+		}
+		return
+	}
+
+	field, events, err := h.resolveSubscription(ctx, req)
+	if err != nil {
+		if ws.removeOperation(id) {
+			ws.sendErrors(id, []GraphQLError{h.graphQLError(err, field)})
+		}
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				if ws.removeOperation(id) {
+					ws.send("complete", id, nil)
+				}
+				return
+			}
+			if err := ws.send("next", id, subscriptionPayload(field, event)); err != nil {
+				ws.removeOperation(id)
+				return
+			}
+		}
+	}
+}
+
+func isSubscription(req GraphQLRequest) bool {
+	return strings.HasPrefix(strings.TrimSpace(req.Query), "subscription")
+}
+
+// resolveSubscription starts the resolver stream for a subscription operation
+// and returns the root field name its events are reported under
+func (h *Handler) resolveSubscription(ctx context.Context, req GraphQLRequest) (string, <-chan interface{}, error) {
+	queryLower := strings.ToLower(req.Query)
+
+	switch {
+	case strings.Contains(queryLower, "syncstream"):
+		var afterCursor *string
+		if cursor, ok := req.Variables["afterCursor"].(string); ok {
+			afterCursor = &cursor
+		}
+		events, err := h.Resolver.SyncStream(ctx, afterCursor)
+		return "syncStream", events, err
+	case strings.Contains(queryLower, "devicechanged"):
+		events, err := h.Resolver.DeviceChanged(ctx)
+		return "deviceChanged", events, err
+	case strings.Contains(queryLower, "remindernotified"):
+		events, err := h.Resolver.ReminderNotified(ctx)
+		return "reminderNotified", events, err
+	case strings.Contains(queryLower, "userchanged"):
+		events, err := h.Resolver.UserChanged(ctx)
+		return "userChanged", events, err
+	case strings.Contains(queryLower, "reminderlistchanged"):
+		listIDs, actions, err := changeFilterVariables(req.Variables)
+		if err != nil {
+			return "reminderListChanged", nil, err
+		}
+		events, err := h.Resolver.ReminderListChanged(ctx, listIDs, actions)
+		return "reminderListChanged", events, err
+	default:
+		// Default: reminderChanged subscription
+		listIDs, actions, err := changeFilterVariables(req.Variables)
+		if err != nil {
+			return "reminderChanged", nil, err
+		}
+		events, err := h.Resolver.ReminderChanged(ctx, listIDs, actions)
+		return "reminderChanged", events, err
+	}
+}
+
+// changeFilterVariables reads the listIds and actions filter arguments of a change subscription
+func changeFilterVariables(variables map[string]interface{}) ([]uuid.UUID, []model.ChangeAction, error) {
+	var listIDs []uuid.UUID
+	if listVar, ok := variables["listIds"]; ok && listVar != nil {
+		listBytes, _ := json.Marshal(listVar)
+		if err := json.Unmarshal(listBytes, &listIDs); err != nil {
+			return nil, nil, apperrors.InvalidFieldsError("Invalid input",
+				apperrors.FieldError{Field: "listIds", Message: "must be a list of UUIDs"})
+		}
+	}
+
+	var actions []model.ChangeAction
+	if actionsVar, ok := variables["actions"]; ok && actionsVar != nil {
+		actionBytes, _ := json.Marshal(actionsVar)
+		if err := json.Unmarshal(actionBytes, &actions); err != nil {
+			return nil, nil, apperrors.InvalidFieldsError("Invalid input",
+				apperrors.FieldError{Field: "actions", Message: "must be a list of ChangeAction values"})
+		}
+		for _, action := range actions {
+			if !action.IsValid() {
+				return nil, nil, apperrors.InvalidFieldsError("Invalid input",
+					apperrors.FieldError{Field: "actions", Message: fmt.Sprintf("unknown change action %q", action)})
+			}
+		}
+	}
+
+	return listIDs, actions, nil
+}
@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/user/remind-me/backend/internal/graphql/model"
+	"github.com/user/remind-me/backend/internal/pubsub"
+)
+
+const reminderChangedSubscription = `subscription { reminderChanged { action } }`
+
+// dialWS connects to the fixture's WebSocket handler with the given subprotocol
+func (f *handlerFixture) dialWS(t *testing.T, protocol string) *websocket.Conn {
+	t.Helper()
+	server := httptest.NewServer(f.router)
+	t.Cleanup(server.Close)
+
+	dialer := websocket.Dialer{Subprotocols: []string{protocol}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func sendWS(t *testing.T, conn *websocket.Conn, msgType, id string, payload interface{}) {
+	t.Helper()
+	msg := map[string]interface{}{"type": msgType}
+	if id != "" {
+		msg["id"] = id
+	}
+	if payload != nil {
+		msg["payload"] = payload
+	}
+	if err := conn.WriteJSON(msg); err != nil {
+		t.Fatalf("send %s: %v", msgType, err)
+	}
+}
+
+// readWS returns the next message, skipping legacy keep-alives
+func readWS(t *testing.T, conn *websocket.Conn) wsIncoming {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		var msg wsIncoming
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		if msg.Type != "ka" {
+			return msg
+		}
+	}
+}
+
+// expectWSClose reads until the server closes the socket and checks the close code
+func expectWSClose(t *testing.T, conn *websocket.Conn, code int, reason string) {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		_, _, err := conn.ReadMessage()
+		if err == nil {
+			continue
+		}
+		var closeErr *websocket.CloseError
+		if !errors.As(err, &closeErr) {
+			t.Fatalf("read error = %v, want close %d", err, code)
+		}
+		if closeErr.Code != code || (reason != "" && closeErr.Text != reason) {
+			t.Fatalf("closed with %d %q, want %d %q", closeErr.Code, closeErr.Text, code, reason)
+		}
+		return
+	}
+}
+
+// initWS opens a graphql-transport-ws connection and completes the handshake
+func (f *handlerFixture) initWS(t *testing.T) *websocket.Conn {
+	t.Helper()
+	conn := f.dialWS(t, protocolTransportWS)
+	sendWS(t, conn, "connection_init", "", map[string]string{"Authorization": "Bearer " + f.token})
+	if msg := readWS(t, conn); msg.Type != "connection_ack" {
+		t.Fatalf("got %q, want connection_ack", msg.Type)
+	}
+	return conn
+}
+
+// waitForSubscribers waits until the hub has n subscribers
+func waitForSubscribers(t *testing.T, hub *pubsub.Hub, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for hub.Stats().Subscribers != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("hub has %d subscribers, want %d", hub.Stats().Subscribers, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWebSocketClosesWithoutInit(t *testing.T) {
+	defer func(timeout time.Duration) { wsInitTimeout = timeout }(wsInitTimeout)
+	wsInitTimeout = 50 * time.Millisecond
+	f := newHandlerFixture(t)
+
+	conn := f.dialWS(t, protocolTransportWS)
+	expectWSClose(t, conn, closeInitTimeout, "Connection initialisation timeout")
+}
+
+func TestWebSocketRejectsSecondInit(t *testing.T) {
+	f := newHandlerFixture(t)
+	conn := f.initWS(t)
+
+	sendWS(t, conn, "connection_init", "", map[string]string{"Authorization": "Bearer " + f.token})
+	expectWSClose(t, conn, closeTooManyInits, "Too many initialisation requests")
+}
+
+func TestWebSocketRejectsFailedInit(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  func(t *testing.T, f *handlerFixture) string
+		reason string
+	}{
+		{"missing token", func(t *testing.T, f *handlerFixture) string { return "" }, "Forbidden"},
+		{"invalid token", func(t *testing.T, f *handlerFixture) string { return "Bearer not-a-token" }, "Forbidden"},
+		{"expired token", func(t *testing.T, f *handlerFixture) string {
+			return "Bearer " + f.tokenExpiringAt(t, time.Now().Add(-time.Minute))
+		}, "Token expired"},
+		{"deleted user", func(t *testing.T, f *handlerFixture) string {
+			f.handler.Users = fakeUsers{}
+			return "Bearer " + f.token
+		}, "Access revoked"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newHandlerFixture(t)
+			conn := f.dialWS(t, protocolTransportWS)
+
+			sendWS(t, conn, "connection_init", "", map[string]string{"Authorization": tt.token(t, f)})
+			expectWSClose(t, conn, closeForbidden, tt.reason)
+		})
+	}
+}
+
+func TestWebSocketClosesWhenTokenLapses(t *testing.T) {
+	f := newHandlerFixture(t)
+	conn := f.dialWS(t, protocolTransportWS)
+
+	token := f.tokenExpiringAt(t, time.Now().Add(time.Second))
+	sendWS(t, conn, "connection_init", "", map[string]string{"Authorization": "Bearer " + token})
+	if msg := readWS(t, conn); msg.Type != "connection_ack" {
+		t.Fatalf("got %q, want connection_ack", msg.Type)
+	}
+	expectWSClose(t, conn, closeUnauthorized, "Token expired")
+}
+
+func TestWebSocketRequiresInitBeforeSubscribe(t *testing.T) {
+	f := newHandlerFixture(t)
+	conn := f.dialWS(t, protocolTransportWS)
+
+	sendWS(t, conn, "subscribe", "1", GraphQLRequest{Query: reminderChangedSubscription})
+	expectWSClose(t, conn, closeUnauthorized, "Unauthorized")
+}
+
+func TestWebSocketRejectsDuplicateOperationID(t *testing.T) {
+	f := newHandlerFixture(t)
+	conn := f.initWS(t)
+
+	sendWS(t, conn, "subscribe", "1", GraphQLRequest{Query: reminderChangedSubscription})
+	sendWS(t, conn, "subscribe", "1", GraphQLRequest{Query: reminderChangedSubscription})
+	expectWSClose(t, conn, closeSubscriberExists, "Subscriber for 1 already exists")
+}
+
+func TestWebSocketStreamsSubscriptionUntilComplete(t *testing.T) {
+	f := newHandlerFixture(t)
+	conn := f.initWS(t)
+
+	sendWS(t, conn, "subscribe", "1", GraphQLRequest{Query: reminderChangedSubscription})
+	waitForSubscribers(t, f.hub, 1)
+	f.hub.Publish(f.userID, pubsub.TopicReminders, &model.ReminderChangeEvent{Action: model.ChangeActionCreated, ReminderID: uuid.New()})
+
+	msg := readWS(t, conn)
+	if msg.Type != "next" || msg.ID != "1" || !strings.Contains(string(msg.Payload), `"reminderChanged"`) {
+		t.Fatalf("got %s %s %s, want the reminderChanged event for operation 1", msg.Type, msg.ID, msg.Payload)
+	}
+
+	// complete ends the subscription and frees its id for reuse
+	sendWS(t, conn, "complete", "1", nil)
+	waitForSubscribers(t, f.hub, 0)
+	sendWS(t, conn, "subscribe", "1", GraphQLRequest{Query: reminderChangedSubscription})
+	waitForSubscribers(t, f.hub, 1)
+}
+
+func TestWebSocketExecutesMutations(t *testing.T) {
+	f := newHandlerFixture(t)
+	conn := f.initWS(t)
+
+	sendWS(t, conn, "subscribe", "m1", GraphQLRequest{Query: logoutMutation})
+	next := readWS(t, conn)
+	var result GraphQLResponse
+	if err := json.Unmarshal(next.Payload, &result); err != nil || next.Type != "next" || next.ID != "m1" {
+		t.Fatalf("got %s %s %s, want the mutation result", next.Type, next.ID, next.Payload)
+	}
+	if data, _ := result.Data.(map[string]interface{}); data["logout"] != true {
+		t.Errorf("result = %s, want logout to be true", next.Payload)
+	}
+	if msg := readWS(t, conn); msg.Type != "complete" || msg.ID != "m1" {
+		t.Errorf("got %s %s, want complete for m1", msg.Type, msg.ID)
+	}
+}
+
+func TestWebSocketAnswersPing(t *testing.T) {
+	f := newHandlerFixture(t)
+	conn := f.initWS(t)
+
+	sendWS(t, conn, "ping", "", map[string]string{"at": "now"})
+	if msg := readWS(t, conn); msg.Type != "pong" || string(msg.Payload) != `{"at":"now"}` {
+		t.Errorf("got %s %s, want pong echoing the payload", msg.Type, msg.Payload)
+	}
+}
+
+func TestLegacyWebSocketStopsSubscription(t *testing.T) {
+	f := newHandlerFixture(t)
+	conn := f.dialWS(t, protocolLegacyWS)
+
+	sendWS(t, conn, "connection_init", "", map[string]string{"Authorization": "Bearer " + f.token})
+	if msg := readWS(t, conn); msg.Type != "connection_ack" {
+		t.Fatalf("got %q, want connection_ack", msg.Type)
+	}
+
+	sendWS(t, conn, "start", "1", GraphQLRequest{Query: reminderChangedSubscription})
+	waitForSubscribers(t, f.hub, 1)
+	f.hub.Publish(f.userID, pubsub.TopicReminders, &model.ReminderChangeEvent{Action: model.ChangeActionUpdated, ReminderID: uuid.New()})
+	if msg := readWS(t, conn); msg.Type != "data" || msg.ID != "1" {
+		t.Fatalf("got %s %s, want data for operation 1", msg.Type, msg.ID)
+	}
+
+	sendWS(t, conn, "stop", "1", nil)
+	waitForSubscribers(t, f.hub, 0)
+
+	sendWS(t, conn, "connection_terminate", "", nil)
+	expectWSClose(t, conn, websocket.CloseNormalClosure, "")
+}
@@ -1,6 +1,8 @@
 package model
 
 import (
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -202,18 +204,40 @@ func (c ChangeAction) String() string {
 	return string(c)
 }
 
+// SyncStatus enum
+type SyncStatus string
+
+const (
+	SyncStatusOK             SyncStatus = "OK"
+	SyncStatusResyncRequired SyncStatus = "RESYNC_REQUIRED"
+)
+
+func (s SyncStatus) IsValid() bool {
+	switch s {
+	case SyncStatusOK, SyncStatusResyncRequired:
+		return true
+	}
+	return false
+}
+
+func (s SyncStatus) String() string {
+	return string(s)
+}
+
 // User type
 type User struct {
-	TypeName     string     `json:"__typename"`
-	ID           uuid.UUID  `json:"id"`
-	Email        string     `json:"email"`
-	DisplayName  string     `json:"displayName"`
-	AvatarURL    *string    `json:"avatarUrl"`
-	Timezone     string     `json:"timezone"`
-	IsPremium    bool       `json:"isPremium"`
-	PremiumUntil *time.Time `json:"premiumUntil"`
-	CreatedAt    time.Time  `json:"createdAt"`
-	UpdatedAt    time.Time  `json:"updatedAt"`
+	TypeName     string          `json:"__typename"`
+	ID           uuid.UUID       `json:"id"`
+	Email        string          `json:"email"`
+	DisplayName  string          `json:"displayName"`
+	AvatarURL    *string         `json:"avatarUrl"`
+	Timezone     string          `json:"timezone"`
+	IsPremium    bool            `json:"isPremium"`
+	PremiumUntil *time.Time      `json:"premiumUntil"`
+	QuietHours   *QuietHours     `json:"quietHours"`
+	Digest       *DigestSettings `json:"digest"`
+	CreatedAt    time.Time       `json:"createdAt"`
+	UpdatedAt    time.Time       `json:"updatedAt"`
 }
 
 func UserFromModel(u *models.User) *User {
@@ -233,6 +257,8 @@ func UserFromModel(u *models.User) *User {
 		Timezone:     u.Timezone,
 		IsPremium:    u.HasActivePremium(),
 		PremiumUntil: u.PremiumUntil,
+		QuietHours:   QuietHoursFromModel(u.QuietHours()),
+		Digest:       DigestSettingsFromModel(u),
 		CreatedAt:    u.CreatedAt,
 		UpdatedAt:    u.UpdatedAt,
 	}
@@ -256,18 +282,63 @@ type AuthenticateWithAppleInput struct {
 	DisplayName    *string `json:"displayName"`
 }
 
+// QuietHours type
+type QuietHours struct {
+	TypeName string `json:"__typename"`
+	Start    string `json:"start"`
+	End      string `json:"end"`
+}
+
+func QuietHoursFromModel(q *models.QuietHours) *QuietHours {
+	if q == nil {
+		return nil
+	}
+	return &QuietHours{
+		TypeName: "QuietHours",
+		Start:    models.FormatTimeOfDay(q.Start),
+		End:      models.FormatTimeOfDay(q.End),
+	}
+}
+
+// DigestSettings type
+type DigestSettings struct {
+	TypeName string      `json:"__typename"`
+	Enabled  bool        `json:"enabled"`
+	Time     string      `json:"time"`
+	Days     []int       `json:"days"`
+	ListIDs  []uuid.UUID `json:"listIds"`
+}
+
+func DigestSettingsFromModel(u *models.User) *DigestSettings {
+	listIDs := make([]uuid.UUID, 0, len(u.DigestListIDs))
+	for _, id := range u.DigestListIDs {
+		if listID, err := uuid.Parse(id); err == nil {
+			listIDs = append(listIDs, listID)
+		}
+	}
+	return &DigestSettings{
+		TypeName: "DigestSettings",
+		Enabled:  u.DigestEnabled,
+		Time:     models.FormatTimeOfDay(u.DigestTime),
+		Days:     u.DigestWeekdays(),
+		ListIDs:  listIDs,
+	}
+}
+
 // Device type
 type Device struct {
-	TypeName         string    `json:"__typename"`
-	ID               uuid.UUID `json:"id"`
-	Platform         Platform  `json:"platform"`
-	DeviceIdentifier string    `json:"deviceIdentifier"`
-	PushToken        string    `json:"pushToken"`
-	DeviceName       *string   `json:"deviceName"`
-	AppVersion       *string   `json:"appVersion"`
-	OsVersion        *string   `json:"osVersion"`
-	LastSeenAt       time.Time `json:"lastSeenAt"`
-	CreatedAt        time.Time `json:"createdAt"`
+	TypeName           string      `json:"__typename"`
+	ID                 uuid.UUID   `json:"id"`
+	Platform           Platform    `json:"platform"`
+	DeviceIdentifier   string      `json:"deviceIdentifier"`
+	PushToken          string      `json:"pushToken"`
+	DeviceName         *string     `json:"deviceName"`
+	AppVersion         *string     `json:"appVersion"`
+	OsVersion          *string     `json:"osVersion"`
+	LastSeenAt         time.Time   `json:"lastSeenAt"`
+	QuietHoursOverride bool        `json:"quietHoursOverride"`
+	QuietHours         *QuietHours `json:"quietHours"`
+	CreatedAt          time.Time   `json:"createdAt"`
 }
 
 func DeviceFromModel(d *models.Device) *Device {
@@ -289,16 +360,18 @@ func DeviceFromModel(d *models.Device) *Device {
 		osVersion = &d.OSVersion
 	}
 	return &Device{
-		TypeName:         "Device",
-		ID:               d.ID,
-		Platform:         platform,
-		DeviceIdentifier: d.DeviceIdentifier,
-		PushToken:        d.PushToken,
-		DeviceName:       deviceName,
-		AppVersion:       appVersion,
-		OsVersion:        osVersion,
-		LastSeenAt:       d.LastSeenAt,
-		CreatedAt:        d.CreatedAt,
+		TypeName:           "Device",
+		ID:                 d.ID,
+		Platform:           platform,
+		DeviceIdentifier:   d.DeviceIdentifier,
+		PushToken:          d.PushToken,
+		DeviceName:         deviceName,
+		AppVersion:         appVersion,
+		OsVersion:          osVersion,
+		LastSeenAt:         d.LastSeenAt,
+		QuietHoursOverride: d.QuietHoursOverride,
+		QuietHours:         QuietHoursFromModel(d.QuietHours()),
+		CreatedAt:          d.CreatedAt,
 	}
 }
 
@@ -424,6 +497,7 @@ type Reminder struct {
 	Version        int             `json:"version"`
 	CreatedAt      time.Time       `json:"createdAt"`
 	UpdatedAt      time.Time       `json:"updatedAt"`
+	Deliveries     []*NotificationDelivery `json:"deliveries"` // Only resolved by Query.reminder, nil elsewhere
 }
 
 func ReminderFromModel(r *models.Reminder) *Reminder {
@@ -522,6 +596,73 @@ type UserChangeEvent struct {
 	Timestamp time.Time    `json:"timestamp"`
 }
 
+// Device change event
+type DeviceChangeEvent struct {
+	TypeName  string       `json:"__typename"`
+	Action    ChangeAction `json:"action"`
+	Device    *Device      `json:"device"`
+	DeviceID  uuid.UUID    `json:"deviceId"`
+	Timestamp time.Time    `json:"timestamp"`
+}
+
+// ReminderNotifiedEvent announces that the server sent a reminder's push notification
+type ReminderNotifiedEvent struct {
+	TypeName   string     `json:"__typename"`
+	ReminderID uuid.UUID  `json:"reminderId"`
+	DueAt      *time.Time `json:"dueAt"`
+	NotifiedAt time.Time  `json:"notifiedAt"`
+}
+
+// DeliveryStatus enum
+type DeliveryStatus string
+
+const (
+	DeliveryStatusSent         DeliveryStatus = "SENT"
+	DeliveryStatusFailed       DeliveryStatus = "FAILED"
+	DeliveryStatusInvalidToken DeliveryStatus = "INVALID_TOKEN"
+)
+
+// DeliveryProvider enum
+type DeliveryProvider string
+
+const (
+	DeliveryProviderAPNs DeliveryProvider = "APNS"
+	DeliveryProviderFCM  DeliveryProvider = "FCM"
+)
+
+// NotificationDelivery is one attempt to push a reminder to one device
+type NotificationDelivery struct {
+	TypeName          string           `json:"__typename"`
+	ID                string           `json:"id"`
+	DeviceID          uuid.UUID        `json:"deviceId"`
+	Provider          DeliveryProvider `json:"provider"`
+	ProviderMessageID *string          `json:"providerMessageId"`
+	Status            DeliveryStatus   `json:"status"`
+	Error             *string          `json:"error"`
+	LatencyMs         int              `json:"latencyMs"`
+	AttemptedAt       time.Time        `json:"attemptedAt"`
+}
+
+func NotificationDeliveryFromModel(d *models.NotificationDelivery) *NotificationDelivery {
+	return &NotificationDelivery{
+		TypeName:          "NotificationDelivery",
+		ID:                strconv.FormatInt(d.ID, 10),
+		DeviceID:          d.DeviceID,
+		Provider:          DeliveryProvider(strings.ToUpper(string(d.Provider))),
+		ProviderMessageID: d.ProviderMessageID,
+		Status:            DeliveryStatus(strings.ToUpper(string(d.Status))),
+		Error:             d.Error,
+		LatencyMs:         d.LatencyMs,
+		AttemptedAt:       d.CreatedAt,
+	}
+}
+
+// EventsDropped is sent on a subscription in place of events the server dropped
+// because the client was not keeping up. Clients should refetch the affected data.
+type EventsDropped struct {
+	Dropped uint64 `json:"dropped"`
+}
+
 // ReminderList change event
 type ReminderListChangeEvent struct {
 	TypeName       string        `json:"__typename"`
@@ -547,6 +688,28 @@ type RegisterDeviceInput struct {
 	OsVersion        *string  `json:"osVersion"`
 }
 
+// UpdateQuietHoursInput type
+type UpdateQuietHoursInput struct {
+	Start    *string `json:"start"`
+	End      *string `json:"end"`
+	Timezone *string `json:"timezone"`
+}
+
+// UpdateDigestSettingsInput type
+type UpdateDigestSettingsInput struct {
+	Enabled bool        `json:"enabled"`
+	Time    *string     `json:"time"`
+	Days    []int       `json:"days"`
+	ListIDs []uuid.UUID `json:"listIds"`
+}
+
+// UpdateDeviceQuietHoursInput type
+type UpdateDeviceQuietHoursInput struct {
+	Override bool    `json:"override"`
+	Start    *string `json:"start"`
+	End      *string `json:"end"`
+}
+
 // Connection types
 type PageInfo struct {
 	TypeName        string  `json:"__typename"`
@@ -571,11 +734,13 @@ type ReminderConnection struct {
 
 // Subscription types
 type ReminderChangeEvent struct {
-	TypeName   string       `json:"__typename"`
-	Action     ChangeAction `json:"action"`
-	Reminder   *Reminder    `json:"reminder"`
-	ReminderID uuid.UUID    `json:"reminderId"`
-	Timestamp  time.Time    `json:"timestamp"`
+	TypeName       string       `json:"__typename"`
+	Action         ChangeAction `json:"action"`
+	Reminder       *Reminder    `json:"reminder"`
+	ReminderID     uuid.UUID    `json:"reminderId"`
+	ListID         *uuid.UUID   `json:"listId"`
+	PreviousListID *uuid.UUID   `json:"previousListId"`
+	Timestamp      time.Time    `json:"timestamp"`
 }
 
 // NotificationSound type
@@ -599,3 +764,33 @@ func NotificationSoundFromModel(s *models.NotificationSound) *NotificationSound
 		IsFree:   s.IsFree,
 	}
 }
+
+// SyncChange is a single entry from the sync event log
+type SyncChange struct {
+	TypeName   string       `json:"__typename"`
+	Cursor     string       `json:"cursor"`
+	EntityType string       `json:"entityType"`
+	EntityID   uuid.UUID    `json:"entityId"`
+	Action     ChangeAction `json:"action"`
+	Reminder   *Reminder    `json:"reminder"`
+	DeviceID   *uuid.UUID   `json:"deviceId"`
+	Timestamp  time.Time    `json:"timestamp"`
+}
+
+// SyncChangesPayload is a page of sync changes after a cursor
+type SyncChangesPayload struct {
+	TypeName string        `json:"__typename"`
+	Status   SyncStatus    `json:"status"`
+	Changes  []*SyncChange `json:"changes"`
+	Cursor   string        `json:"cursor"`
+	HasMore  bool          `json:"hasMore"`
+}
+
+// SyncStreamEvent is one item of the syncStream subscription.
+// Status is RESYNC_REQUIRED with no change when the cursor predates retained history.
+type SyncStreamEvent struct {
+	TypeName string      `json:"__typename"`
+	Status   SyncStatus  `json:"status"`
+	Cursor   string      `json:"cursor"`
+	Change   *SyncChange `json:"change"`
+}
@@ -11,7 +11,7 @@ import (
 	"github.com/user/remind-me/backend/internal/graphql/middleware"
 	"github.com/user/remind-me/backend/internal/graphql/model"
 	"github.com/user/remind-me/backend/internal/models"
-	"github.com/user/remind-me/backend/internal/notification"
+	"github.com/user/remind-me/backend/internal/pubsub"
 	apperrors "github.com/user/remind-me/backend/pkg/errors"
 )
 
@@ -102,29 +102,14 @@ func (r *Resolver) DeleteAccount(ctx context.Context) (bool, error) {
 		return false, apperrors.ErrUnauthorized
 	}
 
-	// 1. Delete RevenueCat subscriber (best-effort)
-	if err := r.SubscriptionService.DeleteSubscriber(userID); err != nil {
-		log.Printf("Warning: failed to delete RevenueCat subscriber for user %s: %v", userID, err)
-	}
-
-	// 2. Soft-delete all reminders
-	if err := r.ReminderService.DeleteAllByUser(userID); err != nil {
-		return false, apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to delete reminders", 500)
-	}
-
-	// 3. Soft-delete all reminder lists
-	if err := r.ReminderListService.DeleteAllByUser(userID); err != nil {
-		return false, apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to delete reminder lists", 500)
-	}
-
-	// 4. Soft-delete all devices
-	if err := r.DeviceRepo.DeleteByUser(userID); err != nil {
-		return false, apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to delete devices", 500)
+	// 1. Soft-delete reminders, lists, devices and the user in one transaction
+	if err := r.AccountService.DeleteAccount(userID); err != nil {
+		return false, err
 	}
 
-	// 5. Soft-delete user (GORM sets deleted_at)
-	if err := r.UserRepo.Delete(userID); err != nil {
-		return false, apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to delete user", 500)
+	// 2. Delete RevenueCat subscriber (best-effort, after the local delete has committed)
+	if err := r.SubscriptionService.DeleteSubscriber(userID); err != nil {
+		log.Printf("Warning: failed to delete RevenueCat subscriber for user %s: %v", userID, err)
 	}
 
 	return true, nil
@@ -137,14 +122,8 @@ func (r *Resolver) RestoreAccount(ctx context.Context) (bool, error) {
 		return false, apperrors.ErrUnauthorized
 	}
 
-	// Restore reminders
-	if err := r.ReminderRepo.RestoreByUserID(userID); err != nil {
-		return false, apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to restore reminders", 500)
-	}
-
-	// Restore reminder lists
-	if err := r.ReminderListRepo.RestoreByUserID(userID); err != nil {
-		return false, apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to restore reminder lists", 500)
+	if err := r.AccountService.RestoreAccount(userID); err != nil {
+		return false, err
 	}
 
 	return true, nil
@@ -172,9 +151,6 @@ func (r *Resolver) VerifySubscription(ctx context.Context) (*model.User, error)
 
 	result := model.UserFromModel(user)
 
-	// Broadcast user change to all devices
-	r.broadcastUserChange(userID, model.ChangeActionUpdated, result)
-
 	return result, nil
 }
 
@@ -215,9 +191,6 @@ func (r *Resolver) CreateReminder(ctx context.Context, input model.CreateReminde
 
 	result := dtoToReminder(reminderDTO)
 
-	// Broadcast change event
-	r.broadcastReminderChange(userID, model.ChangeActionCreated, result)
-
 	return result, nil
 }
 
@@ -264,9 +237,6 @@ func (r *Resolver) UpdateReminder(ctx context.Context, id uuid.UUID, input model
 
 	result := dtoToReminder(reminderDTO)
 
-	// Broadcast change event
-	r.broadcastReminderChange(userID, model.ChangeActionUpdated, result)
-
 	return result, nil
 }
 
@@ -284,14 +254,6 @@ func (r *Resolver) DeleteReminder(ctx context.Context, id uuid.UUID) (bool, erro
 		return false, err
 	}
 
-	// Broadcast delete event
-	r.broadcastReminderDelete(userID, id)
-
-	// Send cross-device notification to dismiss notification on other devices
-	if r.NotificationDispatcher != nil {
-		go r.NotificationDispatcher.SendCrossDeviceAction(ctx, userID, deviceID, id, notification.ActionDelete)
-	}
-
 	return true, nil
 }
 
@@ -311,14 +273,6 @@ func (r *Resolver) SnoozeReminder(ctx context.Context, id uuid.UUID, minutes int
 
 	result := dtoToReminder(reminderDTO)
 
-	// Broadcast change event
-	r.broadcastReminderChange(userID, model.ChangeActionUpdated, result)
-
-	// Send cross-device notification to dismiss notification on other devices
-	if r.NotificationDispatcher != nil {
-		go r.NotificationDispatcher.SendCrossDeviceAction(ctx, userID, deviceID, id, notification.ActionSnooze)
-	}
-
 	return result, nil
 }
 
@@ -338,14 +292,6 @@ func (r *Resolver) CompleteReminder(ctx context.Context, id uuid.UUID) (*model.R
 
 	result := dtoToReminder(reminderDTO)
 
-	// Broadcast change event
-	r.broadcastReminderChange(userID, model.ChangeActionUpdated, result)
-
-	// Send cross-device notification to dismiss notification on other devices
-	if r.NotificationDispatcher != nil {
-		go r.NotificationDispatcher.SendCrossDeviceAction(ctx, userID, deviceID, id, notification.ActionComplete)
-	}
-
 	return result, nil
 }
 
@@ -363,17 +309,6 @@ func (r *Resolver) DismissReminder(ctx context.Context, id uuid.UUID) (bool, err
 		return false, err
 	}
 
-	// Get updated reminder for broadcast
-	reminderDTO, _ := r.ReminderService.GetByID(userID, id)
-	if reminderDTO != nil {
-		r.broadcastReminderChange(userID, model.ChangeActionUpdated, dtoToReminder(reminderDTO))
-	}
-
-	// Send cross-device notification to dismiss notification on other devices
-	if r.NotificationDispatcher != nil {
-		go r.NotificationDispatcher.SendCrossDeviceAction(ctx, userID, deviceID, id, notification.ActionDismiss)
-	}
-
 	return true, nil
 }
 
@@ -411,7 +346,7 @@ func (r *Resolver) RegisterDevice(ctx context.Context, input model.RegisterDevic
 		LastSeenAt:       time.Now(),
 	}
 
-	err := r.DeviceRepo.Upsert(device)
+	err := r.DeviceService.Upsert(device)
 	if err != nil {
 		return nil, err
 	}
@@ -426,40 +361,92 @@ func (r *Resolver) UnregisterDevice(ctx context.Context, id uuid.UUID) (bool, er
 		return false, apperrors.ErrUnauthorized
 	}
 
-	// Verify device belongs to user
-	device, err := r.DeviceRepo.FindByIDAndUser(id, userID)
+	if err := r.DeviceService.Unregister(userID, id); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// UpdateQuietHours sets the current user's quiet hours
+func (r *Resolver) UpdateQuietHours(ctx context.Context, input model.UpdateQuietHoursInput) (*model.User, error) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		return nil, apperrors.ErrUnauthorized
+	}
+
+	user, err := r.UserService.UpdateQuietHours(userID, dto.UpdateQuietHoursRequest{
+		Start:    input.Start,
+		End:      input.End,
+		Timezone: input.Timezone,
+	})
 	if err != nil {
-		return false, apperrors.ErrDeviceNotFound
+		return nil, err
 	}
-	_ = device
 
-	err = r.DeviceRepo.Delete(id)
+	return model.UserFromModel(user), nil
+}
+
+// UpdateDigestSettings sets the current user's daily digest preferences
+func (r *Resolver) UpdateDigestSettings(ctx context.Context, input model.UpdateDigestSettingsInput) (*model.User, error) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		return nil, apperrors.ErrUnauthorized
+	}
+
+	user, err := r.UserService.UpdateDigestSettings(userID, dto.UpdateDigestSettingsRequest{
+		Enabled: input.Enabled,
+		Time:    input.Time,
+		Days:    input.Days,
+		ListIDs: input.ListIDs,
+	})
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	return true, nil
+	return model.UserFromModel(user), nil
+}
+
+// UpdateDeviceQuietHours sets whether a device overrides the user's quiet hours
+func (r *Resolver) UpdateDeviceQuietHours(ctx context.Context, id uuid.UUID, input model.UpdateDeviceQuietHoursInput) (*model.Device, error) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		return nil, apperrors.ErrUnauthorized
+	}
+
+	device, err := r.DeviceService.UpdateQuietHours(userID, id, dto.UpdateDeviceQuietHoursRequest{
+		Override: input.Override,
+		Start:    input.Start,
+		End:      input.End,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return model.DeviceFromModel(device), nil
 }
 
 // Helper functions for broadcasting changes
 
-func (r *Resolver) broadcastReminderChange(userID uuid.UUID, action model.ChangeAction, reminder *model.Reminder) {
+func (r *Resolver) broadcastReminderChange(userID uuid.UUID, action model.ChangeAction, reminder *model.Reminder, previousListID *uuid.UUID) {
 	if r.Hub == nil {
 		return
 	}
 
 	event := &model.ReminderChangeEvent{
-		TypeName:   "ReminderChangeEvent",
-		Action:     action,
-		Reminder:   reminder,
-		ReminderID: reminder.ID,
-		Timestamp:  time.Now(),
+		TypeName:       "ReminderChangeEvent",
+		Action:         action,
+		Reminder:       reminder,
+		ReminderID:     reminder.ID,
+		ListID:         reminder.ListID,
+		PreviousListID: previousListID,
+		Timestamp:      time.Now(),
 	}
 
-	r.Hub.BroadcastToUser(userID, event)
+	r.Hub.Publish(userID, pubsub.TopicReminders, event)
 }
 
-func (r *Resolver) broadcastReminderDelete(userID uuid.UUID, reminderID uuid.UUID) {
+func (r *Resolver) broadcastReminderDelete(userID uuid.UUID, reminderID uuid.UUID, listID *uuid.UUID) {
 	if r.Hub == nil {
 		return
 	}
@@ -469,10 +456,11 @@ func (r *Resolver) broadcastReminderDelete(userID uuid.UUID, reminderID uuid.UUI
 		Action:     model.ChangeActionDeleted,
 		Reminder:   nil,
 		ReminderID: reminderID,
+		ListID:     listID,
 		Timestamp:  time.Now(),
 	}
 
-	r.Hub.BroadcastToUser(userID, event)
+	r.Hub.Publish(userID, pubsub.TopicReminders, event)
 }
 
 func (r *Resolver) broadcastUserChange(userID uuid.UUID, action model.ChangeAction, user *model.User) {
@@ -488,5 +476,36 @@ func (r *Resolver) broadcastUserChange(userID uuid.UUID, action model.ChangeActi
 		Timestamp: time.Now(),
 	}
 
-	r.Hub.BroadcastToUser(userID, event)
+	r.Hub.Publish(userID, pubsub.TopicUser, event)
+}
+
+func (r *Resolver) broadcastDeviceChange(userID uuid.UUID, action model.ChangeAction, deviceID uuid.UUID, device *model.Device) {
+	if r.Hub == nil {
+		return
+	}
+
+	event := &model.DeviceChangeEvent{
+		TypeName:  "DeviceChangeEvent",
+		Action:    action,
+		Device:    device,
+		DeviceID:  deviceID,
+		Timestamp: time.Now(),
+	}
+
+	r.Hub.Publish(userID, pubsub.TopicDevices, event)
+}
+
+func (r *Resolver) broadcastReminderNotified(userID, reminderID uuid.UUID, dueAt *time.Time, notifiedAt time.Time) {
+	if r.Hub == nil {
+		return
+	}
+
+	event := &model.ReminderNotifiedEvent{
+		TypeName:   "ReminderNotifiedEvent",
+		ReminderID: reminderID,
+		DueAt:      dueAt,
+		NotifiedAt: notifiedAt,
+	}
+
+	r.Hub.Publish(userID, pubsub.TopicNotifications, event)
 }
@@ -0,0 +1,126 @@
+package resolver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/user/remind-me/backend/internal/dto"
+	"github.com/user/remind-me/backend/internal/graphql/model"
+	"github.com/user/remind-me/backend/internal/models"
+	"github.com/user/remind-me/backend/internal/notification"
+	"github.com/user/remind-me/backend/internal/outbox"
+	"gorm.io/gorm"
+)
+
+// RegisterOutboxHandlers wires outbox delivery to subscription broadcasts and cross-device pushes
+func (r *Resolver) RegisterOutboxHandlers(relay *outbox.Relay) {
+	relay.Handle(models.OutboxKindReminderChanged, r.deliverReminderChanged)
+	relay.Handle(models.OutboxKindReminderListChanged, r.deliverReminderListChanged)
+	relay.Handle(models.OutboxKindUserChanged, r.deliverUserChanged)
+	relay.Handle(models.OutboxKindCrossDeviceAction, r.deliverCrossDeviceAction)
+	relay.Handle(models.OutboxKindDeviceChanged, r.deliverDeviceChanged)
+	relay.Handle(models.OutboxKindReminderNotified, r.deliverReminderNotified)
+}
+
+func (r *Resolver) deliverReminderChanged(ctx context.Context, event *models.OutboxEvent) error {
+	var payload dto.ReminderChangedPayload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return err
+	}
+
+	if payload.Reminder == nil {
+		r.broadcastReminderDelete(event.UserID, payload.ReminderID, payload.ListID)
+		return nil
+	}
+
+	// Let the scheduler wake for a new or moved due time
+	if r.Scheduler != nil && payload.Reminder.DueAt != nil {
+		r.Scheduler.Notify(*payload.Reminder.DueAt)
+	}
+
+	action := syncActionToChangeAction(models.SyncAction(payload.Action))
+	r.broadcastReminderChange(event.UserID, action, dtoToReminder(payload.Reminder), payload.PreviousListID)
+	return nil
+}
+
+func (r *Resolver) deliverReminderListChanged(ctx context.Context, event *models.OutboxEvent) error {
+	var payload dto.ReminderListChangedPayload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return err
+	}
+
+	if payload.ReminderList == nil {
+		r.broadcastReminderListDelete(event.UserID, payload.ReminderListID)
+		return nil
+	}
+
+	action := syncActionToChangeAction(models.SyncAction(payload.Action))
+	r.broadcastReminderListChange(event.UserID, action, dtoToReminderList(payload.ReminderList))
+	return nil
+}
+
+func (r *Resolver) deliverUserChanged(ctx context.Context, event *models.OutboxEvent) error {
+	var payload dto.UserChangedPayload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return err
+	}
+
+	// Broadcast the current state rather than a snapshot so retries never go backwards
+	user, err := r.UserRepo.FindByID(event.UserID)
+	if err != nil {
+		return err
+	}
+
+	action := syncActionToChangeAction(models.SyncAction(payload.Action))
+	r.broadcastUserChange(event.UserID, action, model.UserFromModel(user))
+	return nil
+}
+
+func (r *Resolver) deliverCrossDeviceAction(ctx context.Context, event *models.OutboxEvent) error {
+	if r.NotificationDispatcher == nil {
+		return nil
+	}
+
+	var payload dto.CrossDeviceActionPayload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return err
+	}
+
+	return r.NotificationDispatcher.SendCrossDeviceAction(ctx, event.UserID, payload.SourceDeviceID, payload.ReminderID, notification.CrossDeviceAction(payload.Action))
+}
+
+func (r *Resolver) deliverDeviceChanged(ctx context.Context, event *models.OutboxEvent) error {
+	var payload dto.DeviceChangedPayload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return err
+	}
+
+	action := syncActionToChangeAction(models.SyncAction(payload.Action))
+	if action == model.ChangeActionDeleted {
+		r.broadcastDeviceChange(event.UserID, action, payload.DeviceID, nil)
+		return nil
+	}
+
+	// Broadcast the current state; a device removed since then needs no update event
+	device, err := r.DeviceRepo.FindByIDAndUser(payload.DeviceID, event.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	r.broadcastDeviceChange(event.UserID, action, device.ID, model.DeviceFromModel(device))
+	return nil
+}
+
+func (r *Resolver) deliverReminderNotified(ctx context.Context, event *models.OutboxEvent) error {
+	var payload dto.ReminderNotifiedPayload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return err
+	}
+
+	r.broadcastReminderNotified(event.UserID, payload.ReminderID, payload.DueAt, payload.NotifiedAt)
+	return nil
+}
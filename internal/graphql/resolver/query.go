@@ -155,6 +155,37 @@ func (r *Resolver) Devices(ctx context.Context) ([]*model.Device, error) {
 	return result, nil
 }
 
+// Bounds for Reminder.deliveries
+const (
+	defaultDeliveriesLimit = 20
+	maxDeliveriesLimit     = 100
+)
+
+// ReminderDeliveries returns the most recent push attempts for one of the user's reminders
+func (r *Resolver) ReminderDeliveries(ctx context.Context, reminderID uuid.UUID, limit *int) ([]*model.NotificationDelivery, error) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		return nil, apperrors.ErrUnauthorized
+	}
+
+	n := defaultDeliveriesLimit
+	if limit != nil && *limit > 0 {
+		n = min(*limit, maxDeliveriesLimit)
+	}
+
+	deliveries, err := r.NotificationDeliveryRepo.ListByReminder(reminderID, userID, n)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.NotificationDelivery, len(deliveries))
+	for i := range deliveries {
+		result[i] = model.NotificationDeliveryFromModel(&deliveries[i])
+	}
+
+	return result, nil
+}
+
 // NotificationSounds returns all available notification sounds
 func (r *Resolver) NotificationSounds(ctx context.Context) ([]*model.NotificationSound, error) {
 	// This query doesn't require authentication - sounds are publicly available
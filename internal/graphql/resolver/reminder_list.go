@@ -8,6 +8,7 @@ import (
 	"github.com/user/remind-me/backend/internal/dto"
 	"github.com/user/remind-me/backend/internal/graphql/middleware"
 	"github.com/user/remind-me/backend/internal/graphql/model"
+	"github.com/user/remind-me/backend/internal/pubsub"
 	apperrors "github.com/user/remind-me/backend/pkg/errors"
 )
 
@@ -66,9 +67,6 @@ func (r *Resolver) CreateReminderList(ctx context.Context, input model.CreateRem
 
 	result := dtoToReminderList(listDTO)
 
-	// Broadcast change event
-	r.broadcastReminderListChange(userID, model.ChangeActionCreated, result)
-
 	return result, nil
 }
 
@@ -93,9 +91,6 @@ func (r *Resolver) UpdateReminderList(ctx context.Context, id uuid.UUID, input m
 
 	result := dtoToReminderList(listDTO)
 
-	// Broadcast change event
-	r.broadcastReminderListChange(userID, model.ChangeActionUpdated, result)
-
 	return result, nil
 }
 
@@ -111,9 +106,6 @@ func (r *Resolver) DeleteReminderList(ctx context.Context, id uuid.UUID) (bool,
 		return false, err
 	}
 
-	// Broadcast delete event
-	r.broadcastReminderListDelete(userID, id)
-
 	return true, nil
 }
 
@@ -134,11 +126,6 @@ func (r *Resolver) ReorderReminderLists(ctx context.Context, ids []uuid.UUID) ([
 		result[i] = dtoToReminderList(&lists[i])
 	}
 
-	// Broadcast change events for reordered lists
-	for _, list := range result {
-		r.broadcastReminderListChange(userID, model.ChangeActionUpdated, list)
-	}
-
 	return result, nil
 }
 
@@ -162,9 +149,6 @@ func (r *Resolver) MoveReminderToList(ctx context.Context, reminderID uuid.UUID,
 
 	result := dtoToReminder(reminderDTO)
 
-	// Broadcast change event
-	r.broadcastReminderChange(userID, model.ChangeActionUpdated, result)
-
 	return result, nil
 }
 
@@ -202,7 +186,7 @@ func (r *Resolver) broadcastReminderListChange(userID uuid.UUID, action model.Ch
 		Timestamp:      time.Now(),
 	}
 
-	r.Hub.BroadcastToUser(userID, event)
+	r.Hub.Publish(userID, pubsub.TopicReminderLists, event)
 }
 
 func (r *Resolver) broadcastReminderListDelete(userID uuid.UUID, listID uuid.UUID) {
@@ -218,5 +202,5 @@ func (r *Resolver) broadcastReminderListDelete(userID uuid.UUID, listID uuid.UUI
 		Timestamp:      time.Now(),
 	}
 
-	r.Hub.BroadcastToUser(userID, event)
+	r.Hub.Publish(userID, pubsub.TopicReminderLists, event)
 }
@@ -1,27 +1,44 @@
 package resolver
 
 import (
+	"github.com/user/remind-me/backend/internal/graphql/model"
 	"github.com/user/remind-me/backend/internal/notification"
 	"github.com/user/remind-me/backend/internal/pubsub"
 	"github.com/user/remind-me/backend/internal/repository"
+	"github.com/user/remind-me/backend/internal/scheduler"
 	"github.com/user/remind-me/backend/internal/service"
 	"github.com/user/remind-me/backend/pkg/jwt"
 )
 
+// Subscription events can cross instances through the hub backend
+func init() {
+	pubsub.RegisterEvent(&model.ReminderChangeEvent{})
+	pubsub.RegisterEvent(&model.ReminderListChangeEvent{})
+	pubsub.RegisterEvent(&model.UserChangeEvent{})
+	pubsub.RegisterEvent(&model.DeviceChangeEvent{})
+	pubsub.RegisterEvent(&model.ReminderNotifiedEvent{})
+}
+
 // Resolver is the root resolver for all GraphQL operations
 type Resolver struct {
-	AuthService            *service.AuthService
-	ReminderService        *service.ReminderService
-	ReminderListService    *service.ReminderListService
-	SubscriptionService    *service.SubscriptionService
-	UserRepo               *repository.UserRepository
-	DeviceRepo             *repository.DeviceRepository
-	ReminderRepo           *repository.ReminderRepository
-	ReminderListRepo       *repository.ReminderListRepository
-	NotificationSoundRepo  *repository.NotificationSoundRepository
-	JWTManager             *jwt.Manager
-	Hub                    *pubsub.Hub
-	NotificationDispatcher *notification.Dispatcher
+	AuthService              *service.AuthService
+	ReminderService          *service.ReminderService
+	ReminderListService      *service.ReminderListService
+	SubscriptionService      *service.SubscriptionService
+	SyncService              *service.SyncService
+	AccountService           *service.AccountService
+	DeviceService            *service.DeviceService
+	UserService              *service.UserService
+	UserRepo                 *repository.UserRepository
+	DeviceRepo               *repository.DeviceRepository
+	ReminderRepo             *repository.ReminderRepository
+	ReminderListRepo         *repository.ReminderListRepository
+	NotificationSoundRepo    *repository.NotificationSoundRepository
+	NotificationDeliveryRepo *repository.NotificationDeliveryRepository
+	JWTManager               *jwt.Manager
+	Hub                      *pubsub.Hub
+	NotificationDispatcher   *notification.Dispatcher
+	Scheduler                *scheduler.Scheduler // nil unless the in-process scheduler is enabled
 }
 
 // NewResolver creates a new Resolver with all dependencies
@@ -30,27 +47,39 @@ func NewResolver(
 	reminderService *service.ReminderService,
 	reminderListService *service.ReminderListService,
 	subscriptionService *service.SubscriptionService,
+	syncService *service.SyncService,
+	accountService *service.AccountService,
+	deviceService *service.DeviceService,
+	userService *service.UserService,
 	userRepo *repository.UserRepository,
 	deviceRepo *repository.DeviceRepository,
 	reminderRepo *repository.ReminderRepository,
 	reminderListRepo *repository.ReminderListRepository,
 	notificationSoundRepo *repository.NotificationSoundRepository,
+	notificationDeliveryRepo *repository.NotificationDeliveryRepository,
 	jwtManager *jwt.Manager,
 	hub *pubsub.Hub,
 	notificationDispatcher *notification.Dispatcher,
+	reminderScheduler *scheduler.Scheduler,
 ) *Resolver {
 	return &Resolver{
-		AuthService:            authService,
-		ReminderService:        reminderService,
-		ReminderListService:    reminderListService,
-		SubscriptionService:    subscriptionService,
-		UserRepo:               userRepo,
-		DeviceRepo:             deviceRepo,
-		ReminderRepo:           reminderRepo,
-		ReminderListRepo:       reminderListRepo,
-		NotificationSoundRepo:  notificationSoundRepo,
-		JWTManager:             jwtManager,
-		Hub:                    hub,
-		NotificationDispatcher: notificationDispatcher,
+		AuthService:              authService,
+		ReminderService:          reminderService,
+		ReminderListService:      reminderListService,
+		SubscriptionService:      subscriptionService,
+		SyncService:              syncService,
+		AccountService:           accountService,
+		DeviceService:            deviceService,
+		UserService:              userService,
+		UserRepo:                 userRepo,
+		DeviceRepo:               deviceRepo,
+		ReminderRepo:             reminderRepo,
+		ReminderListRepo:         reminderListRepo,
+		NotificationSoundRepo:    notificationSoundRepo,
+		NotificationDeliveryRepo: notificationDeliveryRepo,
+		JWTManager:               jwtManager,
+		Hub:                      hub,
+		NotificationDispatcher:   notificationDispatcher,
+		Scheduler:                reminderScheduler,
 	}
 }
@@ -3,145 +3,160 @@ package resolver
 import (
 	"context"
 
+	"github.com/google/uuid"
 	"github.com/user/remind-me/backend/internal/graphql/middleware"
 	"github.com/user/remind-me/backend/internal/graphql/model"
+	"github.com/user/remind-me/backend/internal/pubsub"
 	apperrors "github.com/user/remind-me/backend/pkg/errors"
 )
 
-// ReminderChanged returns a channel that receives reminder change events
-func (r *Resolver) ReminderChanged(ctx context.Context) (<-chan *model.ReminderChangeEvent, error) {
+// ReminderChanged returns a channel that receives reminder change events.
+// Non-empty listIDs or actions restrict delivery to matching events; a reminder moved
+// out of a watched list still matches through its previous list.
+func (r *Resolver) ReminderChanged(ctx context.Context, listIDs []uuid.UUID, actions []model.ChangeAction) (<-chan interface{}, error) {
 	userID, ok := middleware.GetUserID(ctx)
 	if !ok {
 		return nil, apperrors.ErrUnauthorized
 	}
 
-	// Create a channel for this subscription
-	eventChan := make(chan *model.ReminderChangeEvent, 10)
-
-	// Create an internal channel to receive generic events from the hub
-	hubChan := make(chan interface{}, 10)
+	filter := newChangeFilter(listIDs, actions)
+	return r.subscribeTopic(ctx, userID, pubsub.TopicReminders, func(event interface{}) bool {
+		change, ok := event.(*model.ReminderChangeEvent)
+		return !ok || filter.matches(change.Action, change.ListID, change.PreviousListID)
+	}), nil
+}
 
-	// Register with the WebSocket hub
-	if r.Hub != nil {
-		r.Hub.RegisterSubscription(userID, hubChan)
+// ReminderListChanged returns a channel that receives reminder list change events.
+// Non-empty listIDs or actions restrict delivery to matching events.
+func (r *Resolver) ReminderListChanged(ctx context.Context, listIDs []uuid.UUID, actions []model.ChangeAction) (<-chan interface{}, error) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		return nil, apperrors.ErrUnauthorized
 	}
 
-	// Start a goroutine to convert hub events to typed events
-	go func() {
-		defer close(eventChan)
-		defer func() {
-			if r.Hub != nil {
-				r.Hub.UnregisterSubscription(userID, hubChan)
-			}
-		}()
-
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case event, ok := <-hubChan:
-				if !ok {
-					return
-				}
-				// Type assert to our event type
-				if reminderEvent, ok := event.(*model.ReminderChangeEvent); ok {
-					select {
-					case eventChan <- reminderEvent:
-					case <-ctx.Done():
-						return
-					}
-				}
-			}
-		}
-	}()
-
-	return eventChan, nil
+	filter := newChangeFilter(listIDs, actions)
+	return r.subscribeTopic(ctx, userID, pubsub.TopicReminderLists, func(event interface{}) bool {
+		change, ok := event.(*model.ReminderListChangeEvent)
+		return !ok || filter.matches(change.Action, &change.ReminderListID)
+	}), nil
 }
 
-// ReminderListChanged returns a channel that receives reminder list change events
-func (r *Resolver) ReminderListChanged(ctx context.Context) (<-chan *model.ReminderListChangeEvent, error) {
+// UserChanged returns a channel that receives user change events
+func (r *Resolver) UserChanged(ctx context.Context) (<-chan interface{}, error) {
 	userID, ok := middleware.GetUserID(ctx)
 	if !ok {
 		return nil, apperrors.ErrUnauthorized
 	}
 
-	eventChan := make(chan *model.ReminderListChangeEvent, 10)
-	hubChan := make(chan interface{}, 10)
+	return r.subscribeTopic(ctx, userID, pubsub.TopicUser, nil), nil
+}
 
-	if r.Hub != nil {
-		r.Hub.RegisterSubscription(userID, hubChan)
+// DeviceChanged returns a channel that receives device registration changes
+func (r *Resolver) DeviceChanged(ctx context.Context) (<-chan interface{}, error) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		return nil, apperrors.ErrUnauthorized
 	}
 
-	go func() {
-		defer close(eventChan)
-		defer func() {
-			if r.Hub != nil {
-				r.Hub.UnregisterSubscription(userID, hubChan)
-			}
-		}()
-
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case event, ok := <-hubChan:
-				if !ok {
-					return
-				}
-				if listEvent, ok := event.(*model.ReminderListChangeEvent); ok {
-					select {
-					case eventChan <- listEvent:
-					case <-ctx.Done():
-						return
-					}
-				}
-			}
-		}
-	}()
-
-	return eventChan, nil
+	return r.subscribeTopic(ctx, userID, pubsub.TopicDevices, nil), nil
 }
 
-// UserChanged returns a channel that receives user change events
-func (r *Resolver) UserChanged(ctx context.Context) (<-chan *model.UserChangeEvent, error) {
+// ReminderNotified returns a channel that receives an event each time a reminder's
+// push notification is sent
+func (r *Resolver) ReminderNotified(ctx context.Context) (<-chan interface{}, error) {
 	userID, ok := middleware.GetUserID(ctx)
 	if !ok {
 		return nil, apperrors.ErrUnauthorized
 	}
 
-	eventChan := make(chan *model.UserChangeEvent, 10)
-	hubChan := make(chan interface{}, 10)
+	return r.subscribeTopic(ctx, userID, pubsub.TopicNotifications, nil), nil
+}
+
+// subscribeTopic forwards a user's hub events on a topic until ctx is done.
+// Events rejected by match (if set) are skipped. If the hub had to drop events for this
+// subscriber, a *model.EventsDropped is sent so the client knows to resync.
+func (r *Resolver) subscribeTopic(ctx context.Context, userID uuid.UUID, topic pubsub.Topic, match func(event interface{}) bool) <-chan interface{} {
+	eventChan := make(chan interface{}, 10)
 
-	if r.Hub != nil {
-		r.Hub.RegisterSubscription(userID, hubChan)
+	if r.Hub == nil {
+		go func() {
+			<-ctx.Done()
+			close(eventChan)
+		}()
+		return eventChan
 	}
 
+	sub := r.Hub.Subscribe(userID, topic)
+
 	go func() {
 		defer close(eventChan)
-		defer func() {
-			if r.Hub != nil {
-				r.Hub.UnregisterSubscription(userID, hubChan)
-			}
-		}()
+		defer r.Hub.Unsubscribe(sub)
 
+		var reported uint64
 		for {
+			var event interface{}
 			select {
 			case <-ctx.Done():
 				return
-			case event, ok := <-hubChan:
-				if !ok {
-					return
-				}
-				if userEvent, ok := event.(*model.UserChangeEvent); ok {
-					select {
-					case eventChan <- userEvent:
-					case <-ctx.Done():
-						return
-					}
+			case event = <-sub.Events():
+				if match != nil && !match(event) {
+					continue
 				}
+			case <-sub.Overflow():
+				dropped := sub.Dropped()
+				event = &model.EventsDropped{Dropped: dropped - reported}
+				reported = dropped
+			}
+
+			select {
+			case eventChan <- event:
+			case <-ctx.Done():
+				return
 			}
 		}
 	}()
 
-	return eventChan, nil
+	return eventChan
+}
+
+// changeFilter holds the server-side filter arguments of a change subscription.
+// An empty set matches everything.
+type changeFilter struct {
+	lists   map[uuid.UUID]struct{}
+	actions map[model.ChangeAction]struct{}
+}
+
+func newChangeFilter(listIDs []uuid.UUID, actions []model.ChangeAction) changeFilter {
+	filter := changeFilter{
+		lists:   make(map[uuid.UUID]struct{}, len(listIDs)),
+		actions: make(map[model.ChangeAction]struct{}, len(actions)),
+	}
+	for _, id := range listIDs {
+		filter.lists[id] = struct{}{}
+	}
+	for _, action := range actions {
+		filter.actions[action] = struct{}{}
+	}
+	return filter
+}
+
+// matches reports whether a change with the given action, touching any of listIDs, passes the filter
+func (f changeFilter) matches(action model.ChangeAction, listIDs ...*uuid.UUID) bool {
+	if len(f.actions) > 0 {
+		if _, ok := f.actions[action]; !ok {
+			return false
+		}
+	}
+	if len(f.lists) == 0 {
+		return true
+	}
+	for _, id := range listIDs {
+		if id == nil {
+			continue
+		}
+		if _, ok := f.lists[*id]; ok {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,213 @@
+package resolver
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/user/remind-me/backend/internal/dto"
+	"github.com/user/remind-me/backend/internal/graphql/middleware"
+	"github.com/user/remind-me/backend/internal/graphql/model"
+	"github.com/user/remind-me/backend/internal/models"
+	"github.com/user/remind-me/backend/internal/pubsub"
+	"github.com/user/remind-me/backend/internal/service"
+	apperrors "github.com/user/remind-me/backend/pkg/errors"
+)
+
+// ChangesSince returns sync changes after the given cursor.
+// When the cursor predates the retained history the status is RESYNC_REQUIRED and
+// the returned cursor is where the client should resume after a full refetch.
+func (r *Resolver) ChangesSince(ctx context.Context, cursor *string, limit *int) (*model.SyncChangesPayload, error) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		return nil, apperrors.ErrUnauthorized
+	}
+
+	after := ""
+	if cursor != nil {
+		after = *cursor
+	}
+	pageSize := 0
+	if limit != nil {
+		pageSize = *limit
+	}
+
+	resp, err := r.SyncService.GetChangesSince(userID, after, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	status := model.SyncStatusOK
+	if resp.ResyncRequired {
+		status = model.SyncStatusResyncRequired
+	}
+
+	changes := make([]*model.SyncChange, len(resp.Changes))
+	for i := range resp.Changes {
+		changes[i] = syncEventToChange(&resp.Changes[i])
+	}
+
+	return &model.SyncChangesPayload{
+		TypeName: "SyncChangesPayload",
+		Status:   status,
+		Changes:  changes,
+		Cursor:   resp.Cursor,
+		HasMore:  resp.HasMore,
+	}, nil
+}
+
+const (
+	syncStreamPageSize = 100
+	// syncStreamPollInterval re-checks the log in case a hub signal was dropped
+	syncStreamPollInterval = 30 * time.Second
+)
+
+// SyncStream replays sync changes after the given cursor and then streams new ones.
+// Every item carries its cursor, so a client that reconnects with the last cursor it
+// saw resumes without gaps or duplicates. Live changes are read back from the sync log
+// whenever the hub signals a reminder change, which keeps them in sequence order.
+// A user's sync events commit in sequence order, so reading past the highest cursor
+// seen never skips an event that was still uncommitted at the time.
+func (r *Resolver) SyncStream(ctx context.Context, afterCursor *string) (<-chan interface{}, error) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		return nil, apperrors.ErrUnauthorized
+	}
+
+	cursor := ""
+	if afterCursor != nil {
+		cursor = *afterCursor
+	}
+	if _, err := service.ParseSyncCursor(cursor); err != nil {
+		return nil, err
+	}
+
+	eventChan := make(chan interface{}, 10)
+
+	// Subscribe before replaying so changes committed during the replay still wake us.
+	// Hub events are only used as a signal, so dropped ones cost nothing: the log is
+	// re-read either way.
+	var sub *pubsub.Subscription
+	var events <-chan interface{}
+	var overflow <-chan struct{}
+	if r.Hub != nil {
+		sub = r.Hub.Subscribe(userID, pubsub.TopicReminders)
+		events, overflow = sub.Events(), sub.Overflow()
+	}
+
+	go func() {
+		defer close(eventChan)
+		defer func() {
+			if sub != nil {
+				r.Hub.Unsubscribe(sub)
+			}
+		}()
+
+		send := func(event *model.SyncStreamEvent) bool {
+			select {
+			case eventChan <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		// drain emits everything after cursor; it returns false once the subscriber is gone
+		drain := func() bool {
+			for {
+				resp, err := r.SyncService.GetChangesSince(userID, cursor, syncStreamPageSize)
+				if err != nil {
+					log.Printf("SyncStream: failed to read changes for user %s: %v", userID, err)
+					return true
+				}
+
+				if resp.ResyncRequired {
+					cursor = resp.Cursor
+					if !send(&model.SyncStreamEvent{
+						TypeName: "SyncStreamEvent",
+						Status:   model.SyncStatusResyncRequired,
+						Cursor:   cursor,
+					}) {
+						return false
+					}
+					continue
+				}
+
+				for i := range resp.Changes {
+					change := syncEventToChange(&resp.Changes[i])
+					cursor = change.Cursor
+					if !send(&model.SyncStreamEvent{
+						TypeName: "SyncStreamEvent",
+						Status:   model.SyncStatusOK,
+						Cursor:   cursor,
+						Change:   change,
+					}) {
+						return false
+					}
+				}
+
+				if !resp.HasMore {
+					return true
+				}
+			}
+		}
+
+		if !drain() {
+			return
+		}
+
+		ticker := time.NewTicker(syncStreamPollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-events:
+			case <-overflow:
+			case <-ticker.C:
+			}
+
+			if !drain() {
+				return
+			}
+		}
+	}()
+
+	return eventChan, nil
+}
+
+func syncEventToChange(e *dto.SyncEvent) *model.SyncChange {
+	change := &model.SyncChange{
+		TypeName:   "SyncChange",
+		Cursor:     service.FormatSyncCursor(e.Seq),
+		EntityType: e.EntityType,
+		EntityID:   e.EntityID,
+		Action:     syncActionToChangeAction(models.SyncAction(e.Action)),
+		DeviceID:   e.DeviceID,
+		Timestamp:  e.CreatedAt,
+	}
+
+	// Deletes are compacted to tombstones without a payload
+	if e.EntityType == string(models.EntityTypeReminder) && len(e.Payload) > 0 {
+		var reminder models.Reminder
+		data, _ := json.Marshal(e.Payload)
+		if err := json.Unmarshal(data, &reminder); err == nil {
+			change.Reminder = model.ReminderFromModel(&reminder)
+		}
+	}
+
+	return change
+}
+
+func syncActionToChangeAction(a models.SyncAction) model.ChangeAction {
+	switch a {
+	case models.SyncActionCreate:
+		return model.ChangeActionCreated
+	case models.SyncActionDelete:
+		return model.ChangeActionDeleted
+	default:
+		return model.ChangeActionUpdated
+	}
+}
@@ -0,0 +1,49 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/user/remind-me/backend/internal/repository"
+)
+
+// DeliveryRetentionJob handles expiring the notification delivery log and
+// per-device delivery state
+type DeliveryRetentionJob struct {
+	deliveryRepo *repository.NotificationDeliveryRepository
+	targetRepo   *repository.NotificationTargetRepository
+}
+
+// NewDeliveryRetentionJob creates a new delivery retention job handler
+func NewDeliveryRetentionJob(
+	deliveryRepo *repository.NotificationDeliveryRepository,
+	targetRepo *repository.NotificationTargetRepository,
+) *DeliveryRetentionJob {
+	return &DeliveryRetentionJob{
+		deliveryRepo: deliveryRepo,
+		targetRepo:   targetRepo,
+	}
+}
+
+// PruneDeliveries deletes delivery records and targets older than the specified number of days
+// This should be called by a daily cron job
+func (j *DeliveryRetentionJob) PruneDeliveries(ctx context.Context, days int) (int64, error) {
+	log.Printf("[DeliveryRetentionJob] Starting pruning of deliveries older than %d days", days)
+	cutoff := time.Now().AddDate(0, 0, -days)
+
+	count, err := j.deliveryRepo.DeleteOlderThan(cutoff)
+	if err != nil {
+		log.Printf("[DeliveryRetentionJob] Error pruning deliveries: %v", err)
+		return 0, err
+	}
+
+	targets, err := j.targetRepo.DeleteOlderThan(cutoff)
+	if err != nil {
+		log.Printf("[DeliveryRetentionJob] Error pruning notification targets: %v", err)
+		return count, err
+	}
+
+	log.Printf("[DeliveryRetentionJob] Pruned %d notification deliveries and %d targets", count, targets)
+	return count + targets, nil
+}
@@ -4,18 +4,18 @@ import (
 	"context"
 	"log"
 
-	"github.com/user/remind-me/backend/internal/repository"
+	"github.com/user/remind-me/backend/internal/service"
 )
 
 // DeviceCleanupJob handles cleaning up stale devices
 type DeviceCleanupJob struct {
-	deviceRepo *repository.DeviceRepository
+	deviceService *service.DeviceService
 }
 
 // NewDeviceCleanupJob creates a new device cleanup job handler
-func NewDeviceCleanupJob(deviceRepo *repository.DeviceRepository) *DeviceCleanupJob {
+func NewDeviceCleanupJob(deviceService *service.DeviceService) *DeviceCleanupJob {
 	return &DeviceCleanupJob{
-		deviceRepo: deviceRepo,
+		deviceService: deviceService,
 	}
 }
 
@@ -24,7 +24,7 @@ func NewDeviceCleanupJob(deviceRepo *repository.DeviceRepository) *DeviceCleanup
 func (j *DeviceCleanupJob) CleanupStaleDevices(ctx context.Context, days int) (int64, error) {
 	log.Printf("[DeviceCleanupJob] Starting cleanup of devices not seen in %d days", days)
 
-	count, err := j.deviceRepo.DeleteStaleDevices(days)
+	count, err := j.deviceService.DeleteStaleDevices(days)
 	if err != nil {
 		log.Printf("[DeviceCleanupJob] Error cleaning stale devices: %v", err)
 		return 0, err
@@ -0,0 +1,198 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/user/remind-me/backend/internal/models"
+	"github.com/user/remind-me/backend/internal/notification"
+	"github.com/user/remind-me/backend/internal/repository"
+)
+
+// DigestJob sends each user who has opted in a daily summary of the reminders due
+// that day and those overdue
+type DigestJob struct {
+	userRepo     *repository.UserRepository
+	reminderRepo *repository.ReminderRepository
+	dispatcher   *notification.Dispatcher
+}
+
+// NewDigestJob creates a new daily digest job handler
+func NewDigestJob(
+	userRepo *repository.UserRepository,
+	reminderRepo *repository.ReminderRepository,
+	dispatcher *notification.Dispatcher,
+) *DigestJob {
+	return &DigestJob{
+		userRepo:     userRepo,
+		reminderRepo: reminderRepo,
+		dispatcher:   dispatcher,
+	}
+}
+
+// Users with a digest due are claimed in batches under a lease, like due reminders
+const (
+	digestClaimBatchSize = 100
+	digestClaimLease     = 5 * time.Minute
+	digestConcurrency    = 32
+	digestMaxTitles      = 3 // Reminder titles listed before "and N more"
+)
+
+// SendDueDigests sends the digests that are due and returns how many were sent.
+// This should be called by a cron job every few minutes. Concurrent runs are safe: each
+// digest is claimed by exactly one of them.
+func (j *DigestJob) SendDueDigests(ctx context.Context) (int, error) {
+	processed, sentCount := 0, 0
+	for ctx.Err() == nil {
+		users, err := j.userRepo.ClaimDueDigests(time.Now(), digestClaimBatchSize, digestClaimLease)
+		if err != nil {
+			log.Printf("[DigestJob] Error claiming due digests: %v", err)
+			return sentCount, err
+		}
+		if len(users) == 0 {
+			break
+		}
+
+		processed += len(users)
+		sentCount += j.sendClaimed(ctx, users)
+
+		if len(users) < digestClaimBatchSize {
+			break
+		}
+	}
+
+	if processed == 0 {
+		log.Printf("[DigestJob] No digests due")
+		return 0, nil
+	}
+
+	log.Printf("[DigestJob] Completed: sent %d/%d digests", sentCount, processed)
+	return sentCount, nil
+}
+
+// sendClaimed sends the digests of claimed users, digestConcurrency at a time, and
+// returns how many were sent
+func (j *DigestJob) sendClaimed(ctx context.Context, users []models.User) int {
+	var sentCount atomic.Int64
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, digestConcurrency)
+
+	for _, user := range users {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			// Out of time; the rest of the batch is picked up again once its claim expires
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if j.sendDigest(ctx, &user) {
+				sentCount.Add(1)
+			}
+		}()
+	}
+
+	wg.Wait()
+	return int(sentCount.Load())
+}
+
+// sendDigest sends one user's digest, if they have anything due, and schedules their
+// next one. It reports whether a digest was sent.
+func (j *DigestJob) sendDigest(ctx context.Context, user *models.User) bool {
+	now := time.Now()
+	today, err := j.reminderRepo.ListUpcoming(user.ID, now, user.EndOfDay(now))
+	if err != nil {
+		log.Printf("[DigestJob] Failed to load today's reminders for user %s: %v", user.ID, err)
+		return false // The claim expires and it's retried
+	}
+	overdue, err := j.reminderRepo.ListOverdue(user.ID, now)
+	if err != nil {
+		log.Printf("[DigestJob] Failed to load overdue reminders for user %s: %v", user.ID, err)
+		return false
+	}
+	today, overdue = digestFilter(user, today), digestFilter(user, overdue)
+
+	// A digest that fails to send is not retried; by the next one it's out of date
+	sent := false
+	next := user.NextDigestAt(now)
+	if len(today) > 0 || len(overdue) > 0 {
+		quietUntil, err := j.dispatcher.SendToUserOutsideQuietHours(ctx, user, now, digestPayload(today, overdue))
+		switch {
+		case err != nil:
+			log.Printf("[DigestJob] Failed to send digest to user %s: %v", user.ID, err)
+		case quietUntil != nil:
+			// Every device is in quiet hours; the digest is rebuilt and sent when they end
+			next = quietUntil
+		default:
+			sent = true
+		}
+	}
+
+	if err := j.userRepo.SetNextDigestAt(user.ID, next); err != nil {
+		log.Printf("[DigestJob] Failed to schedule next digest for user %s: %v", user.ID, err)
+	}
+	return sent
+}
+
+// digestFilter keeps the reminders in the lists the user's digest includes
+func digestFilter(user *models.User, reminders []models.Reminder) []models.Reminder {
+	included := reminders[:0]
+	for _, reminder := range reminders {
+		if user.DigestIncludes(reminder.ListID) {
+			included = append(included, reminder)
+		}
+	}
+	return included
+}
+
+// digestPayload builds the digest push, e.g. "5 reminders today, 2 overdue", listing
+// overdue reminders first
+func digestPayload(today, overdue []models.Reminder) notification.Payload {
+	var counts []string
+	if len(today) == 1 {
+		counts = append(counts, "1 reminder today")
+	} else if len(today) > 1 {
+		counts = append(counts, fmt.Sprintf("%d reminders today", len(today)))
+	}
+	if len(overdue) > 0 {
+		counts = append(counts, fmt.Sprintf("%d overdue", len(overdue)))
+	}
+
+	var titles []string
+	for _, reminder := range slices.Concat(overdue, today) {
+		if len(titles) == digestMaxTitles {
+			break
+		}
+		titles = append(titles, reminder.Title)
+	}
+	body := strings.Join(titles, ", ")
+	if more := len(today) + len(overdue) - len(titles); more > 0 {
+		body += fmt.Sprintf(" and %d more", more)
+	}
+
+	return notification.Payload{
+		Title:    strings.Join(counts, ", "),
+		Body:     body,
+		Sound:    "default",
+		Category: "DAILY_DIGEST",
+		Data: map[string]string{
+			"type":          "daily_digest",
+			"today_count":   strconv.Itoa(len(today)),
+			"overdue_count": strconv.Itoa(len(overdue)),
+		},
+	}
+}
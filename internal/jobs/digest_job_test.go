@@ -0,0 +1,64 @@
+package jobs
+
+import (
+	"maps"
+	"strconv"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/user/remind-me/backend/internal/models"
+)
+
+// titled returns reminders with the given titles
+func titled(titles ...string) []models.Reminder {
+	list := make([]models.Reminder, len(titles))
+	for i, title := range titles {
+		list[i] = models.Reminder{ID: uuid.New(), Title: title}
+	}
+	return list
+}
+
+func TestDigestPayload(t *testing.T) {
+	tests := []struct {
+		name    string
+		today   []models.Reminder
+		overdue []models.Reminder
+		title   string
+		body    string
+	}{
+		{name: "one today", today: titled("Water plants"), title: "1 reminder today", body: "Water plants"},
+		{name: "several today", today: titled("Water plants", "Call mom"), title: "2 reminders today", body: "Water plants, Call mom"},
+		{name: "only overdue", overdue: titled("Pay rent"), title: "1 overdue", body: "Pay rent"},
+		{name: "overdue listed first", today: titled("Water plants"), overdue: titled("Pay rent"), title: "1 reminder today, 1 overdue", body: "Pay rent, Water plants"},
+		{
+			name:    "more than fit",
+			today:   titled("Water plants", "Call mom", "Gym"),
+			overdue: titled("Pay rent", "Renew passport"),
+			title:   "3 reminders today, 2 overdue",
+			body:    "Pay rent, Renew passport, Water plants and 2 more",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := digestPayload(tt.today, tt.overdue)
+			if payload.Title != tt.title || payload.Body != tt.body {
+				t.Errorf("digest = %q / %q, want %q / %q", payload.Title, payload.Body, tt.title, tt.body)
+			}
+			if payload.ReminderID != uuid.Nil {
+				t.Errorf("digest reminder ID = %s, want none", payload.ReminderID)
+			}
+			if payload.Category != "DAILY_DIGEST" {
+				t.Errorf("category = %q, want DAILY_DIGEST", payload.Category)
+			}
+			want := map[string]string{
+				"type":          "daily_digest",
+				"today_count":   strconv.Itoa(len(tt.today)),
+				"overdue_count": strconv.Itoa(len(tt.overdue)),
+			}
+			if !maps.Equal(payload.Data, want) {
+				t.Errorf("data = %v, want %v", payload.Data, want)
+			}
+		})
+	}
+}
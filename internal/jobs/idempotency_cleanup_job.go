@@ -0,0 +1,35 @@
+package jobs
+
+import (
+	"context"
+	"log"
+
+	"github.com/user/remind-me/backend/internal/repository"
+)
+
+// IdempotencyCleanupJob handles purging expired idempotency keys
+type IdempotencyCleanupJob struct {
+	idempotencyRepo *repository.IdempotencyRepository
+}
+
+// NewIdempotencyCleanupJob creates a new idempotency cleanup job handler
+func NewIdempotencyCleanupJob(idempotencyRepo *repository.IdempotencyRepository) *IdempotencyCleanupJob {
+	return &IdempotencyCleanupJob{
+		idempotencyRepo: idempotencyRepo,
+	}
+}
+
+// CleanupExpiredKeys removes idempotency keys whose replay window has passed
+// This should be called by a daily cron job
+func (j *IdempotencyCleanupJob) CleanupExpiredKeys(ctx context.Context) (int64, error) {
+	log.Printf("[IdempotencyCleanupJob] Starting cleanup of expired idempotency keys")
+
+	count, err := j.idempotencyRepo.DeleteExpired()
+	if err != nil {
+		log.Printf("[IdempotencyCleanupJob] Error deleting expired keys: %v", err)
+		return 0, err
+	}
+
+	log.Printf("[IdempotencyCleanupJob] Deleted %d expired idempotency keys", count)
+	return count, nil
+}
@@ -4,116 +4,241 @@ import (
 	"context"
 	"log"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/user/remind-me/backend/internal/models"
 	"github.com/user/remind-me/backend/internal/notification"
-	"github.com/user/remind-me/backend/internal/repository"
 )
 
+// DueReminderStore claims reminders whose notifications are due
+type DueReminderStore interface {
+	ClaimDueForNotification(now time.Time, limit int, lease time.Duration) ([]models.Reminder, error)
+	DeferNotification(id uuid.UUID, at time.Time) error
+}
+
+// UserStore loads the users whose reminders are due
+type UserStore interface {
+	FindByID(id uuid.UUID) (*models.User, error)
+}
+
+// NotifiedMarker records that a reminder's notification has been pushed
+type NotifiedMarker interface {
+	MarkNotified(reminder *models.Reminder) error
+}
+
 // NotificationJob handles sending notifications for due reminders
 type NotificationJob struct {
-	reminderRepo *repository.ReminderRepository
+	reminderRepo DueReminderStore
+	userRepo     UserStore
 	dispatcher   *notification.Dispatcher
+	notified     NotifiedMarker
 }
 
 // NewNotificationJob creates a new notification job handler
 func NewNotificationJob(
-	reminderRepo *repository.ReminderRepository,
+	reminderRepo DueReminderStore,
+	userRepo UserStore,
 	dispatcher *notification.Dispatcher,
+	notified NotifiedMarker,
 ) *NotificationJob {
 	return &NotificationJob{
 		reminderRepo: reminderRepo,
+		userRepo:     userRepo,
 		dispatcher:   dispatcher,
+		notified:     notified,
 	}
 }
 
-// ProcessDueReminders finds reminders that are due and sends notifications
-// This should be called by a cron job every minute
+// Due reminders are claimed in batches under a lease. The lease must outlast the time a
+// batch takes to send, or another worker may claim the same reminders.
+const (
+	notificationClaimBatchSize = 100
+	notificationClaimLease     = 2 * time.Minute
+	notificationConcurrency    = 32
+)
+
+// ProcessDueReminders claims reminders that are due and sends notifications, then sends
+// the summaries of notifications deferred for quiet hours that have ended.
+// This should be called by a cron job every minute. Concurrent runs are safe: each
+// reminder is claimed by exactly one of them.
 func (j *NotificationJob) ProcessDueReminders(ctx context.Context) (int, error) {
-	// Find reminders that are due now or past due
-	now := time.Now()
+	defer j.sendQuietHoursSummaries(ctx)
 
-	reminders, err := j.reminderRepo.FindDueForNotification(now)
-	if err != nil {
-		log.Printf("[NotificationJob] Error finding due reminders: %v", err)
-		return 0, err
+	processed, sentCount := 0, 0
+	for ctx.Err() == nil {
+		// Claim reminders that are due now or past due
+		reminders, err := j.reminderRepo.ClaimDueForNotification(time.Now(), notificationClaimBatchSize, notificationClaimLease)
+		if err != nil {
+			log.Printf("[NotificationJob] Error claiming due reminders: %v", err)
+			return sentCount, err
+		}
+		if len(reminders) == 0 {
+			break
+		}
+
+		log.Printf("[NotificationJob] Claimed %d reminders due for notification", len(reminders))
+		processed += len(reminders)
+		sentCount += j.sendClaimed(ctx, reminders)
+
+		if len(reminders) < notificationClaimBatchSize {
+			break
+		}
 	}
 
-	if len(reminders) == 0 {
+	if processed == 0 {
 		log.Printf("[NotificationJob] No reminders due for notification")
 		return 0, nil
 	}
 
-	log.Printf("[NotificationJob] Found %d reminders due for notification", len(reminders))
+	log.Printf("[NotificationJob] Completed: sent %d/%d notifications", sentCount, processed)
+	return sentCount, nil
+}
 
-	sentCount := 0
+// sendQuietHoursSummaries sends the summaries due now. Summaries not sent are picked up
+// by a later run.
+func (j *NotificationJob) sendQuietHoursSummaries(ctx context.Context) {
+	sent, err := j.dispatcher.SendQuietHoursSummaries(ctx)
+	if err != nil {
+		log.Printf("[NotificationJob] Error sending quiet-hours summaries: %v", err)
+	}
+	if sent > 0 {
+		log.Printf("[NotificationJob] Sent %d quiet-hours summaries", sent)
+	}
+}
+
+// sendClaimed sends notifications for claimed reminders, notificationConcurrency at a
+// time, and returns how many finished. The dispatcher's worker pool bounds the sends
+// themselves; this only keeps enough reminders in progress to keep the pool busy.
+func (j *NotificationJob) sendClaimed(ctx context.Context, reminders []models.Reminder) int {
+	// Load each user once; their timezone and quiet hours decide which devices are alerted
+	users := make(map[uuid.UUID]*models.User)
 	for _, reminder := range reminders {
-		// Determine the notification sound
-		// Use custom sound filename if set, otherwise default
-		notificationSound := "default"
-		if reminder.SoundID != nil && *reminder.SoundID != "" {
-			// SoundID is the filename (e.g., "ambient.wav")
-			notificationSound = *reminder.SoundID
+		if _, ok := users[reminder.UserID]; ok {
+			continue
 		}
-
-		// Build the notification payload
-		// Title = reminder title, Body = notes (if any)
-		body := ""
-		if reminder.Notes != nil && *reminder.Notes != "" {
-			body = *reminder.Notes
+		user, err := j.userRepo.FindByID(reminder.UserID)
+		if err != nil {
+			log.Printf("[NotificationJob] Failed to load user %s: %v", reminder.UserID, err)
 		}
+		users[reminder.UserID] = user
+	}
 
-		payload := notification.Payload{
-			Title:      reminder.Title,
-			Body:       body,
-			Sound:      notificationSound,
-			Category:   "REMINDER_ACTIONS",
-			ReminderID: reminder.ID,
-			DueAt:      reminder.DueAt.Format(time.RFC3339),
-			Data: map[string]string{
-				"type":        "reminder_due",
-				"reminder_id": reminder.ID.String(),
-				"is_alarm":    strconv.FormatBool(reminder.IsAlarm),
-			},
-		}
+	var sentCount atomic.Int64
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, notificationConcurrency)
 
-		// Add sound_id to notification data for foreground handling
-		if reminder.SoundID != nil && *reminder.SoundID != "" {
-			payload.Data["sound_id"] = *reminder.SoundID
+	for _, reminder := range reminders {
+		user := users[reminder.UserID]
+		if user == nil {
+			// Retried once the claim expires
+			continue
 		}
 
-		// Add notes to data payload for in-app banner
-		if reminder.Notes != nil && *reminder.Notes != "" {
-			payload.Data["notes"] = *reminder.Notes
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
 		}
-
-		// Set alarm category if this is an alarm
-		if reminder.IsAlarm {
-			payload.Category = "ALARM_ACTIONS"
-			payload.Data["type"] = "alarm_due"
+		if ctx.Err() != nil {
+			// Out of time; the rest of the batch is picked up again once its claim expires
+			break
 		}
 
-		// Send notification to all user's devices
-		err := j.dispatcher.SendToUser(ctx, reminder.UserID, payload)
-		if err != nil {
-			log.Printf("[NotificationJob] Failed to send notification for reminder %s: %v", reminder.ID, err)
-			// Continue with other reminders even if one fails
-			continue
-		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if j.sendReminder(ctx, user, reminder) {
+				sentCount.Add(1)
+			}
+		}()
+	}
 
-		// Mark the reminder as notification sent
-		err = j.reminderRepo.MarkNotificationSent(reminder.ID)
-		if err != nil {
-			log.Printf("[NotificationJob] Failed to mark notification sent for reminder %s: %v", reminder.ID, err)
-			// Continue anyway - the notification was sent
+	wg.Wait()
+	return int(sentCount.Load())
+}
+
+// sendReminder notifies the user's devices of one claimed reminder and reports whether
+// the reminder is finished
+func (j *NotificationJob) sendReminder(ctx context.Context, user *models.User, reminder models.Reminder) bool {
+	// Determine the notification sound
+	// Use custom sound filename if set, otherwise default
+	notificationSound := "default"
+	if reminder.SoundID != nil && *reminder.SoundID != "" {
+		// SoundID is the filename (e.g., "ambient.wav")
+		notificationSound = *reminder.SoundID
+	}
+
+	// Build the notification payload
+	// Title = reminder title, Body = notes (if any)
+	body := ""
+	if reminder.Notes != nil && *reminder.Notes != "" {
+		body = *reminder.Notes
+	}
+
+	payload := notification.Payload{
+		Title:      reminder.Title,
+		Body:       body,
+		Sound:      notificationSound,
+		Category:   "REMINDER_ACTIONS",
+		ReminderID: reminder.ID,
+		DueAt:      reminder.DueAt.Format(time.RFC3339),
+		IsAlarm:    reminder.IsAlarm,
+		Data: map[string]string{
+			"type":        "reminder_due",
+			"reminder_id": reminder.ID.String(),
+			"is_alarm":    strconv.FormatBool(reminder.IsAlarm),
+		},
+	}
+
+	// Add sound_id to notification data for foreground handling
+	if reminder.SoundID != nil && *reminder.SoundID != "" {
+		payload.Data["sound_id"] = *reminder.SoundID
+	}
+
+	// Add notes to data payload for in-app banner
+	if reminder.Notes != nil && *reminder.Notes != "" {
+		payload.Data["notes"] = *reminder.Notes
+	}
+
+	// Set alarm category if this is an alarm
+	if reminder.IsAlarm {
+		payload.Category = "ALARM_ACTIONS"
+		payload.Data["type"] = "alarm_due"
+	}
+
+	// Send notification to the user's devices that haven't received it yet.
+	// Devices that failed are retried on a later run, so the reminder stays due
+	// until every device has been delivered to, deferred for quiet hours or dead-lettered.
+	// The dispatcher sets the badge to the user's count at send time.
+	retryAt, err := j.dispatcher.SendReminder(ctx, user, *reminder.DueAt, payload)
+	if err != nil {
+		log.Printf("[NotificationJob] Failed to send notification for reminder %s: %v", reminder.ID, err)
+		// The claim expires and it's retried
+		return false
+	}
+	if retryAt != nil {
+		// Hold the claim until the next retry is due
+		if err := j.reminderRepo.DeferNotification(reminder.ID, *retryAt); err != nil {
+			log.Printf("[NotificationJob] Failed to defer reminder %s: %v", reminder.ID, err)
 		}
+		log.Printf("[NotificationJob] Notification for reminder %s has devices awaiting retry", reminder.ID)
+		return false
+	}
 
-		sentCount++
-		log.Printf("[NotificationJob] Sent notification for reminder %s to user %s", reminder.ID, reminder.UserID)
+	// Mark the reminder as notification sent and tell the user's devices,
+	// so they can drop the matching local notification
+	if err := j.notified.MarkNotified(&reminder); err != nil {
+		log.Printf("[NotificationJob] Failed to mark notification sent for reminder %s: %v", reminder.ID, err)
+		// Continue anyway - the notification was sent
 	}
 
-	log.Printf("[NotificationJob] Completed: sent %d/%d notifications", sentCount, len(reminders))
-	return sentCount, nil
+	log.Printf("[NotificationJob] Sent notification for reminder %s to user %s", reminder.ID, reminder.UserID)
+	return true
 }
 
 // ProcessDueRemindersResult represents the result of processing due reminders
@@ -0,0 +1,152 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/user/remind-me/backend/internal/models"
+	"github.com/user/remind-me/backend/internal/notification"
+	"github.com/user/remind-me/backend/internal/notification/fcm"
+	"github.com/user/remind-me/backend/internal/notification/notificationtest"
+	"gorm.io/gorm"
+)
+
+// fakeReminders hands out its due reminders on the first claim
+type fakeReminders struct {
+	mu       sync.Mutex
+	due      []models.Reminder
+	deferred map[uuid.UUID]time.Time
+	notified []uuid.UUID
+}
+
+func (s *fakeReminders) ClaimDueForNotification(now time.Time, limit int, lease time.Duration) ([]models.Reminder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	claimed := s.due[:min(limit, len(s.due))]
+	s.due = s.due[len(claimed):]
+	return claimed, nil
+}
+
+func (s *fakeReminders) DeferNotification(id uuid.UUID, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deferred[id] = at
+	return nil
+}
+
+func (s *fakeReminders) MarkNotified(reminder *models.Reminder) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.notified = append(s.notified, reminder.ID)
+	return nil
+}
+
+type fakeUsers map[uuid.UUID]*models.User
+
+func (u fakeUsers) FindByID(id uuid.UUID) (*models.User, error) {
+	if user, ok := u[id]; ok {
+		return user, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func TestProcessDueReminders(t *testing.T) {
+	user := &models.User{ID: uuid.New(), Timezone: "UTC"}
+	phone := models.Device{ID: uuid.New(), UserID: user.ID, Platform: models.PlatformIOS, PushToken: "ios-token"}
+	tablet := models.Device{ID: uuid.New(), UserID: user.ID, Platform: models.PlatformAndroid, PushToken: "android-token"}
+
+	ios := notification.NewFakeProvider(models.DeliveryProviderAPNs)
+	android := notification.NewFakeProvider(models.DeliveryProviderFCM)
+	devices := notificationtest.NewDevices(phone, tablet)
+	dispatcher := notification.NewDispatcher(ios, android, devices, &notificationtest.Deliveries{}, notificationtest.NewTargets(), devices, notificationtest.Badges(1), notification.Config{})
+
+	dueAt := time.Now().Add(-time.Minute)
+	sound := "ambient.wav"
+	delivered := models.Reminder{ID: uuid.New(), UserID: user.ID, Title: "Water plants", DueAt: &dueAt, SoundID: &sound}
+	alarm := models.Reminder{ID: uuid.New(), UserID: user.ID, Title: "Wake up", DueAt: &dueAt, IsAlarm: true}
+	reminders := &fakeReminders{due: []models.Reminder{delivered, alarm}, deferred: make(map[uuid.UUID]time.Time)}
+
+	job := NewNotificationJob(reminders, fakeUsers{user.ID: user}, dispatcher, reminders)
+	sent, err := job.ProcessDueReminders(context.Background())
+	if err != nil {
+		t.Fatalf("ProcessDueReminders: %v", err)
+	}
+	if sent != 2 {
+		t.Errorf("sent = %d, want 2", sent)
+	}
+	if len(reminders.notified) != 2 || len(reminders.deferred) != 0 {
+		t.Errorf("marked %d notified and deferred %d, want 2 notified and none deferred", len(reminders.notified), len(reminders.deferred))
+	}
+
+	pushes := ios.Pushes()
+	if len(pushes) != 2 || len(android.Pushes()) != 2 {
+		t.Fatalf("got %d iOS and %d Android pushes, want 2 each", len(pushes), len(android.Pushes()))
+	}
+	for _, push := range pushes {
+		alert := push.Alert
+		switch alert.ReminderID {
+		case delivered.ID:
+			if alert.Sound != sound || alert.Category != "REMINDER_ACTIONS" || alert.Data["type"] != "reminder_due" || alert.Data["sound_id"] != sound {
+				t.Errorf("reminder alert = %+v, want the reminder's sound and actions", alert)
+			}
+		case alarm.ID:
+			if !alert.IsAlarm || alert.Category != "ALARM_ACTIONS" || alert.Data["type"] != "alarm_due" {
+				t.Errorf("alarm alert = %+v, want alarm actions", alert)
+			}
+		default:
+			t.Errorf("alert for unexpected reminder %s", alert.ReminderID)
+		}
+	}
+}
+
+func TestProcessDueRemindersDefersRetryableFailures(t *testing.T) {
+	user := &models.User{ID: uuid.New(), Timezone: "UTC"}
+	tablet := models.Device{ID: uuid.New(), UserID: user.ID, Platform: models.PlatformAndroid, PushToken: "android-token"}
+
+	android := notification.NewFakeProvider(models.DeliveryProviderFCM)
+	android.FailToken("android-token", &fcm.Error{StatusCode: 503, Code: "UNAVAILABLE"})
+	devices := notificationtest.NewDevices(tablet)
+	dispatcher := notification.NewDispatcher(nil, android, devices, &notificationtest.Deliveries{}, notificationtest.NewTargets(), devices, nil, notification.Config{})
+
+	dueAt := time.Now().Add(-time.Minute)
+	reminder := models.Reminder{ID: uuid.New(), UserID: user.ID, Title: "Water plants", DueAt: &dueAt}
+	reminders := &fakeReminders{due: []models.Reminder{reminder}, deferred: make(map[uuid.UUID]time.Time)}
+
+	job := NewNotificationJob(reminders, fakeUsers{user.ID: user}, dispatcher, reminders)
+	sent, err := job.ProcessDueReminders(context.Background())
+	if err != nil {
+		t.Fatalf("ProcessDueReminders: %v", err)
+	}
+	if sent != 0 {
+		t.Errorf("sent = %d, want 0", sent)
+	}
+	if len(reminders.notified) != 0 {
+		t.Errorf("marked %v notified, want none", reminders.notified)
+	}
+	if retryAt, ok := reminders.deferred[reminder.ID]; !ok || !retryAt.After(time.Now()) {
+		t.Errorf("deferred until %v (%t), want a retry in the future", retryAt, ok)
+	}
+}
+
+func TestProcessDueRemindersSkipsUnknownUsers(t *testing.T) {
+	dispatcher := notification.NewDispatcher(nil, nil, notificationtest.NewDevices(), &notificationtest.Deliveries{}, notificationtest.NewTargets(), nil, nil, notification.Config{})
+
+	dueAt := time.Now().Add(-time.Minute)
+	reminder := models.Reminder{ID: uuid.New(), UserID: uuid.New(), Title: "Orphaned", DueAt: &dueAt}
+	reminders := &fakeReminders{due: []models.Reminder{reminder}, deferred: make(map[uuid.UUID]time.Time)}
+
+	job := NewNotificationJob(reminders, fakeUsers{}, dispatcher, reminders)
+	sent, err := job.ProcessDueReminders(context.Background())
+	if err != nil {
+		t.Fatalf("ProcessDueReminders: %v", err)
+	}
+	if sent != 0 || len(reminders.notified) != 0 || len(reminders.deferred) != 0 {
+		t.Errorf("sent %d, notified %v, deferred %v; want the reminder left for its claim to expire", sent, reminders.notified, reminders.deferred)
+	}
+}
@@ -0,0 +1,59 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/user/remind-me/backend/internal/repository"
+)
+
+// SyncRetentionJob handles compacting and expiring the sync event log
+type SyncRetentionJob struct {
+	syncRepo *repository.SyncRepository
+}
+
+// NewSyncRetentionJob creates a new sync retention job handler
+func NewSyncRetentionJob(syncRepo *repository.SyncRepository) *SyncRetentionJob {
+	return &SyncRetentionJob{
+		syncRepo: syncRepo,
+	}
+}
+
+// CompactAndPrune collapses each entity's history down to its latest event and then
+// deletes events older than the specified number of days. Deleting events advances the
+// per-user watermark so clients with older cursors are told to resync.
+// This should be called by a daily cron job
+func (j *SyncRetentionJob) CompactAndPrune(ctx context.Context, days int) (*SyncRetentionResult, error) {
+	if days < 1 {
+		return nil, fmt.Errorf("sync retention must keep at least 1 day, got %d", days)
+	}
+	log.Printf("[SyncRetentionJob] Starting compaction and pruning of sync events older than %d days", days)
+
+	compacted, err := j.syncRepo.CompactEntityHistory(ctx)
+	if err != nil {
+		log.Printf("[SyncRetentionJob] Error compacting sync events: %v", err)
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		log.Printf("[SyncRetentionJob] Context cancelled after compacting %d events", compacted)
+		return &SyncRetentionResult{Compacted: compacted}, err
+	}
+
+	pruned, err := j.syncRepo.DeleteOldEvents(ctx, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		log.Printf("[SyncRetentionJob] Error pruning sync events: %v", err)
+		return &SyncRetentionResult{Compacted: compacted}, err
+	}
+
+	log.Printf("[SyncRetentionJob] Compacted %d and pruned %d sync events", compacted, pruned)
+	return &SyncRetentionResult{Compacted: compacted, Pruned: pruned}, nil
+}
+
+// SyncRetentionResult represents the result of a sync retention run
+type SyncRetentionResult struct {
+	Compacted int64 `json:"compacted"`
+	Pruned    int64 `json:"pruned"`
+}
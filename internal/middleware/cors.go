@@ -32,6 +32,7 @@ func CORSMiddleware() gin.HandlerFunc {
 			"Authorization",
 			"X-Requested-With",
 			"X-Device-ID",
+			"Idempotency-Key",
 			"Upgrade",
 			"Connection",
 			"Sec-WebSocket-Key",
@@ -41,6 +42,7 @@ func CORSMiddleware() gin.HandlerFunc {
 		ExposeHeaders: []string{
 			"Content-Length",
 			"Content-Type",
+			"Idempotent-Replayed",
 		},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
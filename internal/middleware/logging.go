@@ -30,8 +30,8 @@ func LoggingMiddleware() gin.HandlerFunc {
 
 		// Get user ID if available
 		userID := ""
-		if id, exists := c.Get(UserIDKey); exists {
-			userID = id.(string)
+		if id, ok := GetUserID(c); ok {
+			userID = id.String()
 		}
 
 		// Log the request
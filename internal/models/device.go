@@ -24,8 +24,12 @@ type Device struct {
 	AppVersion       string    `gorm:"size:20" json:"app_version"`
 	OSVersion        string    `gorm:"size:20" json:"os_version"`
 	LastSeenAt       time.Time `gorm:"default:now()" json:"last_seen_at"`
-	CreatedAt        time.Time `json:"created_at"`
-	UpdatedAt        time.Time `json:"updated_at"`
+	// When set, the device's own quiet hours (or none, if unset) replace the user's
+	QuietHoursOverride bool      `gorm:"not null;default:false" json:"quiet_hours_override"`
+	QuietHoursStart    *int      `gorm:"type:smallint" json:"quiet_hours_start,omitempty"`
+	QuietHoursEnd      *int      `gorm:"type:smallint" json:"quiet_hours_end,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
 
 	// Relations
 	User *User `gorm:"foreignKey:UserID" json:"-"`
@@ -45,3 +49,8 @@ func (d *Device) IsIOS() bool {
 func (d *Device) IsAndroid() bool {
 	return d.Platform == PlatformAndroid
 }
+
+// QuietHours returns the device's own quiet hours, or nil if it has none
+func (d *Device) QuietHours() *QuietHours {
+	return newQuietHours(d.QuietHoursStart, d.QuietHoursEnd)
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AllDigestDays is the DigestDays mask for every day of the week
+const AllDigestDays = 1<<7 - 1
+
+// DigestDaysMask converts weekdays (0=Sunday) to a DigestDays mask
+func DigestDaysMask(days []int) int {
+	mask := 0
+	for _, day := range days {
+		mask |= 1 << day
+	}
+	return mask
+}
+
+// DigestWeekdays returns the weekdays (0=Sunday) the user gets a digest on
+func (u *User) DigestWeekdays() []int {
+	days := []int{}
+	for day := 0; day < 7; day++ {
+		if u.DigestDays&(1<<day) != 0 {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// NextDigestAt returns when the user's next digest is due after t, or nil if they
+// don't get digests
+func (u *User) NextDigestAt(t time.Time) *time.Time {
+	if !u.DigestEnabled || u.DigestDays&AllDigestDays == 0 {
+		return nil
+	}
+
+	local := t.In(u.Location())
+	for i := 0; i <= 7; i++ {
+		at := time.Date(local.Year(), local.Month(), local.Day()+i, u.DigestTime/60, u.DigestTime%60, 0, 0, local.Location())
+		if at.After(t) && u.DigestDays&(1<<at.Weekday()) != 0 {
+			return &at
+		}
+	}
+	return nil
+}
+
+// DigestIncludes reports whether a reminder in the given list belongs in the user's
+// digest. When the digest is limited to some lists, reminders outside any list are left out.
+func (u *User) DigestIncludes(listID *uuid.UUID) bool {
+	if len(u.DigestListIDs) == 0 {
+		return true
+	}
+	if listID == nil {
+		return false
+	}
+	for _, id := range u.DigestListIDs {
+		if id == listID.String() {
+			return true
+		}
+	}
+	return false
+}
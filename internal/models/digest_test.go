@@ -0,0 +1,54 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUserNextDigestAt(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	weekdays := DigestDaysMask([]int{1, 2, 3, 4, 5})
+	at := func(year int, month time.Month, day, hour, minute int, loc *time.Location) *time.Time {
+		t := time.Date(year, month, day, hour, minute, 0, 0, loc)
+		return &t
+	}
+
+	tests := []struct {
+		name     string
+		enabled  bool
+		timezone string
+		time     int
+		days     int
+		t        time.Time
+		want     *time.Time
+	}{
+		{name: "disabled", time: 8 * 60, days: AllDigestDays, t: time.Date(2026, 6, 1, 7, 0, 0, 0, newYork)},
+		{name: "empty mask", enabled: true, time: 8 * 60, t: time.Date(2026, 6, 1, 7, 0, 0, 0, newYork)},
+		{name: "mask without weekdays", enabled: true, time: 8 * 60, days: 1 << 7, t: time.Date(2026, 6, 1, 7, 0, 0, 0, newYork)},
+		{name: "later today", enabled: true, time: 8 * 60, days: AllDigestDays, t: time.Date(2026, 6, 1, 7, 0, 0, 0, newYork), want: at(2026, 6, 1, 8, 0, newYork)},
+		{name: "at digest time", enabled: true, time: 8 * 60, days: AllDigestDays, t: time.Date(2026, 6, 1, 8, 0, 0, 0, newYork), want: at(2026, 6, 2, 8, 0, newYork)},
+		{name: "tomorrow", enabled: true, time: 8 * 60, days: AllDigestDays, t: time.Date(2026, 6, 1, 9, 0, 0, 0, newYork), want: at(2026, 6, 2, 8, 0, newYork)},
+		{name: "weekdays skip the weekend", enabled: true, time: 8 * 60, days: weekdays, t: time.Date(2026, 10, 16, 9, 0, 0, 0, newYork), want: at(2026, 10, 19, 8, 0, newYork)},
+		{name: "one day a week, already past", enabled: true, time: 8 * 60, days: DigestDaysMask([]int{1}), t: time.Date(2026, 6, 1, 9, 0, 0, 0, newYork), want: at(2026, 6, 8, 8, 0, newYork)},
+		{name: "user's day, not t's", enabled: true, time: 23*60 + 30, days: DigestDaysMask([]int{1}), t: time.Date(2026, 6, 2, 3, 0, 0, 0, time.UTC), want: at(2026, 6, 1, 23, 30, newYork)}, // Monday 23:00 EDT
+		{name: "across spring forward", enabled: true, time: 8 * 60, days: AllDigestDays, t: time.Date(2026, 3, 7, 9, 0, 0, 0, newYork), want: at(2026, 3, 8, 8, 0, newYork)},
+		{name: "across fall back", enabled: true, time: 8 * 60, days: AllDigestDays, t: time.Date(2026, 10, 31, 9, 0, 0, 0, newYork), want: at(2026, 11, 1, 8, 0, newYork)},
+		{name: "unknown timezone", enabled: true, timezone: "Mars/Olympus_Mons", time: 8 * 60, days: AllDigestDays, t: time.Date(2026, 6, 1, 7, 0, 0, 0, time.UTC), want: at(2026, 6, 1, 8, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.timezone == "" {
+				tt.timezone = "America/New_York"
+			}
+			user := User{Timezone: tt.timezone, DigestEnabled: tt.enabled, DigestTime: tt.time, DigestDays: tt.days}
+			got := user.NextDigestAt(tt.t)
+			switch {
+			case tt.want == nil && got != nil:
+				t.Errorf("NextDigestAt(%v) = %v, want nil", tt.t, got)
+			case tt.want != nil && (got == nil || !got.Equal(*tt.want)):
+				t.Errorf("NextDigestAt(%v) = %v, want %v", tt.t, got, tt.want)
+			}
+		})
+	}
+}
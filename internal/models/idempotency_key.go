@@ -0,0 +1,22 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyKey stores the response of a mutation so retries with the same key replay it.
+// Response is nil while the original request is still being processed; that request
+// is the Owner and keeps HeartbeatAt fresh until it finishes.
+type IdempotencyKey struct {
+	UserID      uuid.UUID       `gorm:"type:uuid;primary_key" json:"user_id"`
+	Key         string          `gorm:"size:255;primary_key" json:"key"`
+	RequestHash string          `gorm:"size:64;not null" json:"request_hash"`
+	Response    json.RawMessage `gorm:"type:jsonb" json:"response,omitempty"`
+	Owner       *uuid.UUID      `gorm:"type:uuid" json:"-"`
+	HeartbeatAt time.Time       `json:"heartbeat_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+	ExpiresAt   time.Time       `gorm:"not null;index" json:"expires_at"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DeliveryProvider string
+
+const (
+	DeliveryProviderAPNs DeliveryProvider = "apns"
+	DeliveryProviderFCM  DeliveryProvider = "fcm"
+)
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusSent         DeliveryStatus = "sent"
+	DeliveryStatusFailed       DeliveryStatus = "failed"
+	DeliveryStatusInvalidToken DeliveryStatus = "invalid_token" // Provider rejected the token as permanently invalid
+)
+
+// NotificationDelivery records one attempt to push a reminder to one device
+type NotificationDelivery struct {
+	ID                int64            `gorm:"primaryKey;autoIncrement" json:"id"`
+	ReminderID        uuid.UUID        `gorm:"type:uuid;not null" json:"reminder_id"`
+	UserID            uuid.UUID        `gorm:"type:uuid;not null" json:"user_id"`
+	DeviceID          uuid.UUID        `gorm:"type:uuid;not null" json:"device_id"`
+	Provider          DeliveryProvider `gorm:"type:varchar(10);not null" json:"provider"`
+	ProviderMessageID *string          `gorm:"size:255" json:"provider_message_id,omitempty"` // apns-id or FCM message name
+	Status            DeliveryStatus   `gorm:"type:varchar(20);not null" json:"status"`
+	Error             *string          `json:"error,omitempty"`
+	LatencyMs         int              `gorm:"not null;default:0" json:"latency_ms"`
+	CreatedAt         time.Time        `json:"created_at"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TargetStatus string
+
+const (
+	TargetStatusPending      TargetStatus = "pending"
+	TargetStatusDelivered    TargetStatus = "delivered"
+	TargetStatusDeadLettered TargetStatus = "dead_lettered" // Gave up after a permanent error or too many attempts
+	TargetStatusDeferred     TargetStatus = "deferred"      // Held for the quiet-hours summary due at NextAttemptAt
+)
+
+// NotificationTarget tracks delivery of one occurrence of a reminder to one device
+type NotificationTarget struct {
+	ID            int64        `gorm:"primaryKey;autoIncrement" json:"id"`
+	ReminderID    uuid.UUID    `gorm:"type:uuid;not null" json:"reminder_id"`
+	DeviceID      uuid.UUID    `gorm:"type:uuid;not null" json:"device_id"`
+	DueAt         time.Time    `gorm:"not null" json:"due_at"` // The occurrence being delivered
+	Status        TargetStatus `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	Attempts      int          `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt *time.Time   `json:"next_attempt_at,omitempty"`
+	LastError     *string      `json:"last_error,omitempty"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+
+	// Relations
+	Reminder *Reminder `gorm:"foreignKey:ReminderID" json:"-"`
+}
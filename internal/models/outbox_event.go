@@ -0,0 +1,41 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OutboxKind string
+
+const (
+	OutboxKindReminderChanged     OutboxKind = "reminder_changed"
+	OutboxKindReminderListChanged OutboxKind = "reminder_list_changed"
+	OutboxKindUserChanged         OutboxKind = "user_changed"
+	OutboxKindCrossDeviceAction   OutboxKind = "cross_device_action"
+	OutboxKindDeviceChanged       OutboxKind = "device_changed"
+	OutboxKindReminderNotified    OutboxKind = "reminder_notified"
+)
+
+type OutboxStatus string
+
+const (
+	OutboxStatusPending OutboxStatus = "pending"
+	OutboxStatusDone    OutboxStatus = "done"
+	OutboxStatusDead    OutboxStatus = "dead" // Gave up after too many attempts
+)
+
+// OutboxEvent is a side effect recorded in the same transaction as the change that caused it
+type OutboxEvent struct {
+	ID          int64           `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID      uuid.UUID       `gorm:"type:uuid;not null" json:"user_id"`
+	Kind        OutboxKind      `gorm:"type:varchar(50);not null" json:"kind"`
+	Payload     json.RawMessage `gorm:"type:jsonb;not null" json:"payload"`
+	Status      OutboxStatus    `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	Attempts    int             `gorm:"not null;default:0" json:"attempts"`
+	LastError   *string         `json:"last_error,omitempty"`
+	AvailableAt time.Time       `gorm:"not null" json:"available_at"`
+	ProcessedAt *time.Time      `json:"processed_at,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PubSubMessage is a hub event published through Postgres for other instances to pick up
+type PubSubMessage struct {
+	ID        int64           `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uuid.UUID       `gorm:"type:uuid;not null" json:"user_id"`
+	Topic     string          `gorm:"size:50;not null;default:''" json:"topic"`
+	EventType string          `gorm:"size:100;not null" json:"event_type"`
+	Payload   json.RawMessage `gorm:"type:jsonb;not null" json:"payload"`
+	CreatedAt time.Time       `gorm:"index" json:"created_at"`
+}
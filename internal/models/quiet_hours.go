@@ -0,0 +1,65 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// QuietHours is a daily window during which non-alarm pushes are held back. Start and
+// End are minutes after local midnight; a window whose End is before its Start spans
+// midnight.
+type QuietHours struct {
+	Start int
+	End   int
+}
+
+// newQuietHours returns the window for a pair of nullable columns, or nil if either is unset
+func newQuietHours(start, end *int) *QuietHours {
+	if start == nil || end == nil || *start == *end {
+		return nil
+	}
+	return &QuietHours{Start: *start, End: *end}
+}
+
+// Contains reports whether t, in the location it carries, falls inside the window
+func (q QuietHours) Contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	if q.Start < q.End {
+		return minute >= q.Start && minute < q.End
+	}
+	return minute >= q.Start || minute < q.End
+}
+
+// EndAfter returns the first end of the window after t, in t's location
+func (q QuietHours) EndAfter(t time.Time) time.Time {
+	end := q.endOn(t, 0)
+	if !end.After(t) {
+		end = q.endOn(t, 1)
+	}
+	return end
+}
+
+// endOn returns the end of the window on the day days after t's. When clocks going
+// forward skip the end's time of day, the window ends as they jump.
+func (q QuietHours) endOn(t time.Time, days int) time.Time {
+	end := time.Date(t.Year(), t.Month(), t.Day()+days, q.End/60, q.End%60, 0, 0, t.Location())
+	if end.Hour()*60+end.Minute() != q.End {
+		// time.Date normalized the skipped time to before the jump
+		_, end = end.ZoneBounds()
+	}
+	return end
+}
+
+// ParseTimeOfDay parses an "HH:MM" time of day into minutes after midnight
+func ParseTimeOfDay(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, expected HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// FormatTimeOfDay formats minutes after midnight as "HH:MM"
+func FormatTimeOfDay(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("LoadLocation(%q): %v", name, err)
+	}
+	return loc
+}
+
+func TestQuietHoursContains(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	overnight := QuietHours{Start: 22 * 60, End: 7 * 60}
+	afternoon := QuietHours{Start: 13 * 60, End: 14*60 + 30}
+
+	tests := []struct {
+		name  string
+		q     QuietHours
+		t     time.Time
+		quiet bool
+	}{
+		{"overnight before start", overnight, time.Date(2026, 6, 1, 21, 59, 0, 0, time.UTC), false},
+		{"overnight at start", overnight, time.Date(2026, 6, 1, 22, 0, 0, 0, time.UTC), true},
+		{"overnight before midnight", overnight, time.Date(2026, 6, 1, 23, 59, 0, 0, time.UTC), true},
+		{"overnight at midnight", overnight, time.Date(2026, 6, 2, 0, 0, 0, 0, time.UTC), true},
+		{"overnight before end", overnight, time.Date(2026, 6, 2, 6, 59, 0, 0, time.UTC), true},
+		{"overnight at end", overnight, time.Date(2026, 6, 2, 7, 0, 0, 0, time.UTC), false},
+		{"overnight midday", overnight, time.Date(2026, 6, 2, 12, 0, 0, 0, time.UTC), false},
+		{"same day before start", afternoon, time.Date(2026, 6, 1, 12, 59, 0, 0, time.UTC), false},
+		{"same day inside", afternoon, time.Date(2026, 6, 1, 14, 29, 0, 0, time.UTC), true},
+		{"same day at end", afternoon, time.Date(2026, 6, 1, 14, 30, 0, 0, time.UTC), false},
+		{"same day at midnight", afternoon, time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), false},
+		{"local time of t", overnight, time.Date(2026, 6, 2, 3, 0, 0, 0, time.UTC).In(newYork), true},           // 23:00 EDT
+		{"local time of t outside", overnight, time.Date(2026, 6, 1, 23, 0, 0, 0, time.UTC).In(newYork), false}, // 19:00 EDT
+		{"after spring forward", overnight, time.Date(2026, 3, 8, 3, 0, 0, 0, newYork), true},
+		{"repeated hour, first time", overnight, time.Date(2026, 11, 1, 5, 30, 0, 0, time.UTC).In(newYork), true},  // 01:30 EDT
+		{"repeated hour, second time", overnight, time.Date(2026, 11, 1, 6, 30, 0, 0, time.UTC).In(newYork), true}, // 01:30 EST
+		{"end on fall back day", overnight, time.Date(2026, 11, 1, 7, 0, 0, 0, newYork), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.q.Contains(tt.t); got != tt.quiet {
+				t.Errorf("%+v.Contains(%v) = %t, want %t", tt.q, tt.t, got, tt.quiet)
+			}
+		})
+	}
+}
+
+func TestQuietHoursEndAfter(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	overnight := QuietHours{Start: 22 * 60, End: 7 * 60}
+
+	tests := []struct {
+		name string
+		q    QuietHours
+		t    time.Time
+		want time.Time
+	}{
+		{"before midnight", overnight, time.Date(2026, 6, 1, 23, 0, 0, 0, time.UTC), time.Date(2026, 6, 2, 7, 0, 0, 0, time.UTC)},
+		{"after midnight", overnight, time.Date(2026, 6, 2, 1, 0, 0, 0, time.UTC), time.Date(2026, 6, 2, 7, 0, 0, 0, time.UTC)},
+		{"at end", overnight, time.Date(2026, 6, 2, 7, 0, 0, 0, time.UTC), time.Date(2026, 6, 3, 7, 0, 0, 0, time.UTC)},
+		{"same day window", QuietHours{Start: 13 * 60, End: 14*60 + 30}, time.Date(2026, 6, 1, 13, 15, 0, 0, time.UTC), time.Date(2026, 6, 1, 14, 30, 0, 0, time.UTC)},
+		{"end of month", overnight, time.Date(2026, 6, 30, 23, 0, 0, 0, time.UTC), time.Date(2026, 7, 1, 7, 0, 0, 0, time.UTC)},
+		{"in t's location", overnight, time.Date(2026, 6, 2, 3, 0, 0, 0, time.UTC).In(newYork), time.Date(2026, 6, 2, 7, 0, 0, 0, newYork)},
+		{"across spring forward", overnight, time.Date(2026, 3, 7, 23, 0, 0, 0, newYork), time.Date(2026, 3, 8, 7, 0, 0, 0, newYork)},
+		{"across fall back", overnight, time.Date(2026, 10, 31, 23, 0, 0, 0, newYork), time.Date(2026, 11, 1, 7, 0, 0, 0, newYork)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.q.EndAfter(tt.t)
+			if !got.Equal(tt.want) || got.Location() != tt.t.Location() {
+				t.Errorf("%+v.EndAfter(%v) = %v, want %v", tt.q, tt.t, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuietHoursEndAfterDSTLength(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	overnight := QuietHours{Start: 22 * 60, End: 7 * 60}
+
+	// The window ends at 07:00 local time however long the night is
+	tests := []struct {
+		name  string
+		start time.Time
+		want  time.Duration
+	}{
+		{"spring forward", time.Date(2026, 3, 7, 22, 0, 0, 0, newYork), 8 * time.Hour},
+		{"fall back", time.Date(2026, 10, 31, 22, 0, 0, 0, newYork), 10 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := overnight.EndAfter(tt.start).Sub(tt.start); got != tt.want {
+				t.Errorf("quiet hours from %v last %v, want %v", tt.start, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuietHoursEndInSkippedHour(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	q := QuietHours{Start: 22 * 60, End: 2*60 + 30}     // 02:30 doesn't exist on 2026-03-08
+	jump := time.Date(2026, 3, 8, 7, 0, 0, 0, time.UTC) // 02:00 EST becomes 03:00 EDT
+
+	for _, from := range []time.Time{
+		time.Date(2026, 3, 7, 23, 0, 0, 0, newYork),
+		time.Date(2026, 3, 8, 1, 45, 0, 0, newYork),
+	} {
+		end := q.EndAfter(from)
+		if !end.Equal(jump) {
+			t.Errorf("EndAfter(%v) = %v, want %v as clocks go forward", from, end, jump.In(newYork))
+		}
+		if q.Contains(end) || !q.Contains(end.Add(-time.Minute)) {
+			t.Errorf("window from %v doesn't end at %v", from, end)
+		}
+	}
+}
@@ -52,6 +52,7 @@ type SyncEvent struct {
 	Action     SyncAction  `gorm:"type:varchar(20);not null" json:"action"`
 	Payload    SyncPayload `gorm:"type:jsonb" json:"payload,omitempty"`
 	DeviceID   *uuid.UUID  `gorm:"type:uuid" json:"device_id,omitempty"`
+	Seq        int64       `gorm:"autoIncrement;uniqueIndex:idx_sync_events_seq" json:"seq"` // Monotonic cursor assigned by the database
 	CreatedAt  time.Time   `gorm:"index" json:"created_at"`
 
 	// Relations
@@ -66,6 +67,14 @@ func (se *SyncEvent) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// SyncWatermark records the highest sync event sequence removed by retention for a user.
+// A client whose cursor is below PrunedThroughSeq has missed events and must resync.
+type SyncWatermark struct {
+	UserID           uuid.UUID `gorm:"type:uuid;primary_key" json:"user_id"`
+	PrunedThroughSeq int64     `gorm:"not null;default:0" json:"pruned_through_seq"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
 // CreateSyncEvent creates a new sync event for tracking changes
 func CreateSyncEvent(userID uuid.UUID, entityType EntityType, entityID uuid.UUID, action SyncAction, payload interface{}, deviceID *uuid.UUID) *SyncEvent {
 	var syncPayload SyncPayload
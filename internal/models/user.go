@@ -8,18 +8,26 @@ import (
 )
 
 type User struct {
-	ID           uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	GoogleID     *string        `gorm:"uniqueIndex" json:"-"`
-	AppleID      *string        `gorm:"uniqueIndex" json:"-"`
-	Email        string         `gorm:"uniqueIndex;not null" json:"email"`
-	DisplayName  string         `gorm:"size:255" json:"display_name"`
-	AvatarURL    string         `json:"avatar_url,omitempty"`
-	Timezone     string         `gorm:"default:'UTC'" json:"timezone"`
-	IsPremium    bool           `gorm:"default:false" json:"is_premium"`
-	PremiumUntil *time.Time     `json:"premium_until,omitempty"`
-	CreatedAt    time.Time      `json:"created_at"`
-	UpdatedAt    time.Time      `json:"updated_at"`
-	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	GoogleID     *string    `gorm:"uniqueIndex" json:"-"`
+	AppleID      *string    `gorm:"uniqueIndex" json:"-"`
+	Email        string     `gorm:"uniqueIndex;not null" json:"email"`
+	DisplayName  string     `gorm:"size:255" json:"display_name"`
+	AvatarURL    string     `json:"avatar_url,omitempty"`
+	Timezone     string     `gorm:"default:'UTC'" json:"timezone"`
+	IsPremium    bool       `gorm:"default:false" json:"is_premium"`
+	PremiumUntil *time.Time `json:"premium_until,omitempty"`
+	// Quiet hours in minutes after local midnight; see QuietHours
+	QuietHoursStart *int `gorm:"type:smallint" json:"quiet_hours_start,omitempty"`
+	QuietHoursEnd   *int `gorm:"type:smallint" json:"quiet_hours_end,omitempty"`
+	// Daily digest preferences; see NextDigestAt
+	DigestEnabled bool           `gorm:"not null;default:false" json:"digest_enabled"`
+	DigestTime    int            `gorm:"type:smallint;not null;default:480" json:"digest_time"` // Minutes after local midnight
+	DigestDays    int            `gorm:"type:smallint;not null;default:127" json:"digest_days"` // Bitmask of time.Weekday
+	DigestListIDs StringArray    `gorm:"type:text[];default:'{}'" json:"digest_list_ids"`       // Empty for every list
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// Relations
 	Devices   []Device   `gorm:"foreignKey:UserID" json:"-"`
@@ -42,3 +50,31 @@ func (u *User) HasActivePremium() bool {
 	}
 	return u.PremiumUntil.After(time.Now())
 }
+
+// Location returns the user's timezone, falling back to UTC if it isn't a known zone
+func (u *User) Location() *time.Location {
+	if loc, err := time.LoadLocation(u.Timezone); err == nil && u.Timezone != "" {
+		return loc
+	}
+	return time.UTC
+}
+
+// EndOfDay returns the midnight that ends t's day in the user's timezone
+func (u *User) EndOfDay(t time.Time) time.Time {
+	local := t.In(u.Location())
+	return time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, local.Location())
+}
+
+// QuietHours returns the user's quiet hours, or nil if they have none
+func (u *User) QuietHours() *QuietHours {
+	return newQuietHours(u.QuietHoursStart, u.QuietHoursEnd)
+}
+
+// QuietHoursFor returns the quiet hours that apply to device: its own if it overrides
+// the user's, otherwise the user's. Nil means pushes to the device are never held back.
+func (u *User) QuietHoursFor(device *Device) *QuietHours {
+	if device.QuietHoursOverride {
+		return device.QuietHours()
+	}
+	return u.QuietHours()
+}
@@ -0,0 +1,62 @@
+package models
+
+import "testing"
+
+func TestUserQuietHoursFor(t *testing.T) {
+	ptr := func(v int) *int { return &v }
+	userHours := &QuietHours{Start: 22 * 60, End: 7 * 60}
+	deviceHours := &QuietHours{Start: 23 * 60, End: 6 * 60}
+
+	tests := []struct {
+		name   string
+		user   User
+		device Device
+		want   *QuietHours
+	}{
+		{name: "none set"},
+		{
+			name: "user's",
+			user: User{QuietHoursStart: ptr(userHours.Start), QuietHoursEnd: ptr(userHours.End)},
+			want: userHours,
+		},
+		{
+			name:   "device hours ignored without override",
+			user:   User{QuietHoursStart: ptr(userHours.Start), QuietHoursEnd: ptr(userHours.End)},
+			device: Device{QuietHoursStart: ptr(deviceHours.Start), QuietHoursEnd: ptr(deviceHours.End)},
+			want:   userHours,
+		},
+		{
+			name:   "device override",
+			user:   User{QuietHoursStart: ptr(userHours.Start), QuietHoursEnd: ptr(userHours.End)},
+			device: Device{QuietHoursOverride: true, QuietHoursStart: ptr(deviceHours.Start), QuietHoursEnd: ptr(deviceHours.End)},
+			want:   deviceHours,
+		},
+		{
+			name:   "device override without user hours",
+			device: Device{QuietHoursOverride: true, QuietHoursStart: ptr(deviceHours.Start), QuietHoursEnd: ptr(deviceHours.End)},
+			want:   deviceHours,
+		},
+		{
+			name:   "device override turning quiet hours off",
+			user:   User{QuietHoursStart: ptr(userHours.Start), QuietHoursEnd: ptr(userHours.End)},
+			device: Device{QuietHoursOverride: true},
+		},
+		{
+			name: "user hours half set",
+			user: User{QuietHoursStart: ptr(userHours.Start)},
+		},
+		{
+			name: "empty user window",
+			user: User{QuietHoursStart: ptr(userHours.Start), QuietHoursEnd: ptr(userHours.Start)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.user.QuietHoursFor(&tt.device)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("QuietHoursFor = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"encoding/json"
 	"encoding/pem"
 	"fmt"
-	"io"
 	"net/http"
 	"sync"
 	"time"
@@ -42,6 +41,7 @@ type Client struct {
 	bundleID    string
 	privateKey  *ecdsa.PrivateKey
 	isProduction bool
+	dryRun       bool
 
 	// JWT token caching
 	tokenMu    sync.RWMutex
@@ -56,13 +56,19 @@ type Config struct {
 	PrivateKey   string
 	BundleID     string
 	IsProduction bool
+	// DryRun logs each request instead of sending it; credentials are optional
+	DryRun bool
 }
 
 // NewClient creates a new APNs client
 func NewClient(config Config) (*Client, error) {
-	privateKey, err := parsePrivateKey(config.PrivateKey)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	var privateKey *ecdsa.PrivateKey
+	if !config.DryRun || config.PrivateKey != "" {
+		var err error
+		privateKey, err = parsePrivateKey(config.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key: %w", err)
+		}
 	}
 
 	return &Client{
@@ -74,28 +80,33 @@ func NewClient(config Config) (*Client, error) {
 		bundleID:     config.BundleID,
 		privateKey:   privateKey,
 		isProduction: config.IsProduction,
+		dryRun:       config.DryRun,
 	}, nil
 }
 
-// Send sends a notification to a device
-func (c *Client) Send(ctx context.Context, notification Notification) error {
+// Send sends a notification to a device and returns the apns-id APNs assigned to it
+func (c *Client) Send(ctx context.Context, notification Notification) (string, error) {
 	payload := c.buildPayload(notification)
 
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
-		return fmt.Errorf("failed to marshal payload: %w", err)
+		return "", fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	if c.dryRun {
+		return c.logDryRun(notification.DeviceToken, "alert", "10", payloadBytes), nil
 	}
 
 	url := c.getURL() + "/3/device/" + notification.DeviceToken
 
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(payloadBytes))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
 	token, err := c.getToken()
 	if err != nil {
-		return fmt.Errorf("failed to get auth token: %w", err)
+		return "", fmt.Errorf("failed to get auth token: %w", err)
 	}
 
 	req.Header.Set("Authorization", "bearer "+token)
@@ -106,29 +117,39 @@ func (c *Client) Send(ctx context.Context, notification Notification) error {
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send notification: %w", err)
+		return "", fmt.Errorf("failed to send notification: %w", err)
 	}
 	defer resp.Body.Close()
 
+	apnsID := resp.Header.Get("apns-id")
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("APNs error: %s - %s", resp.Status, string(body))
+		return apnsID, parseError(resp)
 	}
 
-	return nil
+	return apnsID, nil
 }
 
 // SendSilent sends a silent/background notification
-func (c *Client) SendSilent(ctx context.Context, deviceToken string) error {
+func (c *Client) SendSilent(ctx context.Context, deviceToken string) (string, error) {
 	return c.SendData(ctx, deviceToken, map[string]string{"type": "sync"})
 }
 
 // SendData sends a silent/background notification with custom data
-func (c *Client) SendData(ctx context.Context, deviceToken string, data map[string]string) error {
+func (c *Client) SendData(ctx context.Context, deviceToken string, data map[string]string) (string, error) {
+	return c.SendBackground(ctx, deviceToken, nil, data)
+}
+
+// SendBackground sends a silent/background notification with custom data, setting the
+// app icon badge when badge is not nil
+func (c *Client) SendBackground(ctx context.Context, deviceToken string, badge *int, data map[string]string) (string, error) {
+	aps := map[string]interface{}{
+		"content-available": 1,
+	}
+	if badge != nil {
+		aps["badge"] = *badge
+	}
 	payload := map[string]interface{}{
-		"aps": map[string]interface{}{
-			"content-available": 1,
-		},
+		"aps": aps,
 	}
 
 	// Add custom data to payload
@@ -138,46 +159,50 @@ func (c *Client) SendData(ctx context.Context, deviceToken string, data map[stri
 
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
-		return fmt.Errorf("failed to marshal payload: %w", err)
+		return "", fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	// Use high priority for cross-device actions to ensure quick delivery
+	// so notifications/alarms are dismissed promptly on all devices
+	priority := "5"
+	if data["type"] == "cross_device_action" {
+		priority = "10"
+	}
+
+	if c.dryRun {
+		return c.logDryRun(deviceToken, "background", priority, payloadBytes), nil
 	}
 
 	url := c.getURL() + "/3/device/" + deviceToken
 
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(payloadBytes))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
 	token, err := c.getToken()
 	if err != nil {
-		return fmt.Errorf("failed to get auth token: %w", err)
+		return "", fmt.Errorf("failed to get auth token: %w", err)
 	}
 
 	req.Header.Set("Authorization", "bearer "+token)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("apns-topic", c.bundleID)
 	req.Header.Set("apns-push-type", "background")
-
-	// Use high priority for cross-device actions to ensure quick delivery
-	// so notifications/alarms are dismissed promptly on all devices
-	priority := "5"
-	if data["type"] == "cross_device_action" {
-		priority = "10"
-	}
 	req.Header.Set("apns-priority", priority)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send notification: %w", err)
+		return "", fmt.Errorf("failed to send notification: %w", err)
 	}
 	defer resp.Body.Close()
 
+	apnsID := resp.Header.Get("apns-id")
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("APNs error: %s - %s", resp.Status, string(body))
+		return apnsID, parseError(resp)
 	}
 
-	return nil
+	return apnsID, nil
 }
 
 func (c *Client) buildPayload(notification Notification) map[string]interface{} {
@@ -0,0 +1,14 @@
+package apns
+
+import (
+	"log"
+
+	"github.com/google/uuid"
+)
+
+// logDryRun logs the request a dry-run client would have sent and returns a placeholder apns-id
+func (c *Client) logDryRun(deviceToken, pushType, priority string, payload []byte) string {
+	log.Printf("[APNs dry run] POST %s/3/device/%s apns-topic=%s apns-push-type=%s apns-priority=%s %s",
+		c.getURL(), deviceToken, c.bundleID, pushType, priority, payload)
+	return "dry-run-" + uuid.NewString()
+}
@@ -0,0 +1,84 @@
+package apns
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/user/remind-me/backend/internal/notification/retryafter"
+)
+
+// Reasons APNs gives for rejecting a device token. See
+// https://developer.apple.com/documentation/usernotifications/handling-notification-responses-from-apns
+const (
+	ReasonBadDeviceToken         = "BadDeviceToken"
+	ReasonDeviceTokenNotForTopic = "DeviceTokenNotForTopic"
+	ReasonUnregistered           = "Unregistered"
+)
+
+// Error is a notification rejected by APNs
+type Error struct {
+	StatusCode int
+	Reason     string
+	// Timestamp is when APNs last confirmed the token was no longer valid (410 only)
+	Timestamp *time.Time
+	// RetryAfter is the wait requested by a Retry-After header, zero if none was sent
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("APNs error: %d %s", e.StatusCode, e.Reason)
+}
+
+// InvalidToken reports whether the device token will never be accepted again,
+// so the registration should be removed rather than retried
+func (e *Error) InvalidToken() bool {
+	switch e.Reason {
+	case ReasonBadDeviceToken, ReasonDeviceTokenNotForTopic, ReasonUnregistered:
+		return true
+	}
+	return e.StatusCode == http.StatusGone
+}
+
+// InvalidSince returns when APNs last confirmed the token was invalid, or nil if it
+// did not say
+func (e *Error) InvalidSince() *time.Time {
+	return e.Timestamp
+}
+
+// Temporary reports whether the notification may be accepted if sent again later
+// (TooManyRequests or an APNs server error)
+func (e *Error) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+// RetryDelay returns how long APNs asked us to wait before retrying
+func (e *Error) RetryDelay() time.Duration {
+	return e.RetryAfter
+}
+
+// parseError reads the error body of a non-200 APNs response
+func parseError(resp *http.Response) error {
+	var body struct {
+		Reason    string `json:"reason"`
+		Timestamp int64  `json:"timestamp"`
+	}
+	raw, _ := io.ReadAll(resp.Body)
+	_ = json.Unmarshal(raw, &body)
+
+	apnsErr := &Error{
+		StatusCode: resp.StatusCode,
+		Reason:     body.Reason,
+		RetryAfter: retryafter.Parse(resp.Header.Get("Retry-After")),
+	}
+	if apnsErr.Reason == "" {
+		apnsErr.Reason = string(raw)
+	}
+	if body.Timestamp > 0 {
+		ts := time.UnixMilli(body.Timestamp)
+		apnsErr.Timestamp = &ts
+	}
+	return apnsErr
+}
@@ -2,13 +2,14 @@ package notification
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
-	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/user/remind-me/backend/internal/models"
-	"github.com/user/remind-me/backend/internal/notification/apns"
-	"github.com/user/remind-me/backend/internal/notification/fcm"
 	"github.com/user/remind-me/backend/internal/repository"
 )
 
@@ -19,7 +20,7 @@ type Payload struct {
 	Sound      string            `json:"sound,omitempty"`
 	Badge      *int              `json:"badge,omitempty"`
 	Category   string            `json:"category,omitempty"`
-	ReminderID uuid.UUID         `json:"reminder_id"`
+	ReminderID uuid.UUID         `json:"reminder_id"` // Nil when not about one reminder, e.g. digests
 	DueAt      string            `json:"due_at,omitempty"`
 	Data       map[string]string `json:"data,omitempty"`
 	IsAlarm    bool              `json:"is_alarm,omitempty"`
@@ -35,68 +36,162 @@ const (
 	ActionDelete   CrossDeviceAction = "delete"
 )
 
+// DeviceStore looks up the devices pushes are sent to
+type DeviceStore interface {
+	FindByID(id uuid.UUID) (*models.Device, error)
+	ListByUser(userID uuid.UUID) ([]models.Device, error)
+	GetAllPushTokens(userID uuid.UUID) ([]repository.PushToken, error)
+	GetPushTokensExcluding(userID uuid.UUID, excludeDeviceID uuid.UUID) ([]repository.PushToken, error)
+}
+
+// DeliveryLog records every push attempt
+type DeliveryLog interface {
+	Create(delivery *models.NotificationDelivery) error
+}
+
+// TargetStore keeps the per-device delivery state of reminder notifications
+type TargetStore interface {
+	ListByOccurrence(reminderID uuid.UUID, dueAt time.Time) ([]models.NotificationTarget, error)
+	ClaimDeferred(now time.Time, limit int, lease time.Duration) ([]models.NotificationTarget, error)
+	Save(target *models.NotificationTarget) error
+}
+
+// TokenPruner removes the device registrations using a push token that a
+// provider has permanently rejected. When the provider said since when the token has
+// been invalid, registrations updated after that are kept, since the app registered
+// the token again. It reports whether any registration was removed.
+type TokenPruner interface {
+	PruneInvalidToken(pushToken string, invalidSince *time.Time) (bool, error)
+}
+
+// BadgeCounter counts the reminders shown on the app icon badge of a user's devices
+type BadgeCounter interface {
+	BadgeCount(userID uuid.UUID) (int, error)
+}
+
+// Config sets the dispatcher's concurrency and provider rate limits
+type Config struct {
+	Workers       int // Sends in flight at once across all callers
+	APNsPerSecond int // iOS sends per second; zero for no limit
+	FCMPerSecond  int // Android sends per second; zero for no limit
+}
+
+// defaultWorkers is used when Config.Workers is not set
+const defaultWorkers = 50
+
+// platformSender is the provider serving one platform, with its rate limit and counters
+type platformSender struct {
+	provider PushProvider
+	limiter  *tokenBucket
+	counters providerCounters
+}
+
 // Dispatcher handles sending notifications to multiple platforms
 type Dispatcher struct {
-	apnsClient *apns.Client
-	fcmClient  *fcm.Client
-	deviceRepo *repository.DeviceRepository
+	senders      map[models.Platform]*platformSender
+	deviceRepo   DeviceStore
+	deliveryRepo DeliveryLog
+	targetRepo   TargetStore
+	pruner       TokenPruner
+	badges       BadgeCounter
+
+	slots    chan struct{} // Worker pool; one entry per send in flight
+	started  time.Time
+	inFlight atomic.Int64
+	queued   atomic.Int64
 }
 
-// NewDispatcher creates a new notification dispatcher
+// NewDispatcher creates a new notification dispatcher. Either provider may be nil if
+// that platform is not configured. Without badges, pushes leave the badge unchanged.
 func NewDispatcher(
-	apnsClient *apns.Client,
-	fcmClient *fcm.Client,
-	deviceRepo *repository.DeviceRepository,
+	iosProvider PushProvider,
+	androidProvider PushProvider,
+	deviceRepo DeviceStore,
+	deliveryRepo DeliveryLog,
+	targetRepo TargetStore,
+	pruner TokenPruner,
+	badges BadgeCounter,
+	config Config,
 ) *Dispatcher {
-	return &Dispatcher{
-		apnsClient: apnsClient,
-		fcmClient:  fcmClient,
-		deviceRepo: deviceRepo,
+	workers := config.Workers
+	if workers <= 0 {
+		workers = defaultWorkers
 	}
-}
 
-// SendToUser sends a notification to all devices of a user
-func (d *Dispatcher) SendToUser(ctx context.Context, userID uuid.UUID, payload Payload) error {
-	tokens, err := d.deviceRepo.GetAllPushTokens(userID)
-	if err != nil {
-		return err
+	senders := make(map[models.Platform]*platformSender)
+	if iosProvider != nil {
+		senders[models.PlatformIOS] = &platformSender{provider: iosProvider, limiter: newTokenBucket(config.APNsPerSecond)}
+	}
+	if androidProvider != nil {
+		senders[models.PlatformAndroid] = &platformSender{provider: androidProvider, limiter: newTokenBucket(config.FCMPerSecond)}
 	}
 
-	var wg sync.WaitGroup
-	errors := make(chan error, len(tokens))
+	return &Dispatcher{
+		senders:      senders,
+		deviceRepo:   deviceRepo,
+		deliveryRepo: deliveryRepo,
+		targetRepo:   targetRepo,
+		pruner:       pruner,
+		badges:       badges,
+		slots:        make(chan struct{}, workers),
+		started:      time.Now(),
+	}
+}
 
-	for _, token := range tokens {
-		wg.Add(1)
-		go func(platform models.Platform, pushToken string) {
-			defer wg.Done()
+// IsInvalidToken reports whether err is a provider error for a device token that
+// will never be accepted again
+func IsInvalidToken(err error) bool {
+	var tokenErr interface{ InvalidToken() bool }
+	return errors.As(err, &tokenErr) && tokenErr.InvalidToken()
+}
 
-			var sendErr error
-			switch platform {
-			case models.PlatformIOS:
-				sendErr = d.sendToIOS(ctx, pushToken, payload)
-			case models.PlatformAndroid:
-				sendErr = d.sendToAndroid(ctx, pushToken, payload)
-			}
+// tokenInvalidSince returns when the provider says the token in err became invalid, or
+// nil if it did not say
+func tokenInvalidSince(err error) *time.Time {
+	var tokenErr interface{ InvalidSince() *time.Time }
+	if errors.As(err, &tokenErr) {
+		return tokenErr.InvalidSince()
+	}
+	return nil
+}
 
-			if sendErr != nil {
-				errors <- sendErr
-				log.Printf("Failed to send notification to %s device: %v", platform, sendErr)
-			}
-		}(token.Platform, token.PushToken)
+// handleSendError prunes the device registration when err says its token is permanently
+// invalid. A pruned token is not reported as a failure since retrying it can't succeed.
+func (d *Dispatcher) handleSendError(platform models.Platform, pushToken string, err error) error {
+	if err == nil || d.pruner == nil || !IsInvalidToken(err) {
+		return err
 	}
 
-	wg.Wait()
-	close(errors)
+	pruned, pruneErr := d.pruner.PruneInvalidToken(pushToken, tokenInvalidSince(err))
+	if pruneErr != nil {
+		log.Printf("Failed to prune %s device with invalid push token: %v", platform, pruneErr)
+		return err
+	}
+	if !pruned {
+		// Registered again after the provider saw the token become invalid
+		return err
+	}
+	log.Printf("Pruned %s device with invalid push token: %v", platform, err)
+	return nil
+}
 
-	// Collect errors (we don't fail the whole operation if some devices fail)
-	var firstErr error
-	for err := range errors {
-		if firstErr == nil {
-			firstErr = err
-		}
+// badgeCount returns the user's current badge count, or nil if it can't be counted
+func (d *Dispatcher) badgeCount(userID uuid.UUID) *int {
+	if d.badges == nil {
+		return nil
+	}
+	count, err := d.badges.BadgeCount(userID)
+	if err != nil {
+		log.Printf("Failed to count badge for user %s: %v", userID, err)
+		return nil
 	}
+	return &count
+}
 
-	return firstErr
+// SendToUser sends a notification to all devices of a user.
+// Each attempt is recorded in the delivery log.
+func (d *Dispatcher) SendToUser(ctx context.Context, userID uuid.UUID, payload Payload) error {
+	return d.SendToUserExcluding(ctx, userID, nil, payload)
 }
 
 // SendToDevice sends a notification to a specific device
@@ -106,75 +201,117 @@ func (d *Dispatcher) SendToDevice(ctx context.Context, deviceID uuid.UUID, paylo
 		return err
 	}
 
-	switch device.Platform {
-	case models.PlatformIOS:
-		return d.sendToIOS(ctx, device.PushToken, payload)
-	case models.PlatformAndroid:
-		return d.sendToAndroid(ctx, device.PushToken, payload)
+	return d.deliver(ctx, *device, payload)
+}
+
+// deliver pushes payload to one device and records the attempt, pruning the device if
+// its token has been permanently rejected
+func (d *Dispatcher) deliver(ctx context.Context, device models.Device, payload Payload) error {
+	return d.handleSendError(device.Platform, device.PushToken, d.attempt(ctx, device, payload))
+}
+
+// attempt pushes payload to one device and records the attempt. The provider's error
+// is returned as is.
+func (d *Dispatcher) attempt(ctx context.Context, device models.Device, payload Payload) error {
+	sender, ok := d.senders[device.Platform]
+	if !ok {
+		return fmt.Errorf("%w for %s", ErrNoProvider, device.Platform)
 	}
 
-	return nil
+	start := time.Now()
+	messageID, sendErr := d.send(ctx, sender, func() (string, error) {
+		return sender.provider.SendAlert(ctx, device.PushToken, payload)
+	})
+
+	d.recordDelivery(device, payload, sender.provider.Name(), messageID, time.Since(start), sendErr)
+	return sendErr
 }
 
-// SendToUserExcluding sends a notification to all devices of a user except the specified device
-func (d *Dispatcher) SendToUserExcluding(ctx context.Context, userID uuid.UUID, excludeDeviceID *uuid.UUID, payload Payload) error {
-	var tokens []struct {
-		Platform  models.Platform
-		PushToken string
+// recordDelivery writes a delivery log entry for reminder notifications.
+// Failing to record is logged but never fails the send.
+func (d *Dispatcher) recordDelivery(device models.Device, payload Payload, provider models.DeliveryProvider, messageID string, latency time.Duration, sendErr error) {
+	if d.deliveryRepo == nil || payload.ReminderID == uuid.Nil {
+		return
 	}
-	var err error
 
-	if excludeDeviceID != nil {
-		tokens, err = d.deviceRepo.GetPushTokensExcluding(userID, *excludeDeviceID)
-	} else {
-		tokens, err = d.deviceRepo.GetAllPushTokens(userID)
+	delivery := &models.NotificationDelivery{
+		ReminderID: payload.ReminderID,
+		UserID:     device.UserID,
+		DeviceID:   device.ID,
+		Provider:   provider,
+		Status:     models.DeliveryStatusSent,
+		LatencyMs:  int(latency.Milliseconds()),
 	}
-	if err != nil {
-		return err
+	if messageID != "" {
+		delivery.ProviderMessageID = &messageID
+	}
+	if sendErr != nil {
+		delivery.Status = models.DeliveryStatusFailed
+		if IsInvalidToken(sendErr) {
+			delivery.Status = models.DeliveryStatusInvalidToken
+		}
+		errText := sendErr.Error()
+		delivery.Error = &errText
 	}
 
-	var wg sync.WaitGroup
-	errors := make(chan error, len(tokens))
+	if err := d.deliveryRepo.Create(delivery); err != nil {
+		log.Printf("Failed to record notification delivery for reminder %s: %v", payload.ReminderID, err)
+	}
+}
 
-	for _, token := range tokens {
-		wg.Add(1)
-		go func(platform models.Platform, pushToken string) {
-			defer wg.Done()
+// SendToUserExcluding sends a notification to all devices of a user except the specified
+// device. The user's badge count is added unless the payload sets one.
+func (d *Dispatcher) SendToUserExcluding(ctx context.Context, userID uuid.UUID, excludeDeviceID *uuid.UUID, payload Payload) error {
+	devices, err := d.deviceRepo.ListByUser(userID)
+	if err != nil {
+		return err
+	}
 
-			var sendErr error
-			switch platform {
-			case models.PlatformIOS:
-				sendErr = d.sendToIOS(ctx, pushToken, payload)
-			case models.PlatformAndroid:
-				sendErr = d.sendToAndroid(ctx, pushToken, payload)
-			}
+	recipients := devices[:0]
+	for _, device := range devices {
+		if excludeDeviceID == nil || device.ID != *excludeDeviceID {
+			recipients = append(recipients, device)
+		}
+	}
+	return d.sendToDevices(ctx, userID, recipients, payload)
+}
 
-			if sendErr != nil {
-				errors <- sendErr
-				log.Printf("Failed to send notification to %s device: %v", platform, sendErr)
-			}
-		}(token.Platform, token.PushToken)
+// sendToDevices sends payload to the user's given devices. The user's badge count is
+// added unless the payload sets one.
+func (d *Dispatcher) sendToDevices(ctx context.Context, userID uuid.UUID, devices []models.Device, payload Payload) error {
+	if payload.Badge == nil {
+		payload.Badge = d.badgeCount(userID)
 	}
 
-	wg.Wait()
-	close(errors)
+	group := d.newSendGroup()
 
-	var firstErr error
-	for err := range errors {
-		if firstErr == nil {
-			firstErr = err
+	// A push token can be registered more than once (e.g., after device_identifier
+	// changes); send to each token only once to avoid duplicate notifications
+	seen := make(map[string]bool, len(devices))
+	for _, device := range devices {
+		if seen[device.PushToken] {
+			continue
 		}
+		seen[device.PushToken] = true
+
+		group.Go(ctx, func() error {
+			sendErr := d.deliver(ctx, device, payload)
+			if sendErr != nil {
+				log.Printf("Failed to send notification to %s device: %v", device.Platform, sendErr)
+			}
+			return sendErr
+		})
 	}
 
-	return firstErr
+	// We don't fail the whole operation if some devices fail; report the first failure
+	return group.Wait()
 }
 
-// SendCrossDeviceAction sends a silent notification to propagate an action to other devices
+// SendCrossDeviceAction sends a silent notification to propagate an action to other
+// devices. It also carries the user's badge count, since completing, deleting or
+// snoozing a reminder changes it.
 func (d *Dispatcher) SendCrossDeviceAction(ctx context.Context, userID uuid.UUID, excludeDeviceID *uuid.UUID, reminderID uuid.UUID, action CrossDeviceAction) error {
-	var tokens []struct {
-		Platform  models.Platform
-		PushToken string
-	}
+	var tokens []repository.PushToken
 	var err error
 
 	if excludeDeviceID != nil {
@@ -191,28 +328,32 @@ func (d *Dispatcher) SendCrossDeviceAction(ctx context.Context, userID uuid.UUID
 		"action":      string(action),
 		"reminder_id": reminderID.String(),
 	}
+	badge := d.badgeCount(userID)
 
-	var wg sync.WaitGroup
+	group := d.newSendGroup()
 	for _, token := range tokens {
-		wg.Add(1)
-		go func(platform models.Platform, pushToken string) {
-			defer wg.Done()
-
-			switch platform {
-			case models.PlatformIOS:
-				if d.apnsClient != nil {
-					_ = d.apnsClient.SendData(ctx, pushToken, data)
-				}
-			case models.PlatformAndroid:
-				if d.fcmClient != nil {
-					_ = d.fcmClient.SendData(ctx, pushToken, data)
-				}
+		platform, pushToken := token.Platform, token.PushToken
+		group.Go(ctx, func() error {
+			var sendErr error
+			if sender, ok := d.senders[platform]; ok {
+				_, sendErr = d.send(ctx, sender, func() (string, error) {
+					if badge != nil {
+						return sender.provider.SendBadge(ctx, pushToken, *badge, data)
+					}
+					return sender.provider.SendData(ctx, pushToken, data)
+				})
+			}
+
+			sendErr = d.handleSendError(platform, pushToken, sendErr)
+			if sendErr != nil {
+				log.Printf("Failed to send cross-device action to %s device: %v", platform, sendErr)
 			}
-		}(token.Platform, token.PushToken)
+			return sendErr
+		})
 	}
 
-	wg.Wait()
-	return nil
+	// Report the first failure so the caller can retry
+	return group.Wait()
 }
 
 // SendSyncNotification sends a silent sync notification
@@ -222,64 +363,45 @@ func (d *Dispatcher) SendSyncNotification(ctx context.Context, userID uuid.UUID,
 		return err
 	}
 
-	var wg sync.WaitGroup
+	group := d.newSendGroup()
 	for _, token := range tokens {
-		wg.Add(1)
-		go func(platform models.Platform, pushToken string) {
-			defer wg.Done()
-
-			switch platform {
-			case models.PlatformIOS:
-				_ = d.apnsClient.SendSilent(ctx, pushToken)
-			case models.PlatformAndroid:
-				_ = d.fcmClient.SendData(ctx, pushToken, map[string]string{
-					"type": "sync",
+		platform, pushToken := token.Platform, token.PushToken
+		group.Go(ctx, func() error {
+			var sendErr error
+			if sender, ok := d.senders[platform]; ok {
+				_, sendErr = d.send(ctx, sender, func() (string, error) {
+					return sender.provider.SendData(ctx, pushToken, map[string]string{
+						"type": "sync",
+					})
 				})
 			}
-		}(token.Platform, token.PushToken)
+			_ = d.handleSendError(platform, pushToken, sendErr)
+			return nil
+		})
 	}
 
-	wg.Wait()
+	_ = group.Wait()
 	return nil
 }
 
-func (d *Dispatcher) sendToIOS(ctx context.Context, token string, payload Payload) error {
-	// Build base Data map
-	data := map[string]interface{}{
-		"reminder_id": payload.ReminderID.String(),
-		"due_at":      payload.DueAt,
-	}
-
-	// Merge payload.Data (contains sound_id, is_alarm, type)
-	for k, v := range payload.Data {
-		data[k] = v
-	}
-
-	notification := apns.Notification{
-		DeviceToken: token,
-		Title:       payload.Title,
-		Body:        payload.Body,
-		Sound:       payload.Sound,
-		Badge:       payload.Badge,
-		Category:    payload.Category,
-		Data:        data,
+// send waits for the provider's rate limit, then runs fn and counts the outcome
+func (d *Dispatcher) send(ctx context.Context, sender *platformSender, fn func() (string, error)) (string, error) {
+	waited, err := sender.limiter.Wait(ctx)
+	if err != nil {
+		return "", err
 	}
-
-	return d.apnsClient.Send(ctx, notification)
-}
-
-func (d *Dispatcher) sendToAndroid(ctx context.Context, token string, payload Payload) error {
-	data := map[string]string{
-		"reminder_id": payload.ReminderID.String(),
-		"title":       payload.Title,
-		"body":        payload.Body,
-		"due_at":      payload.DueAt,
-		"channel_id":  "reminders",
+	if waited > 0 {
+		sender.counters.throttled.Add(1)
+		sender.counters.throttledMs.Add(uint64(waited.Milliseconds()))
 	}
 
-	for k, v := range payload.Data {
-		data[k] = v
+	start := time.Now()
+	messageID, err := fn()
+	sender.counters.latencyMs.Add(uint64(time.Since(start).Milliseconds()))
+	if err != nil {
+		sender.counters.failed.Add(1)
+	} else {
+		sender.counters.sent.Add(1)
 	}
-
-	return d.fcmClient.SendData(ctx, token, data)
+	return messageID, err
 }
@@ -0,0 +1,376 @@
+package notification_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/user/remind-me/backend/internal/models"
+	"github.com/user/remind-me/backend/internal/notification"
+	"github.com/user/remind-me/backend/internal/notification/apns"
+	"github.com/user/remind-me/backend/internal/notification/fcm"
+	"github.com/user/remind-me/backend/internal/notification/notificationtest"
+)
+
+const testBadge = 3
+
+type dispatcherFixture struct {
+	user       *models.User
+	ios        *notification.FakeProvider
+	android    *notification.FakeProvider
+	devices    *notificationtest.Devices
+	deliveries *notificationtest.Deliveries
+	targets    *notificationtest.Targets
+	dispatcher *notification.Dispatcher
+}
+
+// newDispatcherFixture creates a dispatcher for a user owning devices, sending through
+// fake providers. Unless withAndroid is set, Android has no provider configured.
+func newDispatcherFixture(withAndroid bool, devices ...models.Device) *dispatcherFixture {
+	user := &models.User{ID: uuid.New(), Timezone: "UTC"}
+	for i := range devices {
+		devices[i].UserID = user.ID
+	}
+	f := &dispatcherFixture{
+		user:       user,
+		ios:        notification.NewFakeProvider(models.DeliveryProviderAPNs),
+		devices:    notificationtest.NewDevices(devices...),
+		deliveries: &notificationtest.Deliveries{},
+		targets:    notificationtest.NewTargets(),
+	}
+
+	var android notification.PushProvider
+	if withAndroid {
+		f.android = notification.NewFakeProvider(models.DeliveryProviderFCM)
+		android = f.android
+	}
+	f.dispatcher = notification.NewDispatcher(f.ios, android, f.devices, f.deliveries, f.targets, f.devices, notificationtest.Badges(testBadge), notification.Config{})
+	return f
+}
+
+func newDevice(platform models.Platform, token string) models.Device {
+	return models.Device{ID: uuid.New(), Platform: platform, PushToken: token, UpdatedAt: time.Now().Add(-time.Hour)}
+}
+
+func reminderPayload(reminderID uuid.UUID) notification.Payload {
+	return notification.Payload{
+		Title:      "Water plants",
+		ReminderID: reminderID,
+		Data:       map[string]string{"type": "reminder_due"},
+	}
+}
+
+func targetFor(t *testing.T, targets []models.NotificationTarget, deviceID uuid.UUID) models.NotificationTarget {
+	t.Helper()
+	for _, target := range targets {
+		if target.DeviceID == deviceID {
+			return target
+		}
+	}
+	t.Fatalf("no notification target for device %s", deviceID)
+	return models.NotificationTarget{}
+}
+
+func TestSendReminderDeliversOncePerToken(t *testing.T) {
+	phone := newDevice(models.PlatformIOS, "ios-token")
+	reinstalled := newDevice(models.PlatformIOS, "ios-token") // Same token under a new device identifier
+	tablet := newDevice(models.PlatformAndroid, "android-token")
+	f := newDispatcherFixture(true, phone, reinstalled, tablet)
+
+	reminderID := uuid.New()
+	retryAt, err := f.dispatcher.SendReminder(context.Background(), f.user, time.Now(), reminderPayload(reminderID))
+	if err != nil {
+		t.Fatalf("SendReminder: %v", err)
+	}
+	if retryAt != nil {
+		t.Errorf("retryAt = %v, want nil once every device is delivered to", retryAt)
+	}
+
+	iosPushes, androidPushes := f.ios.Pushes(), f.android.Pushes()
+	if len(iosPushes) != 1 || len(androidPushes) != 1 {
+		t.Fatalf("got %d iOS and %d Android pushes, want 1 each", len(iosPushes), len(androidPushes))
+	}
+	for _, push := range append(iosPushes, androidPushes...) {
+		if push.Alert == nil || push.Alert.Badge == nil || *push.Alert.Badge != testBadge {
+			t.Errorf("push to %s = %+v, want an alert with badge %d", push.Token, push.Alert, testBadge)
+		}
+	}
+
+	targets := f.targets.All()
+	if len(targets) != 2 {
+		t.Fatalf("got %d targets, want 2", len(targets))
+	}
+	for _, target := range targets {
+		if target.Status != models.TargetStatusDelivered || target.Attempts != 1 {
+			t.Errorf("target for device %s: status %s after %d attempts, want delivered after 1", target.DeviceID, target.Status, target.Attempts)
+		}
+	}
+
+	deliveries := f.deliveries.All()
+	if len(deliveries) != 2 {
+		t.Fatalf("got %d deliveries, want 2", len(deliveries))
+	}
+	for _, delivery := range deliveries {
+		if delivery.ReminderID != reminderID || delivery.Status != models.DeliveryStatusSent || delivery.ProviderMessageID == nil {
+			t.Errorf("delivery = %+v, want a sent delivery of reminder %s with a message ID", delivery, reminderID)
+		}
+	}
+}
+
+func TestSendReminderRetriesTemporaryFailures(t *testing.T) {
+	phone := newDevice(models.PlatformIOS, "ios-token")
+	tablet := newDevice(models.PlatformAndroid, "android-token")
+	f := newDispatcherFixture(true, phone, tablet)
+	f.android.FailToken("android-token", &fcm.Error{StatusCode: 503, Code: "UNAVAILABLE"})
+
+	dueAt := time.Now()
+	payload := reminderPayload(uuid.New())
+	retryAt, err := f.dispatcher.SendReminder(context.Background(), f.user, dueAt, payload)
+	if err != nil {
+		t.Fatalf("SendReminder: %v", err)
+	}
+	if retryAt == nil || !retryAt.After(time.Now()) {
+		t.Fatalf("retryAt = %v, want a retry in the future", retryAt)
+	}
+
+	target := targetFor(t, f.targets.All(), tablet.ID)
+	if target.Status != models.TargetStatusPending || target.Attempts != 1 || target.LastError == nil {
+		t.Errorf("failed target = %+v, want pending after 1 attempt with the error kept", target)
+	}
+	if status := targetFor(t, f.targets.All(), phone.ID).Status; status != models.TargetStatusDelivered {
+		t.Errorf("iOS target status = %s, want delivered", status)
+	}
+	if deliveries := f.deliveries.All(); len(deliveries) != 2 {
+		t.Errorf("got %d deliveries, want both attempts recorded", len(deliveries))
+	}
+
+	// Sending again before the retry is due leaves both devices alone
+	f.android.FailToken("android-token", nil)
+	again, err := f.dispatcher.SendReminder(context.Background(), f.user, dueAt, payload)
+	if err != nil {
+		t.Fatalf("SendReminder: %v", err)
+	}
+	if again == nil || !again.Equal(*retryAt) {
+		t.Errorf("retryAt = %v, want the pending retry at %v", again, retryAt)
+	}
+	if len(f.ios.Pushes()) != 1 || len(f.android.Pushes()) != 0 {
+		t.Errorf("got %d iOS and %d Android pushes, want no new pushes", len(f.ios.Pushes()), len(f.android.Pushes()))
+	}
+}
+
+func TestSendReminderPrunesInvalidTokens(t *testing.T) {
+	stale := newDevice(models.PlatformIOS, "stale-token")
+	f := newDispatcherFixture(false, stale)
+	invalidSince := time.Now()
+	f.ios.FailToken("stale-token", &apns.Error{StatusCode: 410, Reason: apns.ReasonUnregistered, Timestamp: &invalidSince})
+
+	retryAt, err := f.dispatcher.SendReminder(context.Background(), f.user, time.Now(), reminderPayload(uuid.New()))
+	if err != nil {
+		t.Fatalf("SendReminder: %v", err)
+	}
+	if retryAt != nil {
+		t.Errorf("retryAt = %v, want nil for a pruned device", retryAt)
+	}
+	if pruned := f.devices.Pruned(); len(pruned) != 1 || pruned[0] != "stale-token" {
+		t.Errorf("pruned tokens = %v, want [stale-token]", pruned)
+	}
+	deliveries := f.deliveries.All()
+	if len(deliveries) != 1 || deliveries[0].Status != models.DeliveryStatusInvalidToken {
+		t.Errorf("deliveries = %+v, want one with status %s", deliveries, models.DeliveryStatusInvalidToken)
+	}
+}
+
+func TestSendReminderKeepsTokensRegisteredAfterInvalidation(t *testing.T) {
+	device := newDevice(models.PlatformIOS, "ios-token")
+	device.UpdatedAt = time.Now()
+	f := newDispatcherFixture(false, device)
+	invalidSince := time.Now().Add(-time.Hour)
+	f.ios.FailToken("ios-token", &apns.Error{StatusCode: 410, Reason: apns.ReasonUnregistered, Timestamp: &invalidSince})
+
+	if _, err := f.dispatcher.SendReminder(context.Background(), f.user, time.Now(), reminderPayload(uuid.New())); err != nil {
+		t.Fatalf("SendReminder: %v", err)
+	}
+	if pruned := f.devices.Pruned(); len(pruned) != 0 {
+		t.Errorf("pruned tokens = %v, want none", pruned)
+	}
+	if status := targetFor(t, f.targets.All(), device.ID).Status; status != models.TargetStatusDeadLettered {
+		t.Errorf("target status = %s, want %s", status, models.TargetStatusDeadLettered)
+	}
+}
+
+func TestSendReminderDeadLettersDevicesWithoutProvider(t *testing.T) {
+	tablet := newDevice(models.PlatformAndroid, "android-token")
+	f := newDispatcherFixture(false, tablet)
+
+	retryAt, err := f.dispatcher.SendReminder(context.Background(), f.user, time.Now(), reminderPayload(uuid.New()))
+	if err != nil {
+		t.Fatalf("SendReminder: %v", err)
+	}
+	if retryAt != nil {
+		t.Errorf("retryAt = %v, want nil", retryAt)
+	}
+	target := targetFor(t, f.targets.All(), tablet.ID)
+	if target.Status != models.TargetStatusDeadLettered {
+		t.Errorf("target status = %s, want %s", target.Status, models.TargetStatusDeadLettered)
+	}
+	if target.LastError == nil || !strings.Contains(*target.LastError, notification.ErrNoProvider.Error()) {
+		t.Errorf("target error = %v, want the missing provider recorded", target.LastError)
+	}
+}
+
+// quietNow sets quiet hours on user covering the current time
+func quietNow(user *models.User) {
+	minute := time.Now().UTC().Hour()*60 + time.Now().UTC().Minute()
+	start, end := (minute+24*60-60)%(24*60), (minute+60)%(24*60)
+	user.QuietHoursStart, user.QuietHoursEnd = &start, &end
+}
+
+func TestSendReminderDefersDuringQuietHours(t *testing.T) {
+	phone := newDevice(models.PlatformIOS, "ios-token")
+	f := newDispatcherFixture(false, phone)
+	quietNow(f.user)
+
+	retryAt, err := f.dispatcher.SendReminder(context.Background(), f.user, time.Now(), reminderPayload(uuid.New()))
+	if err != nil {
+		t.Fatalf("SendReminder: %v", err)
+	}
+	if retryAt != nil {
+		t.Errorf("retryAt = %v, want nil once every device is deferred", retryAt)
+	}
+	if pushes := f.ios.Pushes(); len(pushes) != 0 {
+		t.Errorf("got %d pushes during quiet hours, want 0", len(pushes))
+	}
+	target := targetFor(t, f.targets.All(), phone.ID)
+	if target.Status != models.TargetStatusDeferred || target.NextAttemptAt == nil || !target.NextAttemptAt.After(time.Now()) {
+		t.Errorf("target = %+v, want deferred until quiet hours end", target)
+	}
+
+	// Alarms go through anyway
+	alarm := reminderPayload(uuid.New())
+	alarm.IsAlarm = true
+	if _, err := f.dispatcher.SendReminder(context.Background(), f.user, time.Now(), alarm); err != nil {
+		t.Fatalf("SendReminder: %v", err)
+	}
+	if pushes := f.ios.Pushes(); len(pushes) != 1 {
+		t.Errorf("got %d pushes for an alarm during quiet hours, want 1", len(pushes))
+	}
+}
+
+func TestSendToUserOutsideQuietHoursSkipsQuietDevices(t *testing.T) {
+	phone := newDevice(models.PlatformIOS, "ios-token")
+	tablet := newDevice(models.PlatformAndroid, "android-token")
+	tablet.QuietHoursOverride = true // Overrides the user's quiet hours with none
+	f := newDispatcherFixture(true, phone, tablet)
+	quietNow(f.user)
+
+	quietUntil, err := f.dispatcher.SendToUserOutsideQuietHours(context.Background(), f.user, time.Now(), notification.Payload{Title: "Digest"})
+	if err != nil {
+		t.Fatalf("SendToUserOutsideQuietHours: %v", err)
+	}
+	if quietUntil != nil {
+		t.Errorf("quietUntil = %v, want nil once a device was sent to", quietUntil)
+	}
+	if len(f.ios.Pushes()) != 0 || len(f.android.Pushes()) != 1 {
+		t.Errorf("got %d iOS and %d Android pushes, want only the device outside quiet hours", len(f.ios.Pushes()), len(f.android.Pushes()))
+	}
+}
+
+func TestSendToUserOutsideQuietHoursHoldsBackWhenAllQuiet(t *testing.T) {
+	phone := newDevice(models.PlatformIOS, "ios-token")
+	f := newDispatcherFixture(false, phone)
+	quietNow(f.user)
+
+	now := time.Now()
+	quietUntil, err := f.dispatcher.SendToUserOutsideQuietHours(context.Background(), f.user, now, notification.Payload{Title: "Digest"})
+	if err != nil {
+		t.Fatalf("SendToUserOutsideQuietHours: %v", err)
+	}
+	if quietUntil == nil || !quietUntil.After(now) || quietUntil.After(now.Add(time.Hour)) {
+		t.Errorf("quietUntil = %v, want the end of the quiet hours within the hour", quietUntil)
+	}
+	if pushes := f.ios.Pushes(); len(pushes) != 0 {
+		t.Errorf("got %d pushes during quiet hours, want 0", len(pushes))
+	}
+}
+
+func TestSendQuietHoursSummaries(t *testing.T) {
+	phone := newDevice(models.PlatformIOS, "ios-token")
+	f := newDispatcherFixture(false, phone)
+
+	ended := time.Now().Add(-time.Minute)
+	dueAt := time.Now().Add(-time.Hour)
+	for _, title := range []string{"Water plants", "Call mom"} {
+		reminder := models.Reminder{ID: uuid.New(), UserID: f.user.ID, Title: title, Status: models.StatusActive, DueAt: &dueAt}
+		f.targets.AddReminder(reminder)
+		if err := f.targets.Save(&models.NotificationTarget{ReminderID: reminder.ID, DeviceID: phone.ID, DueAt: dueAt, Status: models.TargetStatusDeferred, NextAttemptAt: &ended}); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+	// Completed during quiet hours, so left out of the summary
+	completed := models.Reminder{ID: uuid.New(), UserID: f.user.ID, Title: "Done", Status: models.StatusCompleted, DueAt: &dueAt}
+	f.targets.AddReminder(completed)
+	if err := f.targets.Save(&models.NotificationTarget{ReminderID: completed.ID, DeviceID: phone.ID, DueAt: dueAt, Status: models.TargetStatusDeferred, NextAttemptAt: &ended}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	sent, err := f.dispatcher.SendQuietHoursSummaries(context.Background())
+	if err != nil {
+		t.Fatalf("SendQuietHoursSummaries: %v", err)
+	}
+	if sent != 1 {
+		t.Errorf("sent = %d, want 1", sent)
+	}
+
+	pushes := f.ios.Pushes()
+	if len(pushes) != 1 || pushes[0].Alert == nil {
+		t.Fatalf("pushes = %+v, want one summary alert", pushes)
+	}
+	summary := pushes[0].Alert
+	if summary.Title != "2 reminders during quiet hours" || summary.Data["count"] != "2" {
+		t.Errorf("summary = %q with data %v, want 2 reminders", summary.Title, summary.Data)
+	}
+	if summary.Badge == nil || *summary.Badge != testBadge {
+		t.Errorf("summary badge = %v, want %d", summary.Badge, testBadge)
+	}
+
+	for _, target := range f.targets.All() {
+		want := models.TargetStatusDelivered
+		if target.ReminderID == completed.ID {
+			want = models.TargetStatusDeadLettered
+		}
+		if target.Status != want {
+			t.Errorf("target for reminder %s: status %s, want %s", target.ReminderID, target.Status, want)
+		}
+	}
+	if deliveries := f.deliveries.All(); len(deliveries) != 2 {
+		t.Errorf("got %d deliveries, want one per summarized reminder", len(deliveries))
+	}
+}
+
+func TestSendCrossDeviceActionCarriesBadge(t *testing.T) {
+	phone := newDevice(models.PlatformIOS, "ios-token")
+	tablet := newDevice(models.PlatformAndroid, "android-token")
+	f := newDispatcherFixture(true, phone, tablet)
+
+	reminderID := uuid.New()
+	if err := f.dispatcher.SendCrossDeviceAction(context.Background(), f.user.ID, &phone.ID, reminderID, notification.ActionComplete); err != nil {
+		t.Fatalf("SendCrossDeviceAction: %v", err)
+	}
+
+	if pushes := f.ios.Pushes(); len(pushes) != 0 {
+		t.Errorf("got %d pushes to the acting device, want 0", len(pushes))
+	}
+	pushes := f.android.Pushes()
+	if len(pushes) != 1 {
+		t.Fatalf("got %d pushes to other devices, want 1", len(pushes))
+	}
+	push := pushes[0]
+	if push.Badge == nil || *push.Badge != testBadge {
+		t.Errorf("badge = %v, want %d", push.Badge, testBadge)
+	}
+	if push.Data["action"] != string(notification.ActionComplete) || push.Data["reminder_id"] != reminderID.String() {
+		t.Errorf("data = %v, want the complete action for reminder %s", push.Data, reminderID)
+	}
+}
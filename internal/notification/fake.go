@@ -0,0 +1,120 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/user/remind-me/backend/internal/models"
+)
+
+// fakeMaxPushes bounds the pushes a FakeProvider keeps, so a server left running with
+// PUSH_MODE=fake doesn't grow without limit
+const fakeMaxPushes = 1000
+
+// FakePush is a push recorded by a FakeProvider
+type FakePush struct {
+	Token     string
+	Alert     *Payload          // Set for SendAlert
+	Data      map[string]string // Set for SendData and SendBadge
+	Badge     *int              // Set for SendBadge
+	MessageID string
+	SentAt    time.Time
+}
+
+// FakeProvider records pushes in memory instead of sending them, so notifications can
+// be exercised locally and in tests without APNs or FCM credentials
+type FakeProvider struct {
+	name models.DeliveryProvider
+
+	mu     sync.Mutex
+	pushes []FakePush
+	errs   map[string]error
+	seq    int
+}
+
+// NewFakeProvider creates a fake that reports itself as the named provider
+func NewFakeProvider(name models.DeliveryProvider) *FakeProvider {
+	return &FakeProvider{
+		name: name,
+		errs: make(map[string]error),
+	}
+}
+
+func (p *FakeProvider) Name() models.DeliveryProvider {
+	return p.name
+}
+
+func (p *FakeProvider) SendAlert(ctx context.Context, token string, payload Payload) (string, error) {
+	return p.record(ctx, FakePush{Token: token, Alert: &payload})
+}
+
+func (p *FakeProvider) SendData(ctx context.Context, token string, data map[string]string) (string, error) {
+	return p.record(ctx, FakePush{Token: token, Data: data})
+}
+
+func (p *FakeProvider) SendBadge(ctx context.Context, token string, badge int, data map[string]string) (string, error) {
+	return p.record(ctx, FakePush{Token: token, Data: data, Badge: &badge})
+}
+
+// FailToken makes sends to token fail with err, or succeed again if err is nil
+func (p *FakeProvider) FailToken(token string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err == nil {
+		delete(p.errs, token)
+		return
+	}
+	p.errs[token] = err
+}
+
+// Pushes returns the most recent pushes recorded, up to fakeMaxPushes, oldest first
+func (p *FakeProvider) Pushes() []FakePush {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]FakePush(nil), p.pushes...)
+}
+
+// Reset forgets recorded pushes and configured failures
+func (p *FakeProvider) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.pushes = nil
+	p.errs = make(map[string]error)
+}
+
+func (p *FakeProvider) record(ctx context.Context, push FakePush) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err, ok := p.errs[push.Token]; ok {
+		return "", err
+	}
+
+	p.seq++
+	push.MessageID = fmt.Sprintf("fake-%s-%d", p.name, p.seq)
+	push.SentAt = time.Now()
+	if len(p.pushes) >= fakeMaxPushes {
+		p.pushes = slices.Delete(p.pushes, 0, len(p.pushes)-fakeMaxPushes+1)
+	}
+	p.pushes = append(p.pushes, push)
+
+	if push.Alert != nil {
+		log.Printf("[FakePush] %s alert to %s: %q %q %v", p.name, push.Token, push.Alert.Title, push.Alert.Body, push.Alert.Data)
+	} else if push.Badge != nil {
+		log.Printf("[FakePush] %s badge %d to %s: %v", p.name, *push.Badge, push.Token, push.Data)
+	} else {
+		log.Printf("[FakePush] %s data to %s: %v", p.name, push.Token, push.Data)
+	}
+	return push.MessageID, nil
+}
@@ -0,0 +1,26 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/user/remind-me/backend/internal/models"
+)
+
+func TestFakeProviderKeepsRecentPushes(t *testing.T) {
+	p := NewFakeProvider(models.DeliveryProviderFCM)
+	for i := range fakeMaxPushes + 10 {
+		if _, err := p.SendData(context.Background(), fmt.Sprintf("token-%d", i), nil); err != nil {
+			t.Fatalf("SendData: %v", err)
+		}
+	}
+
+	pushes := p.Pushes()
+	if len(pushes) != fakeMaxPushes {
+		t.Fatalf("kept %d pushes, want %d", len(pushes), fakeMaxPushes)
+	}
+	if first, last := pushes[0].Token, pushes[len(pushes)-1].Token; first != "token-10" || last != fmt.Sprintf("token-%d", fakeMaxPushes+9) {
+		t.Errorf("kept pushes %s to %s, want the most recent", first, last)
+	}
+}
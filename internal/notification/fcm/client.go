@@ -5,7 +5,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"sync"
 	"time"
@@ -51,6 +50,7 @@ type Client struct {
 	httpClient *http.Client
 	projectID  string
 	credentials []byte
+	dryRun     bool
 
 	// Access token caching
 	tokenMu     sync.RWMutex
@@ -62,6 +62,8 @@ type Client struct {
 type Config struct {
 	ProjectID      string
 	CredentialsJSON string
+	// DryRun logs each request instead of sending it; credentials are optional
+	DryRun bool
 }
 
 // NewClient creates a new FCM client
@@ -72,11 +74,12 @@ func NewClient(config Config) (*Client, error) {
 		},
 		projectID:   config.ProjectID,
 		credentials: []byte(config.CredentialsJSON),
+		dryRun:      config.DryRun,
 	}, nil
 }
 
-// Send sends a notification message to a device
-func (c *Client) Send(ctx context.Context, token string, notification *Notification, data map[string]string) error {
+// Send sends a notification message to a device and returns the message name FCM assigned to it
+func (c *Client) Send(ctx context.Context, token string, notification *Notification, data map[string]string) (string, error) {
 	message := Message{
 		Token:        token,
 		Notification: notification,
@@ -94,7 +97,7 @@ func (c *Client) Send(ctx context.Context, token string, notification *Notificat
 }
 
 // SendData sends a data-only message (for Android to handle in background)
-func (c *Client) SendData(ctx context.Context, token string, data map[string]string) error {
+func (c *Client) SendData(ctx context.Context, token string, data map[string]string) (string, error) {
 	message := Message{
 		Token: token,
 		Data:  data,
@@ -107,7 +110,7 @@ func (c *Client) SendData(ctx context.Context, token string, data map[string]str
 }
 
 // SendToTopic sends a message to a topic
-func (c *Client) SendToTopic(ctx context.Context, topic string, notification *Notification, data map[string]string) error {
+func (c *Client) SendToTopic(ctx context.Context, topic string, notification *Notification, data map[string]string) (string, error) {
 	message := Message{
 		Topic:        topic,
 		Notification: notification,
@@ -117,26 +120,30 @@ func (c *Client) SendToTopic(ctx context.Context, topic string, notification *No
 	return c.sendMessage(ctx, message)
 }
 
-func (c *Client) sendMessage(ctx context.Context, message Message) error {
+func (c *Client) sendMessage(ctx context.Context, message Message) (string, error) {
 	payload := map[string]interface{}{
 		"message": message,
 	}
 
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
-		return fmt.Errorf("failed to marshal payload: %w", err)
+		return "", fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
 	url := fmt.Sprintf(FCMURL, c.projectID)
 
+	if c.dryRun {
+		return c.logDryRun(url, payloadBytes), nil
+	}
+
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(payloadBytes))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
 	token, err := c.getAccessToken(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get access token: %w", err)
+		return "", fmt.Errorf("failed to get access token: %w", err)
 	}
 
 	req.Header.Set("Authorization", "Bearer "+token)
@@ -144,16 +151,21 @@ func (c *Client) sendMessage(ctx context.Context, message Message) error {
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send message: %w", err)
+		return "", fmt.Errorf("failed to send message: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("FCM error: %s - %s", resp.Status, string(body))
+		return "", parseError(resp)
+	}
+
+	var result struct {
+		Name string `json:"name"`
 	}
+	// The message was accepted even if its name can't be read
+	_ = json.NewDecoder(resp.Body).Decode(&result)
 
-	return nil
+	return result.Name, nil
 }
 
 func (c *Client) getAccessToken(ctx context.Context) (string, error) {
@@ -0,0 +1,13 @@
+package fcm
+
+import (
+	"log"
+
+	"github.com/google/uuid"
+)
+
+// logDryRun logs the request a dry-run client would have sent and returns a placeholder message name
+func (c *Client) logDryRun(url string, payload []byte) string {
+	log.Printf("[FCM dry run] POST %s %s", url, payload)
+	return "projects/" + c.projectID + "/messages/dry-run-" + uuid.NewString()
+}
@@ -0,0 +1,105 @@
+package fcm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/user/remind-me/backend/internal/notification/retryafter"
+)
+
+// FCM error codes that mean the registration token is no longer usable. See
+// https://firebase.google.com/docs/reference/fcm/rest/v1/ErrorCode
+const (
+	ErrorCodeUnregistered     = "UNREGISTERED"
+	ErrorCodeInvalidArgument  = "INVALID_ARGUMENT"
+	ErrorCodeSenderIDMismatch = "SENDER_ID_MISMATCH"
+	tokenFieldViolation       = "message.token"
+)
+
+// Error is a message rejected by FCM
+type Error struct {
+	StatusCode int
+	// Code is the FcmError errorCode if present, otherwise the RPC status
+	Code    string
+	Message string
+	// RetryAfter is the wait requested by a Retry-After header, zero if none was sent
+	RetryAfter time.Duration
+	// tokenRejected is set when an INVALID_ARGUMENT names the token as the bad field
+	tokenRejected bool
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("FCM error: %d %s - %s", e.StatusCode, e.Code, e.Message)
+}
+
+// InvalidToken reports whether the registration token will never be accepted
+// again, so the registration should be removed rather than retried.
+// INVALID_ARGUMENT also covers malformed payloads, so it only counts when FCM
+// blames the token.
+func (e *Error) InvalidToken() bool {
+	switch e.Code {
+	case ErrorCodeUnregistered, ErrorCodeSenderIDMismatch:
+		return true
+	case ErrorCodeInvalidArgument:
+		return e.tokenRejected
+	}
+	return false
+}
+
+// Temporary reports whether the message may be accepted if sent again later
+// (QUOTA_EXCEEDED, UNAVAILABLE, INTERNAL and other server errors)
+func (e *Error) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+// RetryDelay returns how long FCM asked us to wait before retrying
+func (e *Error) RetryDelay() time.Duration {
+	return e.RetryAfter
+}
+
+// parseError reads the google.rpc.Status body of a non-200 FCM response
+func parseError(resp *http.Response) error {
+	var payload struct {
+		Error struct {
+			Message string `json:"message"`
+			Status  string `json:"status"`
+			Details []struct {
+				Type            string `json:"@type"`
+				ErrorCode       string `json:"errorCode"`
+				FieldViolations []struct {
+					Field string `json:"field"`
+				} `json:"fieldViolations"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	raw, _ := io.ReadAll(resp.Body)
+	_ = json.Unmarshal(raw, &payload)
+
+	fcmErr := &Error{
+		StatusCode: resp.StatusCode,
+		Code:       payload.Error.Status,
+		Message:    payload.Error.Message,
+		RetryAfter: retryafter.Parse(resp.Header.Get("Retry-After")),
+	}
+	if fcmErr.Message == "" {
+		fcmErr.Message = string(raw)
+	}
+	for _, detail := range payload.Error.Details {
+		if detail.ErrorCode != "" {
+			fcmErr.Code = detail.ErrorCode
+		}
+		for _, violation := range detail.FieldViolations {
+			if violation.Field == tokenFieldViolation {
+				fcmErr.tokenRejected = true
+			}
+		}
+	}
+	if strings.Contains(strings.ToLower(fcmErr.Message), "registration token") {
+		fcmErr.tokenRejected = true
+	}
+	return fcmErr
+}
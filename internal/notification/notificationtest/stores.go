@@ -0,0 +1,227 @@
+// Package notificationtest provides in-memory stores for exercising the notification
+// dispatcher without a database. Use them with notification.FakeProvider.
+package notificationtest
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/user/remind-me/backend/internal/models"
+	"github.com/user/remind-me/backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Devices is an in-memory notification.DeviceStore and notification.TokenPruner
+type Devices struct {
+	mu      sync.Mutex
+	devices []models.Device
+	pruned  []string
+}
+
+// NewDevices creates a store holding devices
+func NewDevices(devices ...models.Device) *Devices {
+	return &Devices{devices: devices}
+}
+
+func (s *Devices) FindByID(id uuid.UUID) (*models.Device, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, device := range s.devices {
+		if device.ID == id {
+			return &device, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (s *Devices) ListByUser(userID uuid.UUID) ([]models.Device, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var devices []models.Device
+	for _, device := range s.devices {
+		if device.UserID == userID {
+			devices = append(devices, device)
+		}
+	}
+	return devices, nil
+}
+
+func (s *Devices) GetAllPushTokens(userID uuid.UUID) ([]repository.PushToken, error) {
+	return s.pushTokens(userID, uuid.Nil)
+}
+
+func (s *Devices) GetPushTokensExcluding(userID uuid.UUID, excludeDeviceID uuid.UUID) ([]repository.PushToken, error) {
+	return s.pushTokens(userID, excludeDeviceID)
+}
+
+func (s *Devices) pushTokens(userID, excludeDeviceID uuid.UUID) ([]repository.PushToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var tokens []repository.PushToken
+	for _, device := range s.devices {
+		token := repository.PushToken{Platform: device.Platform, PushToken: device.PushToken}
+		if device.UserID == userID && device.ID != excludeDeviceID && !slices.Contains(tokens, token) {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+// PruneInvalidToken removes the devices registered with pushToken and last updated
+// before invalidSince, if set
+func (s *Devices) PruneInvalidToken(pushToken string, invalidSince *time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := len(s.devices)
+	s.devices = slices.DeleteFunc(s.devices, func(device models.Device) bool {
+		return device.PushToken == pushToken && (invalidSince == nil || device.UpdatedAt.Before(*invalidSince))
+	})
+	if len(s.devices) == before {
+		return false, nil
+	}
+	s.pruned = append(s.pruned, pushToken)
+	return true, nil
+}
+
+// Pruned returns the push tokens removed by PruneInvalidToken
+func (s *Devices) Pruned() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.pruned)
+}
+
+// Deliveries is an in-memory notification.DeliveryLog
+type Deliveries struct {
+	mu         sync.Mutex
+	deliveries []models.NotificationDelivery
+}
+
+func (s *Deliveries) Create(delivery *models.NotificationDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deliveries = append(s.deliveries, *delivery)
+	return nil
+}
+
+// All returns the recorded deliveries, oldest first
+func (s *Deliveries) All() []models.NotificationDelivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.deliveries)
+}
+
+// Targets is an in-memory notification.TargetStore. Claimed deferred targets have their
+// reminder loaded from the reminders added with AddReminder.
+type Targets struct {
+	mu        sync.Mutex
+	targets   []models.NotificationTarget
+	reminders map[uuid.UUID]models.Reminder
+}
+
+// NewTargets creates an empty target store
+func NewTargets() *Targets {
+	return &Targets{reminders: make(map[uuid.UUID]models.Reminder)}
+}
+
+// AddReminder makes reminder available to ClaimDeferred
+func (s *Targets) AddReminder(reminder models.Reminder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reminders[reminder.ID] = reminder
+}
+
+func (s *Targets) ListByOccurrence(reminderID uuid.UUID, dueAt time.Time) ([]models.NotificationTarget, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var targets []models.NotificationTarget
+	for _, target := range s.targets {
+		if target.ReminderID == reminderID && target.DueAt.Equal(dueAt) {
+			targets = append(targets, target)
+		}
+	}
+	return targets, nil
+}
+
+func (s *Targets) ClaimDeferred(now time.Time, limit int, lease time.Duration) ([]models.NotificationTarget, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var claimed []models.NotificationTarget
+	var devices []uuid.UUID
+	for i := range s.targets {
+		target := &s.targets[i]
+		if target.Status != models.TargetStatusDeferred || target.NextAttemptAt.After(now) {
+			continue
+		}
+		if !slices.Contains(devices, target.DeviceID) {
+			if len(devices) == limit {
+				continue
+			}
+			devices = append(devices, target.DeviceID)
+		}
+
+		leaseEnd := now.Add(lease)
+		target.NextAttemptAt = &leaseEnd
+		result := *target
+		if reminder, ok := s.reminders[target.ReminderID]; ok {
+			result.Reminder = &reminder
+		}
+		claimed = append(claimed, result)
+	}
+
+	slices.SortStableFunc(claimed, func(a, b models.NotificationTarget) int {
+		if a.DeviceID != b.DeviceID {
+			return slices.Index(devices, a.DeviceID) - slices.Index(devices, b.DeviceID)
+		}
+		return a.DueAt.Compare(b.DueAt)
+	})
+	return claimed, nil
+}
+
+func (s *Targets) Save(target *models.NotificationTarget) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := *target
+	saved.Reminder = nil
+	if target.ID == 0 {
+		target.ID = int64(len(s.targets) + 1)
+		saved.ID = target.ID
+		s.targets = append(s.targets, saved)
+		return nil
+	}
+	for i := range s.targets {
+		if s.targets[i].ID == target.ID {
+			s.targets[i] = saved
+			return nil
+		}
+	}
+	s.targets = append(s.targets, saved)
+	return nil
+}
+
+// All returns the stored targets in the order they were first saved
+func (s *Targets) All() []models.NotificationTarget {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.targets)
+}
+
+// Badges is a notification.BadgeCounter reporting the same count for every user
+type Badges int
+
+func (b Badges) BadgeCount(userID uuid.UUID) (int, error) {
+	return int(b), nil
+}
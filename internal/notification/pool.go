@@ -0,0 +1,127 @@
+package notification
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/user/remind-me/backend/internal/models"
+)
+
+// Stats are counters describing dispatcher activity since startup
+type Stats struct {
+	Workers  int                                       `json:"workers"`
+	InFlight int64                                     `json:"in_flight"`
+	Queued   int64                                     `json:"queued"`
+	Uptime   float64                                   `json:"uptime_seconds"`
+	Provider map[models.DeliveryProvider]ProviderStats `json:"providers"`
+}
+
+// ProviderStats are the send counters for one push provider
+type ProviderStats struct {
+	Sent          uint64  `json:"sent"`
+	Failed        uint64  `json:"failed"`
+	SentPerSecond float64 `json:"sent_per_second"` // Average since startup
+	AvgLatencyMs  float64 `json:"avg_latency_ms"`
+	Throttled     uint64  `json:"throttled"` // Sends delayed by the rate limit
+	ThrottledMs   uint64  `json:"throttled_ms"`
+}
+
+// providerCounters accumulates ProviderStats
+type providerCounters struct {
+	sent        atomic.Uint64
+	failed      atomic.Uint64
+	latencyMs   atomic.Uint64
+	throttled   atomic.Uint64
+	throttledMs atomic.Uint64
+}
+
+func (c *providerCounters) snapshot(uptime time.Duration) ProviderStats {
+	stats := ProviderStats{
+		Sent:        c.sent.Load(),
+		Failed:      c.failed.Load(),
+		Throttled:   c.throttled.Load(),
+		ThrottledMs: c.throttledMs.Load(),
+	}
+	if seconds := uptime.Seconds(); seconds > 0 {
+		stats.SentPerSecond = float64(stats.Sent) / seconds
+	}
+	if attempts := stats.Sent + stats.Failed; attempts > 0 {
+		stats.AvgLatencyMs = float64(c.latencyMs.Load()) / float64(attempts)
+	}
+	return stats
+}
+
+// Stats returns a snapshot of the dispatcher counters
+func (d *Dispatcher) Stats() Stats {
+	uptime := time.Since(d.started)
+	providers := make(map[models.DeliveryProvider]ProviderStats, len(d.senders))
+	for _, sender := range d.senders {
+		providers[sender.provider.Name()] = sender.counters.snapshot(uptime)
+	}
+
+	return Stats{
+		Workers:  cap(d.slots),
+		InFlight: d.inFlight.Load(),
+		Queued:   d.queued.Load(),
+		Uptime:   uptime.Seconds(),
+		Provider: providers,
+	}
+}
+
+// sendGroup runs a batch of sends on the dispatcher's shared worker pool. The pool caps
+// the sends in flight across every caller, so a spike of due reminders queues for a
+// worker instead of opening a connection per device.
+type sendGroup struct {
+	d        *Dispatcher
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	firstErr error
+}
+
+func (d *Dispatcher) newSendGroup() *sendGroup {
+	return &sendGroup{d: d}
+}
+
+// Go runs fn once a worker is free. If ctx ends first, fn is skipped and ctx's error is
+// recorded instead.
+func (g *sendGroup) Go(ctx context.Context, fn func() error) {
+	g.d.queued.Add(1)
+	select {
+	case g.d.slots <- struct{}{}:
+		g.d.queued.Add(-1)
+	case <-ctx.Done():
+		g.d.queued.Add(-1)
+		g.fail(ctx.Err())
+		return
+	}
+
+	g.d.inFlight.Add(1)
+	g.wg.Add(1)
+	go func() {
+		defer func() {
+			g.d.inFlight.Add(-1)
+			<-g.d.slots
+			g.wg.Done()
+		}()
+
+		if err := fn(); err != nil {
+			g.fail(err)
+		}
+	}()
+}
+
+// Wait waits for the group's sends and returns the first error
+func (g *sendGroup) Wait() error {
+	g.wg.Wait()
+	return g.firstErr
+}
+
+func (g *sendGroup) fail(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.firstErr == nil {
+		g.firstErr = err
+	}
+}
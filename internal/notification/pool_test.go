@@ -0,0 +1,124 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newPoolDispatcher returns a dispatcher with only its worker pool set up
+func newPoolDispatcher(workers int) *Dispatcher {
+	return &Dispatcher{slots: make(chan struct{}, workers), started: time.Now()}
+}
+
+// waitForStats waits until the dispatcher reports the given in-flight and queued sends
+func waitForStats(t *testing.T, d *Dispatcher, inFlight, queued int64) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		stats := d.Stats()
+		if stats.InFlight == inFlight && stats.Queued == queued {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d in flight and %d queued, want %d and %d", stats.InFlight, stats.Queued, inFlight, queued)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSendGroupCapsSendsInFlight(t *testing.T) {
+	d := newPoolDispatcher(2)
+	release := make(chan struct{})
+	var running, peak atomic.Int32
+	send := func() error {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		<-release
+		running.Add(-1)
+		return nil
+	}
+
+	group := d.newSendGroup()
+	queuing := make(chan struct{})
+	go func() {
+		defer close(queuing)
+		for i := 0; i < 5; i++ {
+			group.Go(context.Background(), send)
+		}
+	}()
+
+	// Two sends run and the third waits for a worker
+	waitForStats(t, d, 2, 1)
+
+	close(release)
+	<-queuing
+	if err := group.Wait(); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if peak.Load() != 2 {
+		t.Errorf("%d sends ran at once, want 2", peak.Load())
+	}
+	waitForStats(t, d, 0, 0)
+	if len(d.slots) != 0 {
+		t.Errorf("%d workers still held after draining, want 0", len(d.slots))
+	}
+}
+
+func TestSendGroupSkipsSendsWhenContextEnds(t *testing.T) {
+	d := newPoolDispatcher(1)
+	release := make(chan struct{})
+	var ran atomic.Int32
+
+	group := d.newSendGroup()
+	group.Go(context.Background(), func() error {
+		<-release
+		ran.Add(1)
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	queued := make(chan struct{})
+	go func() {
+		defer close(queued)
+		group.Go(ctx, func() error {
+			ran.Add(1)
+			return nil
+		})
+	}()
+	waitForStats(t, d, 1, 1)
+
+	cancel()
+	<-queued
+	close(release)
+
+	if err := group.Wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait = %v, want %v", err, context.Canceled)
+	}
+	if ran.Load() != 1 {
+		t.Errorf("%d sends ran, want only the one that had a worker", ran.Load())
+	}
+	waitForStats(t, d, 0, 0)
+}
+
+func TestSendGroupReturnsFirstError(t *testing.T) {
+	d := newPoolDispatcher(1)
+	first, second := errors.New("first"), errors.New("second")
+
+	group := d.newSendGroup()
+	group.Go(context.Background(), func() error { return first })
+	group.Go(context.Background(), func() error { return nil })
+	group.Go(context.Background(), func() error { return second })
+
+	// One worker runs the sends in order
+	if err := group.Wait(); !errors.Is(err, first) {
+		t.Errorf("Wait = %v, want %v", err, first)
+	}
+}
@@ -0,0 +1,123 @@
+package notification
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/user/remind-me/backend/internal/models"
+	"github.com/user/remind-me/backend/internal/notification/apns"
+	"github.com/user/remind-me/backend/internal/notification/fcm"
+)
+
+// PushProvider delivers pushes to the devices of one platform
+type PushProvider interface {
+	// Name identifies the provider in the delivery log and metrics
+	Name() models.DeliveryProvider
+	// SendAlert shows payload to the user and returns the provider's message ID
+	SendAlert(ctx context.Context, token string, payload Payload) (string, error)
+	// SendData delivers data silently to the app and returns the provider's message ID
+	SendData(ctx context.Context, token string, data map[string]string) (string, error)
+	// SendBadge delivers data silently like SendData and sets the app icon badge to badge
+	SendBadge(ctx context.Context, token string, badge int, data map[string]string) (string, error)
+}
+
+// APNsProvider sends to iOS devices through APNs
+type APNsProvider struct {
+	client *apns.Client
+}
+
+// NewAPNsProvider creates a provider backed by an APNs client
+func NewAPNsProvider(client *apns.Client) *APNsProvider {
+	return &APNsProvider{client: client}
+}
+
+func (p *APNsProvider) Name() models.DeliveryProvider {
+	return models.DeliveryProviderAPNs
+}
+
+func (p *APNsProvider) SendAlert(ctx context.Context, token string, payload Payload) (string, error) {
+	// Build base Data map
+	data := map[string]interface{}{
+		"due_at": payload.DueAt,
+	}
+	if payload.ReminderID != uuid.Nil {
+		data["reminder_id"] = payload.ReminderID.String()
+	}
+
+	// Merge payload.Data (contains sound_id, is_alarm, type)
+	for k, v := range payload.Data {
+		data[k] = v
+	}
+
+	return p.client.Send(ctx, apns.Notification{
+		DeviceToken: token,
+		Title:       payload.Title,
+		Body:        payload.Body,
+		Sound:       payload.Sound,
+		Badge:       payload.Badge,
+		Category:    payload.Category,
+		Data:        data,
+	})
+}
+
+func (p *APNsProvider) SendData(ctx context.Context, token string, data map[string]string) (string, error) {
+	return p.client.SendData(ctx, token, data)
+}
+
+func (p *APNsProvider) SendBadge(ctx context.Context, token string, badge int, data map[string]string) (string, error) {
+	return p.client.SendBackground(ctx, token, &badge, data)
+}
+
+// FCMProvider sends to Android devices through FCM
+type FCMProvider struct {
+	client *fcm.Client
+}
+
+// NewFCMProvider creates a provider backed by an FCM client
+func NewFCMProvider(client *fcm.Client) *FCMProvider {
+	return &FCMProvider{client: client}
+}
+
+func (p *FCMProvider) Name() models.DeliveryProvider {
+	return models.DeliveryProviderFCM
+}
+
+// SendAlert sends the alert as a data message; the app builds the notification itself
+// so it can apply the reminder's channel and sound
+func (p *FCMProvider) SendAlert(ctx context.Context, token string, payload Payload) (string, error) {
+	data := map[string]string{
+		"title":      payload.Title,
+		"body":       payload.Body,
+		"due_at":     payload.DueAt,
+		"channel_id": "reminders",
+	}
+	if payload.ReminderID != uuid.Nil {
+		data["reminder_id"] = payload.ReminderID.String()
+	}
+
+	if payload.Badge != nil {
+		data["badge"] = strconv.Itoa(*payload.Badge)
+	}
+
+	for k, v := range payload.Data {
+		data[k] = v
+	}
+
+	return p.client.SendData(ctx, token, data)
+}
+
+func (p *FCMProvider) SendData(ctx context.Context, token string, data map[string]string) (string, error) {
+	return p.client.SendData(ctx, token, data)
+}
+
+// SendBadge passes the badge in the data; Android has no server-set badge, so the app
+// applies it
+func (p *FCMProvider) SendBadge(ctx context.Context, token string, badge int, data map[string]string) (string, error) {
+	withBadge := make(map[string]string, len(data)+1)
+	for k, v := range data {
+		withBadge[k] = v
+	}
+	withBadge["badge"] = strconv.Itoa(badge)
+	return p.client.SendData(ctx, token, withBadge)
+}
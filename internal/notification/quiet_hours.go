@@ -0,0 +1,212 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/user/remind-me/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// Deferred notifications are claimed for summaryClaimBatchSize devices at a time. The
+// lease must outlast the time a batch takes to send, or another worker may claim it too.
+const (
+	summaryClaimBatchSize = 100
+	summaryClaimLease     = 2 * time.Minute
+	summaryMaxTitles      = 3 // Reminder titles listed in a summary before "and N more"
+)
+
+// SendToUserOutsideQuietHours sends payload to each of the user's devices that is not
+// in quiet hours at now. If every device is held back nothing is sent, and it returns
+// when the first of their quiet hours ends so the caller can send again then. Devices
+// still in quiet hours when others receive the push miss it.
+func (d *Dispatcher) SendToUserOutsideQuietHours(ctx context.Context, user *models.User, now time.Time, payload Payload) (*time.Time, error) {
+	devices, err := d.deviceRepo.ListByUser(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now = now.In(user.Location())
+	var quietUntil *time.Time
+	awake := devices[:0]
+	for _, device := range devices {
+		quiet := user.QuietHoursFor(&device)
+		if quiet == nil || !quiet.Contains(now) {
+			awake = append(awake, device)
+			continue
+		}
+		if end := quiet.EndAfter(now); quietUntil == nil || end.Before(*quietUntil) {
+			quietUntil = &end
+		}
+	}
+
+	if len(awake) == 0 && quietUntil != nil {
+		return quietUntil, nil
+	}
+	return nil, d.sendToDevices(ctx, user.ID, awake, payload)
+}
+
+// SendQuietHoursSummaries sends each device whose quiet hours have ended a single push
+// summarizing the reminders deferred during them, and returns how many summaries it
+// sent. Reminders completed, deleted or rescheduled in the meantime are left out.
+// Failed summaries are retried with backoff like reminder pushes.
+func (d *Dispatcher) SendQuietHoursSummaries(ctx context.Context) (int, error) {
+	sent := 0
+	for ctx.Err() == nil {
+		targets, err := d.targetRepo.ClaimDeferred(time.Now(), summaryClaimBatchSize, summaryClaimLease)
+		if err != nil {
+			return sent, err
+		}
+		if len(targets) == 0 {
+			break
+		}
+
+		// Targets come ordered by device
+		var groups [][]models.NotificationTarget
+		for i, target := range targets {
+			if i == 0 || target.DeviceID != targets[i-1].DeviceID {
+				groups = append(groups, nil)
+			}
+			groups[len(groups)-1] = append(groups[len(groups)-1], target)
+		}
+
+		group := d.newSendGroup()
+		delivered := make([]bool, len(groups))
+		for i, deviceTargets := range groups {
+			group.Go(ctx, func() error {
+				delivered[i] = d.sendSummary(ctx, deviceTargets)
+				return nil
+			})
+		}
+		_ = group.Wait() // Targets not attempted are claimed again once their lease expires
+
+		for _, ok := range delivered {
+			if ok {
+				sent++
+			}
+		}
+		if len(groups) < summaryClaimBatchSize {
+			break
+		}
+	}
+	return sent, nil
+}
+
+// sendSummary sends one device its summary of targets and reports whether it was delivered
+func (d *Dispatcher) sendSummary(ctx context.Context, targets []models.NotificationTarget) bool {
+	deviceID := targets[0].DeviceID
+	device, err := d.deviceRepo.FindByID(deviceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		d.closeTargets(targets, errors.New("device no longer registered"))
+		return false
+	}
+	if err != nil {
+		log.Printf("Failed to load device %s for quiet-hours summary: %v", deviceID, err)
+		return false
+	}
+
+	var reminders []models.Reminder
+	var pending, stale []models.NotificationTarget
+	for _, target := range targets {
+		reminder := target.Reminder
+		if reminder == nil || reminder.Status != models.StatusActive || reminder.DueAt == nil || !reminder.DueAt.Equal(target.DueAt) {
+			stale = append(stale, target)
+			continue
+		}
+		reminders = append(reminders, *reminder)
+		pending = append(pending, target)
+	}
+	d.closeTargets(stale, errors.New("reminder completed, deleted or rescheduled during quiet hours"))
+	if len(pending) == 0 {
+		return false
+	}
+
+	sendErr := d.pushSummary(ctx, *device, reminders)
+	if sendErr != nil && d.handleSendError(device.Platform, device.PushToken, sendErr) == nil {
+		// The device was pruned for an invalid token
+		d.closeTargets(pending, sendErr)
+		return false
+	}
+
+	for _, target := range pending {
+		advanceTarget(&target, sendErr)
+		if err := d.targetRepo.Save(&target); err != nil {
+			log.Printf("Failed to save notification target for reminder %s device %s: %v", target.ReminderID, target.DeviceID, err)
+		}
+	}
+	return sendErr == nil
+}
+
+// pushSummary pushes the summary of reminders to device and records it in the delivery
+// log of each reminder
+func (d *Dispatcher) pushSummary(ctx context.Context, device models.Device, reminders []models.Reminder) error {
+	sender, ok := d.senders[device.Platform]
+	if !ok {
+		return fmt.Errorf("%w for %s", ErrNoProvider, device.Platform)
+	}
+
+	payload := summaryPayload(reminders)
+	payload.Badge = d.badgeCount(device.UserID)
+	start := time.Now()
+	messageID, sendErr := d.send(ctx, sender, func() (string, error) {
+		return sender.provider.SendAlert(ctx, device.PushToken, payload)
+	})
+	latency := time.Since(start)
+
+	for _, reminder := range reminders {
+		payload.ReminderID = reminder.ID
+		d.recordDelivery(device, payload, sender.provider.Name(), messageID, latency, sendErr)
+	}
+	return sendErr
+}
+
+// summaryPayload builds the push listing reminders held back during quiet hours
+func summaryPayload(reminders []models.Reminder) Payload {
+	title := fmt.Sprintf("%d reminders during quiet hours", len(reminders))
+	if len(reminders) == 1 {
+		title = "1 reminder during quiet hours"
+	}
+
+	titles := make([]string, 0, summaryMaxTitles)
+	ids := make([]string, len(reminders))
+	for i, reminder := range reminders {
+		if i < summaryMaxTitles {
+			titles = append(titles, reminder.Title)
+		}
+		ids[i] = reminder.ID.String()
+	}
+	body := strings.Join(titles, ", ")
+	if more := len(reminders) - len(titles); more > 0 {
+		body += fmt.Sprintf(" and %d more", more)
+	}
+
+	return Payload{
+		Title:    title,
+		Body:     body,
+		Sound:    "default",
+		Category: "REMINDER_SUMMARY",
+		Data: map[string]string{
+			"type":         "reminder_summary",
+			"reminder_ids": strings.Join(ids, ","),
+			"count":        strconv.Itoa(len(reminders)),
+		},
+	}
+}
+
+// closeTargets dead-letters deferred targets that have nothing left to deliver to
+func (d *Dispatcher) closeTargets(targets []models.NotificationTarget, reason error) {
+	for _, target := range targets {
+		errText := reason.Error()
+		target.Status = models.TargetStatusDeadLettered
+		target.NextAttemptAt = nil
+		target.LastError = &errText
+		if err := d.targetRepo.Save(&target); err != nil {
+			log.Printf("Failed to save notification target for reminder %s device %s: %v", target.ReminderID, target.DeviceID, err)
+		}
+	}
+}
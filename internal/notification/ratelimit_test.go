@@ -0,0 +1,89 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketWithoutLimit(t *testing.T) {
+	if bucket := newTokenBucket(0); bucket != nil {
+		t.Fatalf("newTokenBucket(0) = %+v, want no limit", bucket)
+	}
+
+	var bucket *tokenBucket
+	if wait, err := bucket.Wait(context.Background()); wait != 0 || err != nil {
+		t.Errorf("Wait = %v, %v; want no wait", wait, err)
+	}
+}
+
+func TestTokenBucketAllowsBurstThenThrottles(t *testing.T) {
+	bucket := newTokenBucket(100)
+
+	for i := 0; i < 100; i++ {
+		if wait, err := bucket.Wait(context.Background()); wait != 0 || err != nil {
+			t.Fatalf("send %d waited %v (%v), want the burst to pass straight through", i, wait, err)
+		}
+	}
+
+	start := time.Now()
+	wait, err := bucket.Wait(context.Background())
+	if err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if wait <= 0 || wait > 10*time.Millisecond {
+		t.Errorf("waited %v after the burst, want up to one token's worth (10ms)", wait)
+	}
+	if elapsed := time.Since(start); elapsed < wait {
+		t.Errorf("returned after %v, want it to block for %v", elapsed, wait)
+	}
+}
+
+func TestTokenBucketRefillsUpToBurst(t *testing.T) {
+	bucket := newTokenBucket(10)
+	for i := 0; i < 10; i++ {
+		bucket.Wait(context.Background())
+	}
+
+	// Idle for long enough to refill many times over
+	bucket.mu.Lock()
+	bucket.last = bucket.last.Add(-time.Minute)
+	bucket.mu.Unlock()
+
+	for i := 0; i < 10; i++ {
+		if wait, _ := bucket.Wait(context.Background()); wait != 0 {
+			t.Fatalf("send %d waited %v, want a refilled burst", i, wait)
+		}
+	}
+	bucket.mu.Lock()
+	tokens := bucket.tokens
+	bucket.mu.Unlock()
+	if tokens >= 0.5 {
+		t.Errorf("%.1f tokens left after the burst, want refills capped at the burst size", tokens)
+	}
+}
+
+func TestTokenBucketWaitStopsWhenContextEnds(t *testing.T) {
+	bucket := newTokenBucket(1)
+	bucket.Wait(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	wait, err := bucket.Wait(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || wait != 0 {
+		t.Fatalf("Wait = %v, %v; want %v", wait, err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("returned after %v, want it to stop when the context ended", elapsed)
+	}
+
+	// The abandoned reservation is given back, so the next waiter is not pushed back
+	bucket.mu.Lock()
+	tokens := bucket.tokens
+	bucket.mu.Unlock()
+	if tokens < -0.5 {
+		t.Errorf("%.2f tokens after the cancelled wait, want the reservation returned", tokens)
+	}
+}
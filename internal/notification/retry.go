@@ -0,0 +1,188 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/user/remind-me/backend/internal/models"
+)
+
+// Retry policy for reminder pushes. Delays double from retryBaseDelay up to
+// retryMaxDelay unless the provider asks for a longer wait with Retry-After.
+const (
+	MaxSendAttempts = 5
+	retryBaseDelay  = 30 * time.Second
+	retryMaxDelay   = 15 * time.Minute
+)
+
+// ErrNoProvider is returned for devices on a platform with no push provider configured.
+// Retrying can't help until the server is reconfigured, so such sends are dead-lettered.
+var ErrNoProvider = errors.New("no push provider configured")
+
+// IsRetryable reports whether a failed send may succeed if tried again later.
+// Provider errors are retryable when rate limited or on a server error; errors that
+// never reached the provider (timeouts, connection failures) are too, unless no
+// provider is configured for the platform at all.
+func IsRetryable(err error) bool {
+	if errors.Is(err, ErrNoProvider) {
+		return false
+	}
+	var tempErr interface{ Temporary() bool }
+	if errors.As(err, &tempErr) {
+		return tempErr.Temporary()
+	}
+	return !IsInvalidToken(err)
+}
+
+// retryDelay returns how long to wait before the next attempt after a failed one
+func retryDelay(attempts int, err error) time.Duration {
+	delay := retryMaxDelay
+	if shift := attempts - 1; shift < 16 {
+		delay = min(retryBaseDelay<<shift, retryMaxDelay)
+	}
+
+	var retryErr interface{ RetryDelay() time.Duration }
+	if errors.As(err, &retryErr) {
+		delay = max(delay, retryErr.RetryDelay())
+	}
+	return delay
+}
+
+// SendReminder pushes one occurrence of a due reminder to each of the user's devices
+// that hasn't received it yet. Failed devices are retried with backoff on later calls
+// and dead-lettered after MaxSendAttempts or a permanent error. Unless the payload is an
+// alarm, devices in quiet hours are deferred to the summary sent when their quiet hours
+// end. It returns when the next retry is due, or nil once every device has been
+// delivered to, deferred or dead-lettered and the reminder can be marked notified.
+// The push carries the user's badge count as of the send.
+func (d *Dispatcher) SendReminder(ctx context.Context, user *models.User, dueAt time.Time, payload Payload) (*time.Time, error) {
+	devices, err := d.deviceRepo.ListByUser(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := d.targetRepo.ListByOccurrence(payload.ReminderID, dueAt)
+	if err != nil {
+		return nil, err
+	}
+	payload.Badge = d.badgeCount(user.ID)
+
+	byDevice := make(map[uuid.UUID]models.NotificationTarget, len(targets))
+	for _, target := range targets {
+		byDevice[target.DeviceID] = target
+	}
+
+	// A push token can be registered more than once; a token that any of its devices
+	// has finished with is not sent to again
+	seen := make(map[string]bool, len(devices))
+	for _, device := range devices {
+		if target, ok := byDevice[device.ID]; ok && target.Status != models.TargetStatusPending {
+			seen[device.PushToken] = true
+		}
+	}
+
+	now := time.Now().In(user.Location())
+	var retryAt *time.Time
+	var mu sync.Mutex
+
+	// scheduleRetry keeps the earliest retry among the pending devices
+	scheduleRetry := func(at time.Time) {
+		mu.Lock()
+		defer mu.Unlock()
+		if retryAt == nil || at.Before(*retryAt) {
+			retryAt = &at
+		}
+	}
+
+	group := d.newSendGroup()
+	for _, device := range devices {
+		if seen[device.PushToken] {
+			continue
+		}
+		seen[device.PushToken] = true
+
+		target, ok := byDevice[device.ID]
+		if !ok {
+			target = models.NotificationTarget{
+				ReminderID: payload.ReminderID,
+				DeviceID:   device.ID,
+				DueAt:      dueAt,
+				Status:     models.TargetStatusPending,
+			}
+		}
+		if target.NextAttemptAt != nil && now.Before(*target.NextAttemptAt) {
+			scheduleRetry(*target.NextAttemptAt)
+			continue
+		}
+		if quiet := user.QuietHoursFor(&device); quiet != nil && !payload.IsAlarm && quiet.Contains(now) {
+			deferTarget(&target, quiet.EndAfter(now))
+			if err := d.targetRepo.Save(&target); err != nil {
+				log.Printf("Failed to defer notification target for reminder %s device %s: %v", target.ReminderID, target.DeviceID, err)
+			}
+			continue
+		}
+
+		group.Go(ctx, func() error {
+			sendErr := d.attempt(ctx, device, payload)
+			if sendErr != nil && d.handleSendError(device.Platform, device.PushToken, sendErr) == nil {
+				// The device was pruned for an invalid token, so there is nothing left to deliver to
+				return nil
+			}
+
+			if !advanceTarget(&target, sendErr) {
+				scheduleRetry(*target.NextAttemptAt)
+			}
+			if err := d.targetRepo.Save(&target); err != nil {
+				log.Printf("Failed to save notification target for reminder %s device %s: %v", target.ReminderID, target.DeviceID, err)
+			}
+			return nil
+		})
+	}
+
+	// Sends only fail the group when ctx ended before they got a worker; those devices
+	// haven't been attempted, so the reminder is due again right away
+	if err := group.Wait(); err != nil {
+		scheduleRetry(now)
+	}
+	return retryAt, nil
+}
+
+// deferTarget holds target for the quiet-hours summary due at until. The summary gets a
+// fresh set of attempts.
+func deferTarget(target *models.NotificationTarget, until time.Time) {
+	target.Status = models.TargetStatusDeferred
+	target.Attempts = 0
+	target.NextAttemptAt = &until
+	target.LastError = nil
+	log.Printf("Deferred reminder %s for device %s until quiet hours end at %s", target.ReminderID, target.DeviceID, until.Format(time.RFC3339))
+}
+
+// advanceTarget applies the outcome of an attempt to target and reports whether the
+// target is finished
+func advanceTarget(target *models.NotificationTarget, sendErr error) bool {
+	target.Attempts++
+	if sendErr == nil {
+		target.Status = models.TargetStatusDelivered
+		target.NextAttemptAt = nil
+		target.LastError = nil
+		return true
+	}
+
+	errText := sendErr.Error()
+	target.LastError = &errText
+
+	if !IsRetryable(sendErr) || target.Attempts >= MaxSendAttempts {
+		target.Status = models.TargetStatusDeadLettered
+		target.NextAttemptAt = nil
+		log.Printf("Dead-lettered reminder %s for device %s after %d attempts: %v", target.ReminderID, target.DeviceID, target.Attempts, sendErr)
+		return true
+	}
+
+	next := time.Now().Add(retryDelay(target.Attempts, sendErr))
+	target.NextAttemptAt = &next
+	log.Printf("Retrying reminder %s for device %s at %s (attempt %d): %v", target.ReminderID, target.DeviceID, next.Format(time.RFC3339), target.Attempts, sendErr)
+	return false
+}
@@ -0,0 +1,24 @@
+// Package retryafter reads the Retry-After header that APNs and FCM send with
+// rate-limit and server errors.
+package retryafter
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Parse reads a Retry-After header given in seconds or as an HTTP date, returning
+// zero if it is empty, malformed or already past
+func Parse(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return 0
+}
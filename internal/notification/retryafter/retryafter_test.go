@@ -0,0 +1,32 @@
+package retryafter
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		min   time.Duration
+		max   time.Duration
+	}{
+		{name: "empty", value: ""},
+		{name: "seconds", value: "120", min: 2 * time.Minute, max: 2 * time.Minute},
+		{name: "zero seconds", value: "0"},
+		{name: "negative seconds", value: "-5"},
+		{name: "malformed", value: "soon"},
+		{name: "future date", value: time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), min: 59 * time.Minute, max: time.Hour},
+		{name: "past date", value: time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.value); got < tt.min || got > tt.max {
+				t.Errorf("Parse(%q) = %s, want between %s and %s", tt.value, got, tt.min, tt.max)
+			}
+		})
+	}
+}
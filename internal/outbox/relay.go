@@ -0,0 +1,216 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/user/remind-me/backend/internal/models"
+)
+
+const (
+	batchSize          = 100
+	claimLease         = time.Minute
+	deliverTimeout     = 15 * time.Second
+	deliverConcurrency = 16 // Users whose events are delivered at once
+	maxAttempts        = 10
+	maxBackoff         = 10 * time.Minute
+	retention          = 7 * 24 * time.Hour
+	purgeInterval      = time.Hour
+)
+
+// Store holds the outbox events the relay claims and settles
+type Store interface {
+	ClaimDue(limit int, lease time.Duration) ([]models.OutboxEvent, error)
+	Release(ids []int64) error
+	MarkDone(id int64) error
+	MarkFailed(id int64, attempts int, retryAt time.Time, lastError string) error
+	MarkDead(id int64, attempts int, lastError string) error
+	DeleteProcessedBefore(before time.Time) (int64, error)
+}
+
+// HandlerFunc delivers one outbox event. Returning an error schedules a retry.
+type HandlerFunc func(ctx context.Context, event *models.OutboxEvent) error
+
+// Relay delivers outbox events to their handlers in the background
+type Relay struct {
+	outboxRepo   Store
+	pollInterval time.Duration
+	handlers     map[models.OutboxKind]HandlerFunc
+	handlersMux  sync.RWMutex
+	wake         chan struct{}
+
+	claimLease     time.Duration
+	deliverTimeout time.Duration
+}
+
+// NewRelay creates a new outbox relay
+func NewRelay(outboxRepo Store, pollInterval time.Duration) *Relay {
+	return &Relay{
+		outboxRepo:     outboxRepo,
+		pollInterval:   pollInterval,
+		handlers:       make(map[models.OutboxKind]HandlerFunc),
+		wake:           make(chan struct{}, 1),
+		claimLease:     claimLease,
+		deliverTimeout: deliverTimeout,
+	}
+}
+
+// Handle registers the handler for an event kind
+func (r *Relay) Handle(kind models.OutboxKind, handler HandlerFunc) {
+	r.handlersMux.Lock()
+	defer r.handlersMux.Unlock()
+
+	r.handlers[kind] = handler
+}
+
+// Wake triggers a delivery pass without waiting for the next poll.
+// It never blocks, so it is safe to call after every commit.
+func (r *Relay) Wake() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run delivers events until ctx is cancelled
+func (r *Relay) Run(ctx context.Context) {
+	log.Printf("[OutboxRelay] Started (poll interval %s)", r.pollInterval)
+
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	lastPurge := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("[OutboxRelay] Stopped")
+			return
+		case <-ticker.C:
+		case <-r.wake:
+		}
+
+		// Keep draining while full batches come back
+		for r.deliverBatch(ctx) == batchSize {
+			if ctx.Err() != nil {
+				break
+			}
+		}
+
+		if time.Since(lastPurge) > purgeInterval {
+			lastPurge = time.Now()
+			if count, err := r.outboxRepo.DeleteProcessedBefore(time.Now().Add(-retention)); err != nil {
+				log.Printf("[OutboxRelay] Error purging processed events: %v", err)
+			} else if count > 0 {
+				log.Printf("[OutboxRelay] Purged %d processed events", count)
+			}
+		}
+	}
+}
+
+// deliverBatch claims and delivers one batch, returning how many events were claimed.
+// Each user's events are delivered in order, deliverConcurrency users at a time. A
+// delivery is only started while it can finish before the lease runs out, so no other
+// relay claims an event still in flight; the rest are released for the next batch.
+func (r *Relay) deliverBatch(ctx context.Context) int {
+	leaseEnd := time.Now().Add(r.claimLease)
+	events, err := r.outboxRepo.ClaimDue(batchSize, r.claimLease)
+	if err != nil {
+		log.Printf("[OutboxRelay] Error claiming events: %v", err)
+		return 0
+	}
+
+	var users []uuid.UUID
+	byUser := make(map[uuid.UUID][]*models.OutboxEvent)
+	for i := range events {
+		event := &events[i]
+		if _, ok := byUser[event.UserID]; !ok {
+			users = append(users, event.UserID)
+		}
+		byUser[event.UserID] = append(byUser[event.UserID], event)
+	}
+
+	var unstarted []int64
+	var unstartedMux sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, deliverConcurrency)
+	for _, userID := range users {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			for i, event := range byUser[userID] {
+				if ctx.Err() != nil || time.Until(leaseEnd) < r.deliverTimeout {
+					unstartedMux.Lock()
+					for _, skipped := range byUser[userID][i:] {
+						unstarted = append(unstarted, skipped.ID)
+					}
+					unstartedMux.Unlock()
+					return
+				}
+				r.deliver(ctx, event)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(unstarted) > 0 {
+		if err := r.outboxRepo.Release(unstarted); err != nil {
+			// They are claimed again once the lease expires
+			log.Printf("[OutboxRelay] Error releasing %d undelivered events: %v", len(unstarted), err)
+		}
+	}
+	return len(events)
+}
+
+func (r *Relay) deliver(ctx context.Context, event *models.OutboxEvent) {
+	r.handlersMux.RLock()
+	handler, ok := r.handlers[event.Kind]
+	r.handlersMux.RUnlock()
+
+	var err error
+	if !ok {
+		err = fmt.Errorf("no handler registered for kind %q", event.Kind)
+	} else {
+		deliverCtx, cancel := context.WithTimeout(ctx, r.deliverTimeout)
+		err = handler(deliverCtx, event)
+		cancel()
+	}
+
+	if err == nil {
+		if markErr := r.outboxRepo.MarkDone(event.ID); markErr != nil {
+			log.Printf("[OutboxRelay] Error marking event %d done: %v", event.ID, markErr)
+		}
+		return
+	}
+
+	attempts := event.Attempts + 1
+	if !ok || attempts >= maxAttempts {
+		log.Printf("[OutboxRelay] Giving up on event %d (%s) after %d attempts: %v", event.ID, event.Kind, attempts, err)
+		if markErr := r.outboxRepo.MarkDead(event.ID, attempts, err.Error()); markErr != nil {
+			log.Printf("[OutboxRelay] Error marking event %d dead: %v", event.ID, markErr)
+		}
+		return
+	}
+
+	retryAt := time.Now().Add(backoff(attempts))
+	log.Printf("[OutboxRelay] Event %d (%s) failed, retrying at %s: %v", event.ID, event.Kind, retryAt.Format(time.RFC3339), err)
+	if markErr := r.outboxRepo.MarkFailed(event.ID, attempts, retryAt, err.Error()); markErr != nil {
+		log.Printf("[OutboxRelay] Error recording failure for event %d: %v", event.ID, markErr)
+	}
+}
+
+// backoff doubles from one second up to maxBackoff
+func backoff(attempts int) time.Duration {
+	d := time.Second << (attempts - 1)
+	if d <= 0 || d > maxBackoff {
+		return maxBackoff
+	}
+	return d
+}
@@ -0,0 +1,240 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/user/remind-me/backend/internal/models"
+)
+
+// memoryStore is an in-memory Store with the same lease semantics as the repository
+type memoryStore struct {
+	mu     sync.Mutex
+	events []models.OutboxEvent
+}
+
+func (s *memoryStore) add(userID uuid.UUID, count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for range count {
+		s.events = append(s.events, models.OutboxEvent{
+			ID:          int64(len(s.events) + 1),
+			UserID:      userID,
+			Kind:        models.OutboxKindReminderChanged,
+			Status:      models.OutboxStatusPending,
+			AvailableAt: time.Now(),
+		})
+	}
+}
+
+func (s *memoryStore) ClaimDue(limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var claimed []models.OutboxEvent
+	now := time.Now()
+	for i := range s.events {
+		event := &s.events[i]
+		if len(claimed) == limit || event.Status != models.OutboxStatusPending || event.AvailableAt.After(now) {
+			continue
+		}
+		event.AvailableAt = now.Add(lease)
+		claimed = append(claimed, *event)
+	}
+	return claimed, nil
+}
+
+func (s *memoryStore) Release(ids []int64) error {
+	return s.update(ids, func(event *models.OutboxEvent) {
+		if event.Status == models.OutboxStatusPending {
+			event.AvailableAt = time.Now()
+		}
+	})
+}
+
+func (s *memoryStore) MarkDone(id int64) error {
+	return s.update([]int64{id}, func(event *models.OutboxEvent) {
+		event.Status = models.OutboxStatusDone
+	})
+}
+
+func (s *memoryStore) MarkFailed(id int64, attempts int, retryAt time.Time, lastError string) error {
+	return s.update([]int64{id}, func(event *models.OutboxEvent) {
+		event.Attempts, event.AvailableAt, event.LastError = attempts, retryAt, &lastError
+	})
+}
+
+func (s *memoryStore) MarkDead(id int64, attempts int, lastError string) error {
+	return s.update([]int64{id}, func(event *models.OutboxEvent) {
+		event.Status, event.Attempts, event.LastError = models.OutboxStatusDead, attempts, &lastError
+	})
+}
+
+func (s *memoryStore) DeleteProcessedBefore(before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (s *memoryStore) update(ids []int64, apply func(event *models.OutboxEvent)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.events {
+		if slices.Contains(ids, s.events[i].ID) {
+			apply(&s.events[i])
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, event := range s.events {
+		if event.Status == models.OutboxStatusPending {
+			count++
+		}
+	}
+	return count
+}
+
+// deliveryLog records deliveries made by every relay sharing it
+type deliveryLog struct {
+	mu        sync.Mutex
+	inFlight  map[int64]bool
+	delivered map[int64]int
+	order     map[uuid.UUID][]int64
+	overlaps  []int64
+}
+
+func newDeliveryLog() *deliveryLog {
+	return &deliveryLog{inFlight: make(map[int64]bool), delivered: make(map[int64]int), order: make(map[uuid.UUID][]int64)}
+}
+
+func (l *deliveryLog) handler(delay time.Duration) HandlerFunc {
+	return func(ctx context.Context, event *models.OutboxEvent) error {
+		l.mu.Lock()
+		if l.inFlight[event.ID] {
+			l.overlaps = append(l.overlaps, event.ID)
+		}
+		l.inFlight[event.ID] = true
+		l.mu.Unlock()
+
+		time.Sleep(delay)
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.inFlight, event.ID)
+		l.delivered[event.ID]++
+		l.order[event.UserID] = append(l.order[event.UserID], event.ID)
+		return nil
+	}
+}
+
+func newTestRelay(store Store, lease, timeout time.Duration) *Relay {
+	r := NewRelay(store, 5*time.Millisecond)
+	r.claimLease, r.deliverTimeout = lease, timeout
+	return r
+}
+
+func TestSlowBatchIsNotReclaimed(t *testing.T) {
+	store := &memoryStore{}
+	busy, quiet := uuid.New(), uuid.New()
+	store.add(busy, 20) // Sequential for one user: far longer than one lease
+	store.add(quiet, 5)
+
+	deliveries := newDeliveryLog()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for range 2 {
+		relay := newTestRelay(store, 200*time.Millisecond, 50*time.Millisecond)
+		relay.Handle(models.OutboxKindReminderChanged, deliveries.handler(20*time.Millisecond))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			relay.Run(ctx)
+		}()
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for store.pending() > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	wg.Wait()
+
+	if pending := store.pending(); pending > 0 {
+		t.Fatalf("%d events still pending", pending)
+	}
+	if len(deliveries.overlaps) > 0 {
+		t.Errorf("events %v were delivered by two relays at once", deliveries.overlaps)
+	}
+	for id, count := range deliveries.delivered {
+		if count != 1 {
+			t.Errorf("event %d delivered %d times, want 1", id, count)
+		}
+	}
+	for userID, order := range deliveries.order {
+		if !slices.IsSorted(order) {
+			t.Errorf("events for user %s delivered out of order: %v", userID, order)
+		}
+	}
+}
+
+func TestDeliverBatchReleasesEventsNotStarted(t *testing.T) {
+	store := &memoryStore{}
+	store.add(uuid.New(), 10)
+
+	deliveries := newDeliveryLog()
+	relay := newTestRelay(store, 100*time.Millisecond, 50*time.Millisecond)
+	relay.Handle(models.OutboxKindReminderChanged, deliveries.handler(30*time.Millisecond))
+
+	if claimed := relay.deliverBatch(context.Background()); claimed != 10 {
+		t.Fatalf("claimed %d events, want 10", claimed)
+	}
+	delivered := len(deliveries.delivered)
+	if delivered == 0 || delivered == 10 {
+		t.Fatalf("delivered %d events, want some but not all within the lease", delivered)
+	}
+
+	// The rest can be claimed straight away, without waiting for the lease
+	released, _ := store.ClaimDue(batchSize, time.Minute)
+	if len(released) != 10-delivered {
+		t.Errorf("reclaimed %d events, want the %d not delivered", len(released), 10-delivered)
+	}
+}
+
+func TestDeliverRetriesAndGivesUp(t *testing.T) {
+	store := &memoryStore{}
+	store.add(uuid.New(), 2)
+	store.events[1].Kind = "unknown"
+	store.events[0].Attempts = maxAttempts - 2
+
+	relay := newTestRelay(store, time.Minute, time.Second)
+	relay.Handle(models.OutboxKindReminderChanged, func(ctx context.Context, event *models.OutboxEvent) error {
+		return errors.New("subscriber unavailable")
+	})
+
+	relay.deliverBatch(context.Background())
+	if event := store.events[0]; event.Status != models.OutboxStatusPending || event.Attempts != maxAttempts-1 || !event.AvailableAt.After(time.Now()) {
+		t.Errorf("failed event = %+v, want a retry scheduled", event)
+	}
+	if event := store.events[1]; event.Status != models.OutboxStatusDead {
+		t.Errorf("event without handler has status %s, want dead", event.Status)
+	}
+
+	store.events[0].AvailableAt = time.Now()
+	relay.deliverBatch(context.Background())
+	if event := store.events[0]; event.Status != models.OutboxStatusDead || event.Attempts != maxAttempts {
+		t.Errorf("event = %+v, want dead after %d attempts", event, maxAttempts)
+	}
+}
@@ -0,0 +1,50 @@
+package pubsub
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// DeliverFunc hands a received event to the local subscribers of a user's topic
+type DeliverFunc func(userID uuid.UUID, topic Topic, event interface{})
+
+// Backend carries hub events between instances
+type Backend interface {
+	// Publish sends an event on a user's topic to every instance, including this one
+	Publish(userID uuid.UUID, topic Topic, event interface{}) error
+	// Start begins passing received events to deliver until ctx is cancelled. It returns
+	// an error if the backend cannot start receiving, e.g. its first connection failed.
+	Start(ctx context.Context, deliver DeliverFunc) error
+}
+
+// MemoryBackend delivers events within the current process only
+type MemoryBackend struct {
+	deliver DeliverFunc
+	mu      sync.RWMutex
+}
+
+// NewMemoryBackend creates a new in-process backend
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{}
+}
+
+func (b *MemoryBackend) Publish(userID uuid.UUID, topic Topic, event interface{}) error {
+	b.mu.RLock()
+	deliver := b.deliver
+	b.mu.RUnlock()
+
+	if deliver != nil {
+		deliver(userID, topic, event)
+	}
+	return nil
+}
+
+func (b *MemoryBackend) Start(ctx context.Context, deliver DeliverFunc) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.deliver = deliver
+	return nil
+}
@@ -0,0 +1,65 @@
+package pubsub
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Events crossing instances are serialized, so backends need to know how to rebuild them.
+var (
+	eventTypes    = make(map[string]reflect.Type)
+	eventTypesMux sync.RWMutex
+)
+
+// RegisterEvent makes an event type transportable by backends that serialize events.
+// Pass a pointer to a zero value, e.g. RegisterEvent(&model.ReminderChangeEvent{}).
+func RegisterEvent(prototype interface{}) {
+	t := reflect.TypeOf(prototype)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	eventTypesMux.Lock()
+	defer eventTypesMux.Unlock()
+
+	eventTypes[t.Name()] = t
+}
+
+// encodeEvent returns the registered type name and JSON body of an event
+func encodeEvent(event interface{}) (string, []byte, error) {
+	t := reflect.TypeOf(event)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	eventTypesMux.RLock()
+	registered, ok := eventTypes[t.Name()]
+	eventTypesMux.RUnlock()
+	if !ok || registered != t {
+		return "", nil, fmt.Errorf("event type %s is not registered", t)
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return "", nil, err
+	}
+	return t.Name(), data, nil
+}
+
+// decodeEvent rebuilds an event as a pointer to its registered type
+func decodeEvent(name string, data []byte) (interface{}, error) {
+	eventTypesMux.RLock()
+	t, ok := eventTypes[name]
+	eventTypesMux.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown event type %q", name)
+	}
+
+	event := reflect.New(t).Interface()
+	if err := json.Unmarshal(data, event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
@@ -1,62 +1,182 @@
 package pubsub
 
 import (
+	"context"
+	"log"
 	"sync"
+	"sync/atomic"
 
 	"github.com/google/uuid"
 )
 
-// Hub manages pub/sub for GraphQL subscriptions
+// Topic identifies a stream of events for a user
+type Topic string
+
+const (
+	TopicReminders     Topic = "reminders"
+	TopicReminderLists Topic = "reminder_lists"
+	TopicUser          Topic = "user"
+	TopicDevices       Topic = "devices"
+	TopicNotifications Topic = "notifications"
+)
+
+// DefaultQueueSize is the number of undelivered events buffered per subscriber
+const DefaultQueueSize = 64
+
+// Subscription receives a user's events for a set of topics through a bounded queue.
+// If the consumer falls behind, further events are dropped and Overflow fires so the
+// consumer can tell its client to resync instead of losing events silently.
+type Subscription struct {
+	userID   uuid.UUID
+	topics   map[Topic]bool
+	events   chan interface{}
+	overflow chan struct{}
+	dropped  atomic.Uint64
+}
+
+// Events returns the subscription's event queue
+func (s *Subscription) Events() <-chan interface{} {
+	return s.events
+}
+
+// Overflow signals that events were dropped since the last signal was received
+func (s *Subscription) Overflow() <-chan struct{} {
+	return s.overflow
+}
+
+// Dropped returns how many events this subscription has dropped in total
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Stats are counters describing hub activity since startup
+type Stats struct {
+	Subscribers int    `json:"subscribers"`
+	Published   uint64 `json:"published"`
+	Delivered   uint64 `json:"delivered"`
+	Dropped     uint64 `json:"dropped"`
+}
+
+// Hub manages pub/sub for GraphQL subscriptions.
+// Subscriptions are always local to this instance; the backend decides how published
+// events reach the hubs of other instances.
 type Hub struct {
-	subscriptions    map[uuid.UUID][]chan interface{}
+	subscriptions    map[uuid.UUID][]*Subscription
 	subscriptionsMux sync.RWMutex
+	backend          Backend
+	queueSize        int
+
+	published atomic.Uint64
+	delivered atomic.Uint64
+	dropped   atomic.Uint64
 }
 
-// NewHub creates a new Hub
+// NewHub creates a new Hub that only delivers within this process
 func NewHub() *Hub {
+	h := NewHubWithBackend(NewMemoryBackend())
+	_ = h.Start(context.Background())
+	return h
+}
+
+// NewHubWithBackend creates a Hub that publishes through the given backend.
+// Start must be called before events are delivered.
+func NewHubWithBackend(backend Backend) *Hub {
 	return &Hub{
-		subscriptions: make(map[uuid.UUID][]chan interface{}),
+		subscriptions: make(map[uuid.UUID][]*Subscription),
+		backend:       backend,
+		queueSize:     DefaultQueueSize,
 	}
 }
 
-// RegisterSubscription registers a subscription channel for a user
-func (h *Hub) RegisterSubscription(userID uuid.UUID, ch chan interface{}) {
+// Start begins receiving events from the backend until ctx is cancelled. It returns
+// the backend's error if it cannot start receiving.
+func (h *Hub) Start(ctx context.Context) error {
+	return h.backend.Start(ctx, h.deliverLocal)
+}
+
+// Subscribe registers a subscription for a user's events on the given topics
+func (h *Hub) Subscribe(userID uuid.UUID, topics ...Topic) *Subscription {
+	sub := &Subscription{
+		userID:   userID,
+		topics:   make(map[Topic]bool, len(topics)),
+		events:   make(chan interface{}, h.queueSize),
+		overflow: make(chan struct{}, 1),
+	}
+	for _, topic := range topics {
+		sub.topics[topic] = true
+	}
+
 	h.subscriptionsMux.Lock()
 	defer h.subscriptionsMux.Unlock()
 
-	h.subscriptions[userID] = append(h.subscriptions[userID], ch)
+	h.subscriptions[userID] = append(h.subscriptions[userID], sub)
+	return sub
 }
 
-// UnregisterSubscription removes a subscription channel for a user
-func (h *Hub) UnregisterSubscription(userID uuid.UUID, ch chan interface{}) {
+// Unsubscribe removes a subscription
+func (h *Hub) Unsubscribe(sub *Subscription) {
 	h.subscriptionsMux.Lock()
 	defer h.subscriptionsMux.Unlock()
 
-	subs := h.subscriptions[userID]
-	for i, sub := range subs {
-		if sub == ch {
-			h.subscriptions[userID] = append(subs[:i], subs[i+1:]...)
+	subs := h.subscriptions[sub.userID]
+	for i, s := range subs {
+		if s == sub {
+			h.subscriptions[sub.userID] = append(subs[:i], subs[i+1:]...)
 			break
 		}
 	}
 
 	// Clean up empty subscription list
-	if len(h.subscriptions[userID]) == 0 {
-		delete(h.subscriptions, userID)
+	if len(h.subscriptions[sub.userID]) == 0 {
+		delete(h.subscriptions, sub.userID)
+	}
+}
+
+// Publish sends an event on a topic to the user's subscribers on every instance
+func (h *Hub) Publish(userID uuid.UUID, topic Topic, event interface{}) {
+	h.published.Add(1)
+	if err := h.backend.Publish(userID, topic, event); err != nil {
+		log.Printf("[Hub] Failed to publish %s event for user %s: %v", topic, userID, err)
 	}
 }
 
-// BroadcastToUser sends an event to all subscribers for a user
-func (h *Hub) BroadcastToUser(userID uuid.UUID, event interface{}) {
+// Stats returns a snapshot of the hub counters
+func (h *Hub) Stats() Stats {
+	h.subscriptionsMux.RLock()
+	subscribers := 0
+	for _, subs := range h.subscriptions {
+		subscribers += len(subs)
+	}
+	h.subscriptionsMux.RUnlock()
+
+	return Stats{
+		Subscribers: subscribers,
+		Published:   h.published.Load(),
+		Delivered:   h.delivered.Load(),
+		Dropped:     h.dropped.Load(),
+	}
+}
+
+// deliverLocal sends an event to the subscribers of the topic connected to this instance
+func (h *Hub) deliverLocal(userID uuid.UUID, topic Topic, event interface{}) {
 	h.subscriptionsMux.RLock()
 	defer h.subscriptionsMux.RUnlock()
 
-	if subs, ok := h.subscriptions[userID]; ok {
-		for _, ch := range subs {
+	for _, sub := range h.subscriptions[userID] {
+		if !sub.topics[topic] {
+			continue
+		}
+
+		select {
+		case sub.events <- event:
+			h.delivered.Add(1)
+		default:
+			// Queue full: drop and tell the consumer it has missed events
+			sub.dropped.Add(1)
+			h.dropped.Add(1)
 			select {
-			case ch <- event:
+			case sub.overflow <- struct{}{}:
 			default:
-				// Channel full, skip
 			}
 		}
 	}
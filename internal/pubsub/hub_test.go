@@ -0,0 +1,138 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type testEvent struct {
+	Value string
+}
+
+func init() {
+	RegisterEvent(&testEvent{})
+}
+
+// receive returns the next event on sub, failing if none arrives
+func receive(t *testing.T, sub *Subscription) interface{} {
+	t.Helper()
+	select {
+	case event := <-sub.Events():
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no event received")
+		return nil
+	}
+}
+
+func expectNoEvent(t *testing.T, sub *Subscription) {
+	t.Helper()
+	select {
+	case event := <-sub.Events():
+		t.Fatalf("unexpected event %+v", event)
+	default:
+	}
+}
+
+func TestHubDeliversOnlySubscribedTopics(t *testing.T) {
+	hub := NewHub()
+	userID := uuid.New()
+	reminders := hub.Subscribe(userID, TopicReminders)
+	both := hub.Subscribe(userID, TopicReminders, TopicUser)
+	other := hub.Subscribe(uuid.New(), TopicReminders)
+
+	hub.Publish(userID, TopicReminders, "reminder")
+	hub.Publish(userID, TopicUser, "user")
+
+	if event := receive(t, reminders); event != "reminder" {
+		t.Errorf("reminders subscription got %v, want the reminder event", event)
+	}
+	expectNoEvent(t, reminders)
+	if first, second := receive(t, both), receive(t, both); first != "reminder" || second != "user" {
+		t.Errorf("subscription to both topics got %v and %v, want both events in order", first, second)
+	}
+	expectNoEvent(t, other)
+
+	if stats := hub.Stats(); stats.Subscribers != 3 || stats.Published != 2 || stats.Delivered != 3 {
+		t.Errorf("stats = %+v, want 3 subscribers, 2 published and 3 delivered", stats)
+	}
+}
+
+func TestHubUnsubscribeStopsDelivery(t *testing.T) {
+	hub := NewHub()
+	userID := uuid.New()
+	sub := hub.Subscribe(userID, TopicReminders)
+
+	hub.Unsubscribe(sub)
+	hub.Publish(userID, TopicReminders, "reminder")
+
+	expectNoEvent(t, sub)
+	if stats := hub.Stats(); stats.Subscribers != 0 {
+		t.Errorf("subscribers = %d after unsubscribing, want 0", stats.Subscribers)
+	}
+}
+
+func TestHubSignalsOverflowWhenQueueIsFull(t *testing.T) {
+	hub := NewHub()
+	hub.queueSize = 2
+	userID := uuid.New()
+	slow := hub.Subscribe(userID, TopicReminders)
+	fast := hub.Subscribe(userID, TopicReminders)
+
+	for i := 0; i < 2; i++ {
+		hub.Publish(userID, TopicReminders, i)
+		receive(t, fast)
+	}
+	select {
+	case <-slow.Overflow():
+		t.Fatalf("overflow signalled before the queue was full")
+	default:
+	}
+
+	hub.Publish(userID, TopicReminders, 2)
+	hub.Publish(userID, TopicReminders, 3)
+
+	select {
+	case <-slow.Overflow():
+	default:
+		t.Fatalf("no overflow signal after the queue filled up")
+	}
+	select {
+	case <-slow.Overflow():
+		t.Errorf("overflow signalled once per dropped event, want a single pending signal")
+	default:
+	}
+	if slow.Dropped() != 2 || fast.Dropped() != 0 {
+		t.Errorf("dropped %d and %d events, want 2 for the slow subscriber only", slow.Dropped(), fast.Dropped())
+	}
+	if stats := hub.Stats(); stats.Dropped != 2 {
+		t.Errorf("hub dropped %d events, want 2", stats.Dropped)
+	}
+
+	// The queued events are still delivered in order
+	if first, second := receive(t, slow), receive(t, slow); first != 0 || second != 1 {
+		t.Errorf("slow subscriber got %v and %v, want the first two events", first, second)
+	}
+}
+
+type failingBackend struct {
+	MemoryBackend
+	err error
+}
+
+func (b *failingBackend) Start(ctx context.Context, deliver DeliverFunc) error {
+	return b.err
+}
+
+func TestHubStartReturnsBackendError(t *testing.T) {
+	backendErr := errors.New("connection refused")
+	hub := NewHubWithBackend(&failingBackend{err: backendErr})
+
+	if err := hub.Start(context.Background()); !errors.Is(err, backendErr) {
+		t.Errorf("Start = %v, want %v", err, backendErr)
+	}
+}
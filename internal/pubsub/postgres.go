@@ -0,0 +1,265 @@
+package pubsub
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/user/remind-me/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	postgresChannel       = "hub_events"
+	postgresRetention     = 10 * time.Minute
+	postgresPurgeInterval = time.Minute
+	postgresMaxBackoff    = 30 * time.Second
+	// postgresCatchUpLookback must stay well below postgresRetention so the messages
+	// a reconnecting listener reads back have not been purged yet
+	postgresCatchUpLookback = time.Minute
+	// postgresDedupeSize bounds how many delivered ids are remembered. It should exceed
+	// the number of messages published within postgresCatchUpLookback.
+	postgresDedupeSize = 10000
+)
+
+// PostgresBackend shares hub events between instances with LISTEN/NOTIFY.
+// Events are stored in pubsub_messages and the notification carries only the row id,
+// so event size is not bound by the NOTIFY payload limit.
+//
+// LISTEN needs a session-level connection: listenURL must not point at a
+// transaction-mode connection pooler.
+type PostgresBackend struct {
+	db       *gorm.DB
+	messages messageStore
+	connect  func(ctx context.Context) (listenConn, error)
+}
+
+// NewPostgresBackend creates a backend that publishes through db and listens on listenURL
+func NewPostgresBackend(db *gorm.DB, listenURL string) *PostgresBackend {
+	return &PostgresBackend{
+		db:       db,
+		messages: gormMessageStore{db: db},
+		connect: func(ctx context.Context) (listenConn, error) {
+			conn, err := pgx.Connect(ctx, listenURL)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := conn.Exec(ctx, "LISTEN "+postgresChannel); err != nil {
+				conn.Close(context.Background())
+				return nil, err
+			}
+			return conn, nil
+		},
+	}
+}
+
+// listenConn is a connection listening on postgresChannel
+type listenConn interface {
+	WaitForNotification(ctx context.Context) (*pgconn.Notification, error)
+	Close(ctx context.Context) error
+}
+
+// messageStore reads and purges stored hub messages
+type messageStore interface {
+	CreatedAfter(t time.Time) ([]models.PubSubMessage, error)
+	FindByID(id int64) (*models.PubSubMessage, error)
+	DeleteBefore(t time.Time) error
+}
+
+type gormMessageStore struct {
+	db *gorm.DB
+}
+
+func (s gormMessageStore) CreatedAfter(t time.Time) ([]models.PubSubMessage, error) {
+	var messages []models.PubSubMessage
+	err := s.db.Where("created_at > ?", t).Order("id ASC").Find(&messages).Error
+	return messages, err
+}
+
+func (s gormMessageStore) FindByID(id int64) (*models.PubSubMessage, error) {
+	var message models.PubSubMessage
+	if err := s.db.Where("id = ?", id).First(&message).Error; err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+func (s gormMessageStore) DeleteBefore(t time.Time) error {
+	return s.db.Where("created_at < ?", t).Delete(&models.PubSubMessage{}).Error
+}
+
+func (b *PostgresBackend) Publish(userID uuid.UUID, topic Topic, event interface{}) error {
+	eventType, data, err := encodeEvent(event)
+	if err != nil {
+		return err
+	}
+
+	// Insert and notify in one statement; the notification is sent when it commits
+	return b.db.Exec(`
+		WITH message AS (
+			INSERT INTO pubsub_messages (user_id, topic, event_type, payload, created_at)
+			VALUES (?, ?, ?, ?, NOW())
+			RETURNING id
+		)
+		SELECT pg_notify(?, message.id::text) FROM message
+	`, userID, string(topic), eventType, string(data), postgresChannel).Error
+}
+
+// Start connects the listener and returns its error if the first connection fails.
+// Later disconnects are retried in the background with backoff.
+func (b *PostgresBackend) Start(ctx context.Context, deliver DeliverFunc) error {
+	conn, err := b.connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Only deliver messages published from about now on
+	go b.listen(ctx, conn, deliver, time.Now())
+	go b.purge(ctx)
+	return nil
+}
+
+// listen delivers notifications from conn, reconnecting whenever the connection fails
+func (b *PostgresBackend) listen(ctx context.Context, conn listenConn, deliver DeliverFunc, since time.Time) {
+	delivered := newRecentIDs(postgresDedupeSize)
+	for conn != nil {
+		err := b.serve(ctx, conn, deliver, since, delivered)
+		conn.Close(context.Background())
+		since = time.Now()
+		if ctx.Err() != nil {
+			return
+		}
+
+		conn = b.reconnect(ctx, err)
+	}
+}
+
+// reconnect connects again with backoff after the listener failed with err. It returns
+// nil once ctx is cancelled.
+func (b *PostgresBackend) reconnect(ctx context.Context, err error) listenConn {
+	backoff := time.Second
+	for {
+		log.Printf("[PostgresBackend] Listener disconnected, reconnecting in %s: %v", backoff, err)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+
+		var conn listenConn
+		if conn, err = b.connect(ctx); err == nil {
+			return conn
+		}
+		backoff = min(backoff*2, postgresMaxBackoff)
+	}
+}
+
+// serve delivers notifications until the connection fails. Message ids are drawn
+// before commit, so they do not arrive in id order and a high-water mark would drop
+// messages that commit late. Instead delivered ids are remembered, and on each connect
+// every message created within postgresCatchUpLookback of since is read back.
+func (b *PostgresBackend) serve(ctx context.Context, conn listenConn, deliver DeliverFunc, since time.Time, delivered *recentIDs) error {
+	// Catch up on messages published while the listener was down. created_at is the
+	// publishing transaction's start time, so look back far enough to cover messages
+	// that committed after the disconnect but were created before it.
+	missed, err := b.messages.CreatedAfter(since.Add(-postgresCatchUpLookback))
+	if err != nil {
+		return err
+	}
+	for i := range missed {
+		b.deliverMessage(&missed[i], deliver, delivered)
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		id, err := strconv.ParseInt(notification.Payload, 10, 64)
+		if err != nil {
+			log.Printf("[PostgresBackend] Ignoring malformed notification %q", notification.Payload)
+			continue
+		}
+		if delivered.contains(id) {
+			continue // Already delivered during catch-up
+		}
+
+		message, err := b.messages.FindByID(id)
+		if err != nil {
+			log.Printf("[PostgresBackend] Failed to load message %d: %v", id, err)
+			continue
+		}
+		b.deliverMessage(message, deliver, delivered)
+	}
+}
+
+func (b *PostgresBackend) deliverMessage(message *models.PubSubMessage, deliver DeliverFunc, delivered *recentIDs) {
+	if !delivered.add(message.ID) {
+		return
+	}
+
+	event, err := decodeEvent(message.EventType, message.Payload)
+	if err != nil {
+		log.Printf("[PostgresBackend] Failed to decode message %d: %v", message.ID, err)
+		return
+	}
+	deliver(message.UserID, Topic(message.Topic), event)
+}
+
+// recentIDs remembers the ids of the most recently delivered messages, forgetting the
+// oldest once full. It is only used by the listener goroutine.
+type recentIDs struct {
+	seen  map[int64]struct{}
+	order []int64 // Ring buffer of the ids in seen, oldest at next once full
+	next  int
+}
+
+func newRecentIDs(size int) *recentIDs {
+	return &recentIDs{
+		seen:  make(map[int64]struct{}, size),
+		order: make([]int64, 0, size),
+	}
+}
+
+func (r *recentIDs) contains(id int64) bool {
+	_, ok := r.seen[id]
+	return ok
+}
+
+// add records id and reports whether it was not already known
+func (r *recentIDs) add(id int64) bool {
+	if r.contains(id) {
+		return false
+	}
+	if len(r.order) < cap(r.order) {
+		r.order = append(r.order, id)
+	} else {
+		delete(r.seen, r.order[r.next])
+		r.order[r.next] = id
+		r.next = (r.next + 1) % len(r.order)
+	}
+	r.seen[id] = struct{}{}
+	return true
+}
+
+// purge removes messages every instance has had time to deliver
+func (b *PostgresBackend) purge(ctx context.Context) {
+	ticker := time.NewTicker(postgresPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := b.messages.DeleteBefore(time.Now().Add(-postgresRetention)); err != nil {
+				log.Printf("[PostgresBackend] Failed to purge messages: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/user/remind-me/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// fakeConn delivers the payloads sent on notifications and fails once it is closed
+type fakeConn struct {
+	notifications chan string
+}
+
+func newFakeConn() *fakeConn {
+	return &fakeConn{notifications: make(chan string, 10)}
+}
+
+func (c *fakeConn) WaitForNotification(ctx context.Context) (*pgconn.Notification, error) {
+	select {
+	case payload, ok := <-c.notifications:
+		if !ok {
+			return nil, errors.New("connection closed")
+		}
+		return &pgconn.Notification{Channel: postgresChannel, Payload: payload}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *fakeConn) Close(ctx context.Context) error { return nil }
+
+type fakeMessages struct {
+	mu       sync.Mutex
+	messages []models.PubSubMessage
+}
+
+func (s *fakeMessages) add(t *testing.T, id int64, userID uuid.UUID, createdAt time.Time) {
+	t.Helper()
+	payload, err := json.Marshal(testEvent{Value: strconv.FormatInt(id, 10)})
+	if err != nil {
+		t.Fatalf("encode event: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, models.PubSubMessage{
+		ID:        id,
+		UserID:    userID,
+		Topic:     string(TopicReminders),
+		EventType: "testEvent",
+		Payload:   payload,
+		CreatedAt: createdAt,
+	})
+}
+
+func (s *fakeMessages) CreatedAfter(t time.Time) ([]models.PubSubMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var messages []models.PubSubMessage
+	for _, m := range s.messages {
+		if m.CreatedAt.After(t) {
+			messages = append(messages, m)
+		}
+	}
+	return messages, nil
+}
+
+func (s *fakeMessages) FindByID(id int64) (*models.PubSubMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, m := range s.messages {
+		if m.ID == id {
+			return &m, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (s *fakeMessages) DeleteBefore(t time.Time) error { return nil }
+
+// deliveredIDs records the ids of the test events passed to deliver
+type deliveredIDs struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+func (d *deliveredIDs) deliver(userID uuid.UUID, topic Topic, event interface{}) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.ids = append(d.ids, event.(*testEvent).Value)
+}
+
+func (d *deliveredIDs) get() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.ids...)
+}
+
+func eventValues(ids ...int64) []string {
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = strconv.FormatInt(id, 10)
+	}
+	return values
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPostgresBackendStartReturnsConnectError(t *testing.T) {
+	connectErr := errors.New("connection refused")
+	backend := &PostgresBackend{
+		messages: &fakeMessages{},
+		connect: func(ctx context.Context) (listenConn, error) {
+			return nil, connectErr
+		},
+	}
+
+	hub := NewHubWithBackend(backend)
+	if err := hub.Start(context.Background()); !errors.Is(err, connectErr) {
+		t.Errorf("Start = %v, want %v", err, connectErr)
+	}
+}
+
+func TestPostgresBackendCatchesUpAndSkipsDuplicates(t *testing.T) {
+	userID := uuid.New()
+	messages := &fakeMessages{}
+	messages.add(t, 1, userID, time.Now().Add(-10*postgresCatchUpLookback)) // Long before startup
+	messages.add(t, 2, userID, time.Now().Add(-time.Second))
+
+	conn := newFakeConn()
+	backend := &PostgresBackend{
+		messages: messages,
+		connect: func(ctx context.Context) (listenConn, error) {
+			return conn, nil
+		},
+	}
+	hub := NewHubWithBackend(backend)
+	sub := hub.Subscribe(userID, TopicReminders)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := hub.Start(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	messages.add(t, 3, userID, time.Now())
+	conn.notifications <- "2" // Already delivered during catch-up
+	conn.notifications <- "not-an-id"
+	conn.notifications <- "3"
+
+	for _, want := range eventValues(2, 3) {
+		if event := receive(t, sub).(*testEvent); event.Value != want {
+			t.Fatalf("got event %q, want %q", event.Value, want)
+		}
+	}
+	expectNoEvent(t, sub)
+}
+
+func TestPostgresBackendCatchUpAfterReconnectSkipsDelivered(t *testing.T) {
+	userID := uuid.New()
+	messages := &fakeMessages{}
+	messages.add(t, 1, userID, time.Now())
+	backend := &PostgresBackend{messages: messages}
+	delivered := newRecentIDs(postgresDedupeSize)
+	received := &deliveredIDs{}
+
+	first := newFakeConn()
+	first.notifications <- "1"
+	close(first.notifications)
+	since := time.Now()
+	if err := backend.serve(context.Background(), first, received.deliver, since, delivered); err == nil {
+		t.Fatalf("serve returned without an error after the connection closed")
+	}
+
+	// Message 2 was published while the listener was down
+	messages.add(t, 2, userID, time.Now())
+	second := newFakeConn()
+	close(second.notifications)
+	backend.serve(context.Background(), second, received.deliver, time.Now(), delivered)
+
+	if got, want := received.get(), eventValues(1, 2); !equalStrings(got, want) {
+		t.Errorf("delivered %v, want %v once each", got, want)
+	}
+}
+
+func TestRecentIDsForgetsOldest(t *testing.T) {
+	ids := newRecentIDs(2)
+	for _, id := range []int64{1, 2, 3} {
+		if !ids.add(id) {
+			t.Fatalf("add(%d) reported a duplicate", id)
+		}
+	}
+
+	if ids.add(3) {
+		t.Errorf("add(3) accepted a remembered id")
+	}
+	if ids.contains(1) || !ids.contains(2) || !ids.contains(3) {
+		t.Errorf("remembers 1: %t, 2: %t, 3: %t; want only the two most recent", ids.contains(1), ids.contains(2), ids.contains(3))
+	}
+}
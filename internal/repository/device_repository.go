@@ -7,6 +7,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/user/remind-me/backend/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type DeviceRepository struct {
@@ -95,19 +96,40 @@ func (r *DeviceRepository) DeleteByDeviceIdentifier(deviceIdentifier string) err
 	return r.db.Where("device_identifier = ?", deviceIdentifier).Delete(&models.Device{}).Error
 }
 
-// DeleteByPushToken removes a device by its push token (used when APNs returns invalid token)
-func (r *DeviceRepository) DeleteByPushToken(pushToken string) error {
-	return r.db.Where("push_token = ?", pushToken).Delete(&models.Device{}).Error
+// DeleteByPushToken removes every device registered with the push token (used when
+// APNs or FCM reports the token invalid) and returns the deleted rows. If updatedBefore
+// is set, only registrations last updated before it are removed.
+func (r *DeviceRepository) DeleteByPushToken(pushToken string, updatedBefore *time.Time) ([]models.Device, error) {
+	var devices []models.Device
+	query := r.db.Clauses(clause.Returning{}).Where("push_token = ?", pushToken)
+	if updatedBefore != nil {
+		query = query.Where("updated_at < ?", *updatedBefore)
+	}
+	err := query.Delete(&devices).Error
+	return devices, err
 }
 
 // DeleteStaleDevices removes devices not seen in the specified number of days
-func (r *DeviceRepository) DeleteStaleDevices(days int) (int64, error) {
+// and returns the deleted rows
+func (r *DeviceRepository) DeleteStaleDevices(days int) ([]models.Device, error) {
 	staleThreshold := time.Now().AddDate(0, 0, -days)
-	result := r.db.Where("last_seen_at < ?", staleThreshold).Delete(&models.Device{})
-	return result.RowsAffected, result.Error
+	var devices []models.Device
+	err := r.db.Clauses(clause.Returning{}).
+		Where("last_seen_at < ?", staleThreshold).
+		Delete(&devices).Error
+	return devices, err
 }
 
-func (r *DeviceRepository) Upsert(device *models.Device) error {
+// DeviceUpsertResult describes what Upsert changed
+type DeviceUpsertResult struct {
+	Created bool
+	// Removed holds entries unlinked from another user or replaced by this registration
+	Removed []models.Device
+}
+
+func (r *DeviceRepository) Upsert(device *models.Device) (*DeviceUpsertResult, error) {
+	result := &DeviceUpsertResult{}
+
 	// Step 1: Check if this device_identifier exists for a DIFFERENT user
 	// If so, unlink it from that user (device can only belong to one account)
 	var existingForOtherUser models.Device
@@ -115,7 +137,10 @@ func (r *DeviceRepository) Upsert(device *models.Device) error {
 	if err == nil {
 		log.Printf("[DeviceRepository] Device %s was linked to user %s, unlinking before linking to user %s",
 			device.DeviceIdentifier, existingForOtherUser.UserID, device.UserID)
-		r.db.Delete(&existingForOtherUser)
+		if err := r.db.Delete(&existingForOtherUser).Error; err != nil {
+			return nil, err
+		}
+		result.Removed = append(result.Removed, existingForOtherUser)
 	}
 
 	// Step 2: Check if this push_token exists for current user with a DIFFERENT device_identifier
@@ -126,7 +151,10 @@ func (r *DeviceRepository) Upsert(device *models.Device) error {
 	if err == nil {
 		log.Printf("[DeviceRepository] Removing stale device entry with same push_token but different identifier: %s",
 			existingWithSameToken.DeviceIdentifier)
-		r.db.Delete(&existingWithSameToken)
+		if err := r.db.Delete(&existingWithSameToken).Error; err != nil {
+			return nil, err
+		}
+		result.Removed = append(result.Removed, existingWithSameToken)
 	}
 
 	// Step 3: Try to find existing device with same device identifier for current user
@@ -135,11 +163,15 @@ func (r *DeviceRepository) Upsert(device *models.Device) error {
 
 	if err == gorm.ErrRecordNotFound {
 		// Create new device
-		return r.db.Create(device).Error
+		if err := r.db.Create(device).Error; err != nil {
+			return nil, err
+		}
+		result.Created = true
+		return result, nil
 	}
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Update existing device, including the push token which may have changed
@@ -150,7 +182,10 @@ func (r *DeviceRepository) Upsert(device *models.Device) error {
 	existing.LastSeenAt = time.Now()
 	device.ID = existing.ID
 
-	return r.db.Save(&existing).Error
+	if err := r.db.Save(&existing).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 func (r *DeviceRepository) CountByUser(userID uuid.UUID) (int64, error) {
@@ -161,14 +196,14 @@ func (r *DeviceRepository) CountByUser(userID uuid.UUID) (int64, error) {
 	return count, err
 }
 
-func (r *DeviceRepository) GetAllPushTokens(userID uuid.UUID) ([]struct {
+// PushToken is a distinct push token of a user's devices
+type PushToken struct {
 	Platform  models.Platform
 	PushToken string
-}, error) {
-	var result []struct {
-		Platform  models.Platform
-		PushToken string
-	}
+}
+
+func (r *DeviceRepository) GetAllPushTokens(userID uuid.UUID) ([]PushToken, error) {
+	var result []PushToken
 	// Use DISTINCT to prevent duplicate notifications when same push_token
 	// exists multiple times (e.g., after device_identifier changes)
 	err := r.db.Model(&models.Device{}).
@@ -179,14 +214,8 @@ func (r *DeviceRepository) GetAllPushTokens(userID uuid.UUID) ([]struct {
 }
 
 // GetPushTokensExcluding returns all push tokens for a user except the specified device
-func (r *DeviceRepository) GetPushTokensExcluding(userID uuid.UUID, excludeDeviceID uuid.UUID) ([]struct {
-	Platform  models.Platform
-	PushToken string
-}, error) {
-	var result []struct {
-		Platform  models.Platform
-		PushToken string
-	}
+func (r *DeviceRepository) GetPushTokensExcluding(userID uuid.UUID, excludeDeviceID uuid.UUID) ([]PushToken, error) {
+	var result []PushToken
 	// Use DISTINCT to prevent duplicate notifications when same push_token
 	// exists multiple times (e.g., after device_identifier changes)
 	err := r.db.Model(&models.Device{}).
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/user/remind-me/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type IdempotencyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyRepository(db *gorm.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Reserve claims a key for an in-flight request owned by entry.Owner. It returns false
+// if the key is already held by a live entry. Expired entries, and in-flight entries
+// whose owner stopped refreshing the heartbeat over staleAfter ago (left behind by a
+// crashed request), are taken over.
+func (r *IdempotencyRepository) Reserve(entry *models.IdempotencyKey, staleAfter time.Duration) (bool, error) {
+	result := r.db.Exec(`
+		INSERT INTO idempotency_keys (user_id, key, request_hash, response, owner, heartbeat_at, created_at, expires_at)
+		VALUES (?, ?, ?, NULL, ?, NOW(), NOW(), ?)
+		ON CONFLICT (user_id, key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash,
+			response = NULL,
+			owner = EXCLUDED.owner,
+			heartbeat_at = EXCLUDED.heartbeat_at,
+			created_at = EXCLUDED.created_at,
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at < NOW()
+			OR (idempotency_keys.response IS NULL AND idempotency_keys.heartbeat_at < ?)
+	`, entry.UserID, entry.Key, entry.RequestHash, entry.Owner, entry.ExpiresAt, time.Now().Add(-staleAfter))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *IdempotencyRepository) Find(userID uuid.UUID, key string) (*models.IdempotencyKey, error) {
+	var entry models.IdempotencyKey
+	err := r.db.Where("user_id = ? AND key = ?", userID, key).First(&entry).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Heartbeat keeps owner's in-flight reservation from being taken over as stale
+func (r *IdempotencyRepository) Heartbeat(userID uuid.UUID, key string, owner uuid.UUID) error {
+	return r.db.Model(&models.IdempotencyKey{}).
+		Where("user_id = ? AND key = ? AND owner = ? AND response IS NULL", userID, key, owner).
+		Update("heartbeat_at", time.Now()).Error
+}
+
+// SaveResponse stores the final response for a key reserved by owner
+func (r *IdempotencyRepository) SaveResponse(userID uuid.UUID, key string, owner uuid.UUID, response []byte) error {
+	return r.db.Model(&models.IdempotencyKey{}).
+		Where("user_id = ? AND key = ? AND owner = ?", userID, key, owner).
+		Update("response", response).Error
+}
+
+// Release drops owner's in-flight reservation so the request can be retried
+func (r *IdempotencyRepository) Release(userID uuid.UUID, key string, owner uuid.UUID) error {
+	return r.db.
+		Where("user_id = ? AND key = ? AND owner = ? AND response IS NULL", userID, key, owner).
+		Delete(&models.IdempotencyKey{}).Error
+}
+
+// DeleteExpired removes keys past their TTL
+func (r *IdempotencyRepository) DeleteExpired() (int64, error) {
+	result := r.db.Where("expires_at < ?", time.Now()).Delete(&models.IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/user/remind-me/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type NotificationDeliveryRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationDeliveryRepository(db *gorm.DB) *NotificationDeliveryRepository {
+	return &NotificationDeliveryRepository{db: db}
+}
+
+func (r *NotificationDeliveryRepository) Create(delivery *models.NotificationDelivery) error {
+	return r.db.Create(delivery).Error
+}
+
+// ListByReminder returns the user's most recent delivery attempts for a reminder, newest first
+func (r *NotificationDeliveryRepository) ListByReminder(reminderID, userID uuid.UUID, limit int) ([]models.NotificationDelivery, error) {
+	var deliveries []models.NotificationDelivery
+	err := r.db.
+		Where("reminder_id = ? AND user_id = ?", reminderID, userID).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// DeleteOlderThan removes delivery records created before the cutoff
+func (r *NotificationDeliveryRepository) DeleteOlderThan(cutoff time.Time) (int64, error) {
+	result := r.db.Where("created_at < ?", cutoff).Delete(&models.NotificationDelivery{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/user/remind-me/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationTargetRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationTargetRepository(db *gorm.DB) *NotificationTargetRepository {
+	return &NotificationTargetRepository{db: db}
+}
+
+// ListByOccurrence returns the per-device delivery state of one occurrence of a reminder
+func (r *NotificationTargetRepository) ListByOccurrence(reminderID uuid.UUID, dueAt time.Time) ([]models.NotificationTarget, error) {
+	var targets []models.NotificationTarget
+	err := r.db.Where("reminder_id = ? AND due_at = ?", reminderID, dueAt).Find(&targets).Error
+	return targets, err
+}
+
+// Save creates the target on its first attempt and updates it afterwards
+func (r *NotificationTargetRepository) Save(target *models.NotificationTarget) error {
+	return r.db.Omit(clause.Associations).Save(target).Error
+}
+
+// ClaimDeferred leases the deferred targets of up to limit devices whose quiet hours have
+// ended, with each target's reminder loaded. A device's targets are claimed together so
+// they go out in one summary; the lease keeps other workers off them until it expires.
+func (r *NotificationTargetRepository) ClaimDeferred(now time.Time, limit int, lease time.Duration) ([]models.NotificationTarget, error) {
+	var claimed []models.NotificationTarget
+	err := r.db.Raw(`
+		UPDATE notification_targets
+		SET next_attempt_at = ?
+		WHERE id IN (
+			SELECT id FROM notification_targets
+			WHERE status = ? AND next_attempt_at <= ?
+				AND device_id IN (
+					SELECT device_id FROM notification_targets
+					WHERE status = ? AND next_attempt_at <= ?
+					GROUP BY device_id
+					ORDER BY MIN(next_attempt_at)
+					LIMIT ?
+				)
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
+	`, now.Add(lease), models.TargetStatusDeferred, now, models.TargetStatusDeferred, now, limit).Scan(&claimed).Error
+	if err != nil || len(claimed) == 0 {
+		return nil, err
+	}
+
+	ids := make([]int64, len(claimed))
+	for i, target := range claimed {
+		ids[i] = target.ID
+	}
+
+	// Deleted reminders are left unloaded
+	var targets []models.NotificationTarget
+	err = r.db.Preload("Reminder").Where("id IN ?", ids).Order("device_id, due_at").Find(&targets).Error
+	return targets, err
+}
+
+// DeleteOlderThan removes targets last updated before the cutoff
+func (r *NotificationTargetRepository) DeleteOlderThan(cutoff time.Time) (int64, error) {
+	result := r.db.Where("updated_at < ?", cutoff).Delete(&models.NotificationTarget{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/user/remind-me/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type OutboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// Enqueue records a side effect. Call it on a transaction-scoped repository so the
+// event commits or rolls back together with the change.
+func (r *OutboxRepository) Enqueue(userID uuid.UUID, kind models.OutboxKind, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return r.db.Create(&models.OutboxEvent{
+		UserID:      userID,
+		Kind:        kind,
+		Payload:     data,
+		Status:      models.OutboxStatusPending,
+		AvailableAt: time.Now(),
+	}).Error
+}
+
+// ClaimDue leases up to limit pending events whose available_at has passed.
+// Claimed events become invisible to other relays until the lease expires, so a relay
+// that dies mid-batch has its events picked up again.
+func (r *OutboxRepository) ClaimDue(limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	err := r.db.Raw(`
+		UPDATE outbox_events
+		SET available_at = ?
+		WHERE id IN (
+			SELECT id FROM outbox_events
+			WHERE status = ? AND available_at <= NOW()
+			ORDER BY id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
+	`, time.Now().Add(lease), models.OutboxStatusPending, limit).Scan(&events).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// RETURNING order is unspecified; deliver in insertion order
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events, nil
+}
+
+// Release ends the lease on claimed events that were not attempted, so the next claim
+// picks them up without waiting for the lease to expire
+func (r *OutboxRepository) Release(ids []int64) error {
+	return r.db.Model(&models.OutboxEvent{}).
+		Where("id IN ? AND status = ?", ids, models.OutboxStatusPending).
+		Update("available_at", time.Now()).Error
+}
+
+func (r *OutboxRepository) MarkDone(id int64) error {
+	return r.db.Model(&models.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       models.OutboxStatusDone,
+			"processed_at": time.Now(),
+		}).Error
+}
+
+// MarkFailed records a failed attempt and schedules the next one at retryAt
+func (r *OutboxRepository) MarkFailed(id int64, attempts int, retryAt time.Time, lastError string) error {
+	return r.db.Model(&models.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"attempts":     attempts,
+			"available_at": retryAt,
+			"last_error":   lastError,
+		}).Error
+}
+
+// MarkDead stops retrying an event
+func (r *OutboxRepository) MarkDead(id int64, attempts int, lastError string) error {
+	return r.db.Model(&models.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       models.OutboxStatusDead,
+			"attempts":     attempts,
+			"last_error":   lastError,
+			"processed_at": time.Now(),
+		}).Error
+}
+
+// DeleteProcessedBefore removes delivered and dead events processed before the given time
+func (r *OutboxRepository) DeleteProcessedBefore(before time.Time) (int64, error) {
+	result := r.db.
+		Where("status <> ? AND processed_at < ?", models.OutboxStatusPending, before).
+		Delete(&models.OutboxEvent{})
+	return result.RowsAffected, result.Error
+}
@@ -128,9 +128,16 @@ func (r *ReminderListRepository) MoveRemindersToList(fromListID, toListID uuid.U
 		Update("list_id", toListID).Error
 }
 
-// DeleteRemindersByListID deletes all reminders belonging to a list
-func (r *ReminderListRepository) DeleteRemindersByListID(listID uuid.UUID) error {
-	return r.db.Where("list_id = ?", listID).Delete(&models.Reminder{}).Error
+// DeleteRemindersByListID deletes all reminders belonging to a list and returns them
+func (r *ReminderListRepository) DeleteRemindersByListID(listID uuid.UUID) ([]models.Reminder, error) {
+	var deleted []models.Reminder
+	err := r.db.Raw(`
+		UPDATE reminders
+		SET deleted_at = NOW()
+		WHERE list_id = ? AND deleted_at IS NULL
+		RETURNING *
+	`, listID).Scan(&deleted).Error
+	return deleted, err
 }
 
 // RestoreByUserID restores all soft-deleted reminder lists for a user
@@ -113,6 +113,25 @@ func (r *ReminderRepository) ListUpcoming(userID uuid.UUID, from, to time.Time)
 	return reminders, err
 }
 
+// ListOverdue returns the user's active reminders that were due before the given time
+func (r *ReminderRepository) ListOverdue(userID uuid.UUID, before time.Time) ([]models.Reminder, error) {
+	var reminders []models.Reminder
+	err := r.db.
+		Where("user_id = ? AND due_at < ? AND status = ?", userID, before, models.StatusActive).
+		Order("due_at ASC").
+		Find(&reminders).Error
+	return reminders, err
+}
+
+// CountDueBefore counts the user's active reminders due before the given time
+func (r *ReminderRepository) CountDueBefore(userID uuid.UUID, before time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Reminder{}).
+		Where("user_id = ? AND due_at < ? AND status = ?", userID, before, models.StatusActive).
+		Count(&count).Error
+	return count, err
+}
+
 func (r *ReminderRepository) Update(reminder *models.Reminder) error {
 	return r.db.Save(reminder).Error
 }
@@ -138,9 +157,10 @@ func (r *ReminderRepository) Snooze(id uuid.UUID, until time.Time, deviceID *uui
 		// Update dueAt directly instead of snoozedUntil
 		"due_at": until,
 		// Clear snoozedUntil for backward compat
-		"snoozed_until":        nil,
-		"snooze_count":         gorm.Expr("snooze_count + 1"),
-		"notification_sent_at": nil, // Clear so a new notification will be sent after snooze
+		"snoozed_until":              nil,
+		"snooze_count":               gorm.Expr("snooze_count + 1"),
+		"notification_sent_at":       nil, // Clear so a new notification will be sent after snooze
+		"notification_claimed_until": nil, // Drop any retry lease held for the previous due time
 	}
 	if deviceID != nil {
 		updates["last_modified_by"] = deviceID
@@ -207,32 +227,80 @@ func (r *ReminderRepository) CountByUser(userID uuid.UUID) (int64, error) {
 	return count, err
 }
 
-// FindDueForNotification finds reminders that are due and haven't been notified yet
-// It looks for active reminders where due_at is now or in the past AND notification_sent_at IS NULL
+// ClaimDueForNotification leases up to limit active reminders that are due and haven't
+// been notified yet. SKIP LOCKED keeps concurrent workers from claiming the same rows, and
+// a claimed reminder isn't returned again until its lease expires, so a worker that dies
+// mid-send has its reminders picked up by the next run.
 // Note: Reminders without scheduled dates (due_at IS NULL) are excluded from notifications
 // Simplified: snoozed reminders now have updated dueAt with status="active"
-func (r *ReminderRepository) FindDueForNotification(now time.Time) ([]models.Reminder, error) {
+func (r *ReminderRepository) ClaimDueForNotification(now time.Time, limit int, lease time.Duration) ([]models.Reminder, error) {
 	var reminders []models.Reminder
-	err := r.db.
-		Where("due_at IS NOT NULL AND status = ? AND due_at <= ? AND notification_sent_at IS NULL", models.StatusActive, now).
-		Preload("User").
-		Find(&reminders).Error
+	err := r.db.Raw(`
+		UPDATE reminders
+		SET notification_claimed_until = ?
+		WHERE id IN (
+			SELECT id FROM reminders
+			WHERE due_at IS NOT NULL AND status = ? AND due_at <= ?
+				AND notification_sent_at IS NULL AND deleted_at IS NULL
+				AND (notification_claimed_until IS NULL OR notification_claimed_until <= ?)
+			ORDER BY due_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
+	`, now.Add(lease), models.StatusActive, now, now, limit).Scan(&reminders).Error
 	return reminders, err
 }
 
-// MarkNotificationSent marks a reminder as having its notification sent
+// ListNotificationTimes returns when each pending reminder notification is next due, up
+// to until, earliest first. A reminder whose claim runs past its due time (a send in
+// progress or a push retry) is due again when the claim expires. Notifications deferred
+// for quiet hours are due when the quiet hours end.
+func (r *ReminderRepository) ListNotificationTimes(until time.Time, limit int) ([]time.Time, error) {
+	var times []time.Time
+	err := r.db.Raw(`
+		SELECT fire_at FROM (
+			SELECT GREATEST(due_at, COALESCE(notification_claimed_until, due_at)) AS fire_at
+			FROM reminders
+			WHERE due_at IS NOT NULL AND status = ?
+				AND notification_sent_at IS NULL AND deleted_at IS NULL
+			UNION ALL
+			SELECT next_attempt_at AS fire_at
+			FROM notification_targets
+			WHERE status = ?
+		) AS due
+		WHERE fire_at <= ?
+		ORDER BY fire_at
+		LIMIT ?
+	`, models.StatusActive, models.TargetStatusDeferred, until, limit).Scan(&times).Error
+	return times, err
+}
+
+// DeferNotification extends a reminder's claim until at, when its next push retry is due
+func (r *ReminderRepository) DeferNotification(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&models.Reminder{}).
+		Where("id = ?", id).
+		UpdateColumn("notification_claimed_until", at).Error
+}
+
+// MarkNotificationSent marks a reminder as having its notification sent and releases its claim
 func (r *ReminderRepository) MarkNotificationSent(id uuid.UUID) error {
-	now := time.Now()
 	return r.db.Model(&models.Reminder{}).
 		Where("id = ?", id).
-		Update("notification_sent_at", now).Error
+		Updates(map[string]interface{}{
+			"notification_sent_at":       time.Now(),
+			"notification_claimed_until": nil,
+		}).Error
 }
 
 // ClearNotificationSent clears the notification_sent_at field (used when rescheduling after snooze)
 func (r *ReminderRepository) ClearNotificationSent(id uuid.UUID) error {
 	return r.db.Model(&models.Reminder{}).
 		Where("id = ?", id).
-		Update("notification_sent_at", nil).Error
+		Updates(map[string]interface{}{
+			"notification_sent_at":       nil,
+			"notification_claimed_until": nil,
+		}).Error
 }
 
 // RestoreByUserID restores all soft-deleted reminders for a user
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"context"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,15 +18,53 @@ func NewSyncRepository(db *gorm.DB) *SyncRepository {
 	return &SyncRepository{db: db}
 }
 
+// syncLogLockClass namespaces the per-user sync log locks ("sync" in ASCII)
+const syncLogLockClass = 0x73796e63
+
+// Create records a sync event. See lockSyncLogs for how its seq is kept in commit order.
 func (r *SyncRepository) Create(event *models.SyncEvent) error {
-	return r.db.Create(event).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockSyncLogs(tx, event.UserID); err != nil {
+			return err
+		}
+		return tx.Create(event).Error
+	})
 }
 
 func (r *SyncRepository) CreateBatch(events []*models.SyncEvent) error {
 	if len(events) == 0 {
 		return nil
 	}
-	return r.db.Create(events).Error
+	userIDs := make([]uuid.UUID, len(events))
+	for i, event := range events {
+		userIDs[i] = event.UserID
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockSyncLogs(tx, userIDs...); err != nil {
+			return err
+		}
+		return tx.Create(events).Error
+	})
+}
+
+// lockSyncLogs takes the sync log lock of each user until the enclosing transaction
+// ends. seq is drawn from a sequence when a row is inserted, not when it commits, so
+// without the lock two writers could commit a user's events out of seq order and a
+// reader paging by seq would skip the one that committed second. Holding the lock
+// until commit makes each user's events commit in seq order. Locks are taken in a
+// fixed order so batches covering several users cannot deadlock.
+func lockSyncLogs(tx *gorm.DB, userIDs ...uuid.UUID) error {
+	keys := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		keys = append(keys, id.String())
+	}
+	slices.Sort(keys)
+	for _, key := range slices.Compact(keys) {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?, hashtext(?))", syncLogLockClass, key).Error; err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (r *SyncRepository) FindByID(id uuid.UUID) (*models.SyncEvent, error) {
@@ -78,9 +118,78 @@ func (r *SyncRepository) GetLatestEventTime(userID uuid.UUID) (*time.Time, error
 	return &event.CreatedAt, nil
 }
 
-func (r *SyncRepository) DeleteOldEvents(before time.Time) (int64, error) {
-	result := r.db.Where("created_at < ?", before).Delete(&models.SyncEvent{})
-	return result.RowsAffected, result.Error
+// DeleteOldEvents removes sync events created before the given time and advances
+// each affected user's watermark so stale cursors are detected as needing a resync
+func (r *SyncRepository) DeleteOldEvents(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	err := r.db.WithContext(ctx).Raw(`
+		WITH deleted AS (
+			DELETE FROM sync_events
+			WHERE created_at < ?
+			RETURNING user_id, seq
+		), marks AS (
+			INSERT INTO sync_watermarks (user_id, pruned_through_seq, updated_at)
+			SELECT user_id, MAX(seq), NOW() FROM deleted GROUP BY user_id
+			ON CONFLICT (user_id) DO UPDATE
+			SET pruned_through_seq = GREATEST(sync_watermarks.pruned_through_seq, EXCLUDED.pruned_through_seq),
+			    updated_at = NOW()
+			RETURNING 1
+		)
+		SELECT COUNT(*) FROM deleted
+	`, before).Scan(&deleted).Error
+	return deleted, err
+}
+
+// CompactEntityHistory keeps only the latest event for each entity and strips the
+// payload from tombstones. Superseded events carry no information a client needs,
+// since replaying from any cursor still ends on the entity's latest event.
+func (r *SyncRepository) CompactEntityHistory(ctx context.Context) (int64, error) {
+	db := r.db.WithContext(ctx)
+	result := db.Exec(`
+		DELETE FROM sync_events
+		WHERE id IN (
+			SELECT id FROM (
+				SELECT id, ROW_NUMBER() OVER (
+					PARTITION BY user_id, entity_type, entity_id
+					ORDER BY seq DESC
+				) AS rn
+				FROM sync_events
+			) ranked
+			WHERE ranked.rn > 1
+		)
+	`)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	err := db.Model(&models.SyncEvent{}).
+		Where("action = ? AND payload IS NOT NULL", models.SyncActionDelete).
+		Update("payload", nil).Error
+
+	return result.RowsAffected, err
+}
+
+// GetPrunedThroughSeq returns the highest sequence removed by retention for a user
+func (r *SyncRepository) GetPrunedThroughSeq(userID uuid.UUID) (int64, error) {
+	var watermark models.SyncWatermark
+	err := r.db.Where("user_id = ?", userID).First(&watermark).Error
+	if err == gorm.ErrRecordNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return watermark.PrunedThroughSeq, nil
+}
+
+// GetLatestSeq returns the sequence of the user's most recent sync event, or 0 if none exist
+func (r *SyncRepository) GetLatestSeq(userID uuid.UUID) (int64, error) {
+	var seq int64
+	err := r.db.Model(&models.SyncEvent{}).
+		Select("COALESCE(MAX(seq), 0)").
+		Where("user_id = ?", userID).
+		Scan(&seq).Error
+	return seq, err
 }
 
 func (r *SyncRepository) GetEventsForEntity(entityType models.EntityType, entityID uuid.UUID) ([]models.SyncEvent, error) {
@@ -127,3 +236,28 @@ func (r *SyncRepository) GetChangesSince(userID uuid.UUID, since time.Time, limi
 
 	return events, hasMore, nil
 }
+
+// GetChangesAfter returns sync events with a sequence greater than afterSeq, in order.
+// Events are written under lockSyncLogs, so no event with a lower seq than one
+// returned here can commit later: a cursor taken from the result never skips one.
+func (r *SyncRepository) GetChangesAfter(userID uuid.UUID, afterSeq int64, limit int) ([]models.SyncEvent, bool, error) {
+	var events []models.SyncEvent
+
+	// Fetch one extra to determine if there are more
+	err := r.db.
+		Where("user_id = ? AND seq > ?", userID, afterSeq).
+		Order("seq ASC").
+		Limit(limit + 1).
+		Find(&events).Error
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(events) > limit
+	if hasMore {
+		events = events[:limit]
+	}
+
+	return events, hasMore, nil
+}
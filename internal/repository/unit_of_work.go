@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+// Repositories groups repositories that share a single database handle.
+// Inside a UnitOfWork the handle is the transaction, so every write goes through it.
+type Repositories struct {
+	Users         *UserRepository
+	Devices       *DeviceRepository
+	Reminders     *ReminderRepository
+	ReminderLists *ReminderListRepository
+	Sync          *SyncRepository
+	Outbox        *OutboxRepository
+}
+
+func NewRepositories(db *gorm.DB) *Repositories {
+	return &Repositories{
+		Users:         NewUserRepository(db),
+		Devices:       NewDeviceRepository(db),
+		Reminders:     NewReminderRepository(db),
+		ReminderLists: NewReminderListRepository(db),
+		Sync:          NewSyncRepository(db),
+		Outbox:        NewOutboxRepository(db),
+	}
+}
+
+// UnitOfWork runs multi-step operations atomically
+type UnitOfWork struct {
+	db          *gorm.DB
+	afterCommit []func()
+}
+
+func NewUnitOfWork(db *gorm.DB) *UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+// OnCommit registers a hook that runs after every successful commit.
+// Hooks must not block; register them before the unit of work is shared.
+func (u *UnitOfWork) OnCommit(fn func()) {
+	u.afterCommit = append(u.afterCommit, fn)
+}
+
+// Do runs fn inside a transaction with repositories bound to it.
+// The transaction commits if fn returns nil and rolls back on any error or panic.
+func (u *UnitOfWork) Do(fn func(tx *Repositories) error) error {
+	err := u.db.Transaction(func(tx *gorm.DB) error {
+		return fn(NewRepositories(tx))
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range u.afterCommit {
+		hook()
+	}
+	return nil
+}
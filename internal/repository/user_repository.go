@@ -56,10 +56,46 @@ func (r *UserRepository) FindByEmail(email string) (*models.User, error) {
 	return &user, nil
 }
 
+// Exists reports whether the user exists and has not been deleted
+func (r *UserRepository) Exists(id uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).
+		Where("id = ?", id).
+		Count(&count).Error
+	return count > 0, err
+}
+
 func (r *UserRepository) Update(user *models.User) error {
 	return r.db.Save(user).Error
 }
 
+// ClaimDueDigests leases up to limit users whose daily digest is due by pushing their
+// next digest past the lease. SKIP LOCKED keeps concurrent workers from claiming the
+// same users; a worker that dies mid-send leaves the digest due again when the lease expires.
+func (r *UserRepository) ClaimDueDigests(now time.Time, limit int, lease time.Duration) ([]models.User, error) {
+	var users []models.User
+	err := r.db.Raw(`
+		UPDATE users
+		SET digest_next_at = ?
+		WHERE id IN (
+			SELECT id FROM users
+			WHERE digest_enabled AND digest_next_at <= ? AND deleted_at IS NULL
+			ORDER BY digest_next_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
+	`, now.Add(lease), now, limit).Scan(&users).Error
+	return users, err
+}
+
+// SetNextDigestAt records when the user's next digest is due; nil if they get none
+func (r *UserRepository) SetNextDigestAt(id uuid.UUID, at *time.Time) error {
+	return r.db.Model(&models.User{}).
+		Where("id = ?", id).
+		UpdateColumn("digest_next_at", at).Error
+}
+
 func (r *UserRepository) Delete(id uuid.UUID) error {
 	return r.db.Delete(&models.User{}, id).Error
 }
@@ -0,0 +1,86 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+const (
+	// lockKey identifies the scheduler's advisory lock ("remind" in ASCII)
+	lockKey int64 = 0x72656d696e64
+	channel       = "reminder_schedule"
+)
+
+// PostgresElection elects the leader with a Postgres advisory lock and sends it due
+// times with LISTEN/NOTIFY. The lock is held by the leader's connection, so another
+// instance takes over as soon as that connection drops.
+//
+// The advisory lock and LISTEN need a session-level connection: connURL must not point
+// at a transaction-mode connection pooler.
+type PostgresElection struct {
+	db      *gorm.DB
+	connURL string
+}
+
+// NewPostgresElection creates an election that notifies through db and campaigns over connURL
+func NewPostgresElection(db *gorm.DB, connURL string) *PostgresElection {
+	return &PostgresElection{
+		db:      db,
+		connURL: connURL,
+	}
+}
+
+func (e *PostgresElection) Campaign(ctx context.Context) (Term, error) {
+	conn, err := pgx.Connect(ctx, e.connURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var acquired bool
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", lockKey).Scan(&acquired); err != nil {
+		conn.Close(context.Background())
+		return nil, err
+	}
+	if !acquired {
+		conn.Close(context.Background())
+		return nil, nil
+	}
+	if _, err := conn.Exec(ctx, "LISTEN "+channel); err != nil {
+		conn.Close(context.Background())
+		return nil, err
+	}
+	return &postgresTerm{conn: conn}, nil
+}
+
+func (e *PostgresElection) Notify(dueAt time.Time) error {
+	return e.db.Exec("SELECT pg_notify(?, ?)", channel, dueAt.UTC().Format(time.RFC3339Nano)).Error
+}
+
+// postgresTerm is a leadership held by one connection. The connection is only used by
+// the listener; closing it releases the lock.
+type postgresTerm struct {
+	conn *pgx.Conn
+}
+
+func (t *postgresTerm) Wait(ctx context.Context) (time.Time, error) {
+	for {
+		notification, err := t.conn.WaitForNotification(ctx)
+		if err != nil {
+			return time.Time{}, err
+		}
+		dueAt, err := time.Parse(time.RFC3339Nano, notification.Payload)
+		if err != nil {
+			log.Printf("[Scheduler] Ignoring malformed notification %q", notification.Payload)
+			continue
+		}
+		return dueAt, nil
+	}
+}
+
+func (t *postgresTerm) Resign() {
+	t.conn.Close(context.Background())
+}
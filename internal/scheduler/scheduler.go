@@ -0,0 +1,231 @@
+// Package scheduler fires reminder notifications at their due time instead of waiting
+// for the next external cron tick. One instance at a time leads, elected with a Postgres
+// advisory lock; the others stand by and take over if the leader's connection drops.
+package scheduler
+
+import (
+	"container/heap"
+	"context"
+	"log"
+	"time"
+)
+
+const (
+	electionInterval = 15 * time.Second
+	refreshInterval  = time.Minute
+	lookahead        = 5 * time.Minute
+	maxScheduled     = 1000
+	runTimeout       = 55 * time.Second
+)
+
+// Election chooses one leader among the instances and relays due times to it
+type Election interface {
+	// Campaign takes leadership if no other instance holds it. It returns a nil Term
+	// without error when another instance leads.
+	Campaign(ctx context.Context) (Term, error)
+	// Notify sends a due time to the current leader, on whichever instance it runs
+	Notify(dueAt time.Time) error
+}
+
+// Term is one instance's hold on leadership
+type Term interface {
+	// Wait blocks until a due time is notified. It returns an error once leadership is
+	// lost or ctx is cancelled.
+	Wait(ctx context.Context) (time.Time, error)
+	// Resign gives up leadership
+	Resign()
+}
+
+// TimeStore lists when pending reminder notifications are due
+type TimeStore interface {
+	ListNotificationTimes(until time.Time, limit int) ([]time.Time, error)
+}
+
+// Job processes the reminders that are due
+type Job interface {
+	ProcessDueReminders(ctx context.Context) (int, error)
+}
+
+// clock tells the time and waits, so tests can control both
+type clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Scheduler wakes at each upcoming reminder due time and runs the notification job.
+// Due times are loaded every refreshInterval and after each run, and reminder changes
+// reported through Notify are added as they happen. The job claims reminders atomically,
+// so the HTTP cron endpoint can keep running as a fallback.
+type Scheduler struct {
+	election Election
+	times    TimeStore
+	job      Job
+	clock    clock
+}
+
+// NewScheduler creates a scheduler that runs job at the times listed by times while
+// election makes it the leader
+func NewScheduler(election Election, times TimeStore, job Job) *Scheduler {
+	return &Scheduler{
+		election: election,
+		times:    times,
+		job:      job,
+		clock:    realClock{},
+	}
+}
+
+// Notify tells the leader that a reminder is now due at dueAt. Failures are only
+// logged: the leader picks the change up on its next refresh.
+func (s *Scheduler) Notify(dueAt time.Time) {
+	if dueAt.Sub(s.clock.Now()) > lookahead {
+		return // Loaded by a later refresh
+	}
+	if err := s.election.Notify(dueAt); err != nil {
+		log.Printf("[Scheduler] Failed to notify leader of due time %s: %v", dueAt.Format(time.RFC3339), err)
+	}
+}
+
+// Run campaigns for leadership and schedules notifications while leading, until ctx
+// is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	log.Printf("[Scheduler] Started")
+	for {
+		if err := s.lead(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("[Scheduler] Lost leadership: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Printf("[Scheduler] Stopped")
+			return
+		case <-s.clock.After(electionInterval):
+		}
+	}
+}
+
+// lead campaigns for leadership and, if elected, schedules notifications until the term
+// ends or ctx is cancelled. It returns nil without leading if another instance leads.
+func (s *Scheduler) lead(ctx context.Context) error {
+	term, err := s.election.Campaign(ctx)
+	if err != nil || term == nil {
+		return err
+	}
+	defer term.Resign()
+	log.Printf("[Scheduler] Elected leader")
+
+	listenCtx, cancel := context.WithCancel(ctx)
+	notified := make(chan time.Time, 64)
+	lost := make(chan error, 1)
+	done := make(chan struct{})
+	defer func() {
+		cancel()
+		<-done
+	}()
+	go func() {
+		defer close(done)
+		for {
+			dueAt, err := term.Wait(listenCtx)
+			if err != nil {
+				lost <- err
+				return
+			}
+			select {
+			case notified <- dueAt:
+			default: // The next refresh catches up
+			}
+		}
+	}()
+
+	due := &dueHeap{}
+	s.refresh(due, time.Time{})
+	nextRefresh := s.clock.Now().Add(refreshInterval)
+
+	for {
+		now := s.clock.Now()
+		wake := s.clock.After(min(due.wait(now), nextRefresh.Sub(now)))
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-lost:
+			return err
+		case dueAt := <-notified:
+			heap.Push(due, dueAt)
+		case <-wake:
+			now := s.clock.Now()
+			if !now.Before(nextRefresh) {
+				s.refresh(due, time.Time{})
+				nextRefresh = now.Add(refreshInterval)
+			}
+
+			fired := false
+			for due.Len() > 0 && !now.Before((*due)[0]) {
+				heap.Pop(due)
+				fired = true
+			}
+			if fired {
+				s.run(ctx)
+				// Times that were already due are ones the run left behind, e.g. a reminder
+				// whose user no longer exists. They wait for the next periodic refresh rather
+				// than firing again straight away.
+				s.refresh(due, now)
+			}
+		}
+	}
+}
+
+// run processes every reminder that is due now
+func (s *Scheduler) run(ctx context.Context) {
+	runCtx, cancel := context.WithTimeout(ctx, runTimeout)
+	defer cancel()
+
+	if _, err := s.job.ProcessDueReminders(runCtx); err != nil {
+		log.Printf("[Scheduler] Error processing due reminders: %v", err)
+	}
+}
+
+// refresh reloads the due times within the lookahead window, skipping those at or
+// before after
+func (s *Scheduler) refresh(due *dueHeap, after time.Time) {
+	times, err := s.times.ListNotificationTimes(s.clock.Now().Add(lookahead), maxScheduled)
+	if err != nil {
+		log.Printf("[Scheduler] Error loading due times: %v", err)
+		return
+	}
+
+	for len(times) > 0 && !times[0].After(after) {
+		times = times[1:]
+	}
+	*due = times // Already sorted, so a valid heap
+}
+
+// dueHeap is a min-heap of due times
+type dueHeap []time.Time
+
+func (h dueHeap) Len() int           { return len(h) }
+func (h dueHeap) Less(i, j int) bool { return h[i].Before(h[j]) }
+func (h dueHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *dueHeap) Push(x interface{}) { *h = append(*h, x.(time.Time)) }
+
+func (h *dueHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+// wait returns how long after now the earliest due time is, or refreshInterval if none
+// is scheduled
+func (h dueHeap) wait(now time.Time) time.Duration {
+	if len(h) == 0 {
+		return refreshInterval
+	}
+	return max(h[0].Sub(now), 0)
+}
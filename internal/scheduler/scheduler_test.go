@@ -0,0 +1,288 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClock only moves when advanced. Every wait it starts is reported on sleeps so a
+// test can tell when the scheduler has gone idle.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+	sleeps  chan time.Duration
+}
+
+type fakeWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC), sleeps: make(chan time.Duration, 100)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, fakeWaiter{at: c.now.Add(d), ch: ch})
+	c.sleeps <- d
+	return ch
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	pending := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			pending = append(pending, w)
+		} else {
+			w.ch <- c.now
+		}
+	}
+	c.waiters = pending
+}
+
+// expectSleep waits for the scheduler to start waiting and checks for how long
+func (c *fakeClock) expectSleep(t *testing.T, want time.Duration) {
+	t.Helper()
+	select {
+	case d := <-c.sleeps:
+		if d != want {
+			t.Fatalf("scheduler slept %v, want %v", d, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("scheduler never slept, want a %v sleep", want)
+	}
+}
+
+// fakeElection hands out the queued campaign results in order; once they run out
+// another instance leads
+type fakeElection struct {
+	mu        sync.Mutex
+	terms     []*fakeTerm
+	campaigns int
+	notified  []time.Time
+}
+
+func (e *fakeElection) Campaign(ctx context.Context) (Term, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.campaigns++
+	if len(e.terms) == 0 {
+		return nil, nil
+	}
+	term := e.terms[0]
+	e.terms = e.terms[1:]
+	if term == nil {
+		return nil, nil
+	}
+	return term, nil
+}
+
+func (e *fakeElection) Notify(dueAt time.Time) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.notified = append(e.notified, dueAt)
+	return nil
+}
+
+type fakeTerm struct {
+	due      chan time.Time
+	lost     chan struct{}
+	resigned atomic.Bool
+}
+
+func newFakeTerm() *fakeTerm {
+	return &fakeTerm{due: make(chan time.Time), lost: make(chan struct{})}
+}
+
+func (t *fakeTerm) Wait(ctx context.Context) (time.Time, error) {
+	select {
+	case dueAt := <-t.due:
+		return dueAt, nil
+	case <-t.lost:
+		return time.Time{}, errors.New("connection lost")
+	case <-ctx.Done():
+		return time.Time{}, ctx.Err()
+	}
+}
+
+func (t *fakeTerm) Resign() { t.resigned.Store(true) }
+
+type fakeTimes struct {
+	mu    sync.Mutex
+	times []time.Time
+}
+
+func (s *fakeTimes) ListNotificationTimes(until time.Time, limit int) ([]time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var times []time.Time
+	for _, t := range s.times {
+		if !t.After(until) && len(times) < limit {
+			times = append(times, t)
+		}
+	}
+	return times, nil
+}
+
+type fakeJob struct {
+	runs atomic.Int32
+}
+
+func (j *fakeJob) ProcessDueReminders(ctx context.Context) (int, error) {
+	j.runs.Add(1)
+	return 0, nil
+}
+
+type schedulerFixture struct {
+	scheduler *Scheduler
+	clock     *fakeClock
+	election  *fakeElection
+	times     *fakeTimes
+	job       *fakeJob
+	stop      func()
+}
+
+func newSchedulerFixture(terms ...*fakeTerm) *schedulerFixture {
+	f := &schedulerFixture{
+		clock:    newFakeClock(),
+		election: &fakeElection{terms: terms},
+		times:    &fakeTimes{},
+		job:      &fakeJob{},
+	}
+	f.scheduler = NewScheduler(f.election, f.times, f.job)
+	f.scheduler.clock = f.clock
+	return f
+}
+
+// start runs the scheduler until the test ends
+func (f *schedulerFixture) start(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		f.scheduler.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-stopped
+	})
+}
+
+func (f *schedulerFixture) expectRuns(t *testing.T, want int32) {
+	t.Helper()
+	if runs := f.job.runs.Load(); runs != want {
+		t.Fatalf("job ran %d times, want %d", runs, want)
+	}
+}
+
+func TestSchedulerRunsJobAtDueTime(t *testing.T) {
+	f := newSchedulerFixture(newFakeTerm())
+	f.times.times = []time.Time{f.clock.Now().Add(30 * time.Second)}
+	f.start(t)
+
+	f.clock.expectSleep(t, 30*time.Second)
+	f.expectRuns(t, 0)
+
+	f.clock.Advance(30 * time.Second)
+	f.clock.expectSleep(t, 30*time.Second) // Until the next refresh
+	f.expectRuns(t, 1)
+}
+
+func TestSchedulerDoesNotRefirePastDueTimesUntilRefresh(t *testing.T) {
+	f := newSchedulerFixture(newFakeTerm())
+	// A reminder the job keeps leaving behind stays listed as past due
+	f.times.times = []time.Time{f.clock.Now().Add(-time.Second)}
+	f.start(t)
+
+	f.clock.expectSleep(t, refreshInterval)
+	f.expectRuns(t, 1)
+
+	f.clock.Advance(refreshInterval)
+	f.clock.expectSleep(t, refreshInterval)
+	f.expectRuns(t, 2)
+}
+
+func TestSchedulerRunsNotifiedDueTimes(t *testing.T) {
+	term := newFakeTerm()
+	f := newSchedulerFixture(term)
+	f.start(t)
+
+	f.clock.expectSleep(t, refreshInterval)
+	term.due <- f.clock.Now().Add(10 * time.Second)
+	f.clock.expectSleep(t, 10*time.Second)
+
+	f.clock.Advance(10 * time.Second)
+	f.clock.expectSleep(t, refreshInterval-10*time.Second)
+	f.expectRuns(t, 1)
+}
+
+func TestSchedulerNotifyForwardsOnlyTimesWithinLookahead(t *testing.T) {
+	f := newSchedulerFixture()
+	now := f.clock.Now()
+
+	f.scheduler.Notify(now.Add(time.Minute))
+	f.scheduler.Notify(now.Add(lookahead + time.Minute))
+
+	if len(f.election.notified) != 1 || !f.election.notified[0].Equal(now.Add(time.Minute)) {
+		t.Errorf("notified %v, want only the time within the lookahead", f.election.notified)
+	}
+}
+
+func TestSchedulerOnlyRunsWhileLeading(t *testing.T) {
+	first, second := newFakeTerm(), newFakeTerm()
+	// Another instance leads at first, then this one is elected twice
+	f := newSchedulerFixture(nil, first, second)
+	f.times.times = []time.Time{f.clock.Now().Add(time.Hour)}
+	f.start(t)
+
+	f.clock.expectSleep(t, electionInterval)
+	f.expectRuns(t, 0)
+
+	f.clock.Advance(electionInterval)
+	f.clock.expectSleep(t, refreshInterval)
+
+	// Losing the term resigns it and campaigns again after the election interval
+	close(first.lost)
+	f.clock.expectSleep(t, electionInterval)
+	if !first.resigned.Load() {
+		t.Errorf("lost term was not resigned")
+	}
+
+	f.times.mu.Lock()
+	f.times.times = []time.Time{f.clock.Now()}
+	f.times.mu.Unlock()
+	f.clock.Advance(electionInterval)
+	f.clock.expectSleep(t, refreshInterval)
+	f.expectRuns(t, 1)
+
+	f.election.mu.Lock()
+	defer f.election.mu.Unlock()
+	if f.election.campaigns != 3 {
+		t.Errorf("campaigned %d times, want 3", f.election.campaigns)
+	}
+}
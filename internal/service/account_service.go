@@ -0,0 +1,59 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/user/remind-me/backend/internal/repository"
+	apperrors "github.com/user/remind-me/backend/pkg/errors"
+)
+
+type AccountService struct {
+	uow *repository.UnitOfWork
+}
+
+func NewAccountService(uow *repository.UnitOfWork) *AccountService {
+	return &AccountService{
+		uow: uow,
+	}
+}
+
+// DeleteAccount soft-deletes a user together with their reminders, lists and devices.
+// Either everything is deleted or nothing is.
+func (s *AccountService) DeleteAccount(userID uuid.UUID) error {
+	return s.uow.Do(func(tx *repository.Repositories) error {
+		if err := tx.Reminders.DeleteByUserID(userID); err != nil {
+			return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to delete reminders", http.StatusInternalServerError)
+		}
+
+		if err := tx.ReminderLists.DeleteByUserID(userID); err != nil {
+			return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to delete reminder lists", http.StatusInternalServerError)
+		}
+
+		if err := tx.Devices.DeleteByUser(userID); err != nil {
+			return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to delete devices", http.StatusInternalServerError)
+		}
+
+		// GORM sets deleted_at
+		if err := tx.Users.Delete(userID); err != nil {
+			return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to delete user", http.StatusInternalServerError)
+		}
+
+		return nil
+	})
+}
+
+// RestoreAccount restores soft-deleted reminders and reminder lists for a user
+func (s *AccountService) RestoreAccount(userID uuid.UUID) error {
+	return s.uow.Do(func(tx *repository.Repositories) error {
+		if err := tx.Reminders.RestoreByUserID(userID); err != nil {
+			return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to restore reminders", http.StatusInternalServerError)
+		}
+
+		if err := tx.ReminderLists.RestoreByUserID(userID); err != nil {
+			return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to restore reminder lists", http.StatusInternalServerError)
+		}
+
+		return nil
+	})
+}
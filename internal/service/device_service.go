@@ -8,26 +8,38 @@ import (
 	"github.com/user/remind-me/backend/internal/dto"
 	"github.com/user/remind-me/backend/internal/models"
 	"github.com/user/remind-me/backend/internal/repository"
+	"github.com/user/remind-me/backend/internal/validation"
 	apperrors "github.com/user/remind-me/backend/pkg/errors"
 )
 
 type DeviceService struct {
 	deviceRepo *repository.DeviceRepository
 	userRepo   *repository.UserRepository
+	uow        *repository.UnitOfWork
 }
 
-func NewDeviceService(deviceRepo *repository.DeviceRepository, userRepo *repository.UserRepository) *DeviceService {
+func NewDeviceService(deviceRepo *repository.DeviceRepository, userRepo *repository.UserRepository, uow *repository.UnitOfWork) *DeviceService {
 	return &DeviceService{
 		deviceRepo: deviceRepo,
 		userRepo:   userRepo,
+		uow:        uow,
 	}
 }
 
 // Register registers a new device or updates an existing one.
 func (s *DeviceService) Register(userID uuid.UUID, req dto.RegisterDeviceRequest) (*dto.DeviceDTO, error) {
-	// Validate push token is not empty
-	if req.PushToken == "" {
-		return nil, apperrors.ValidationError("Push token is required")
+	device := &models.Device{
+		UserID:           userID,
+		DeviceIdentifier: req.DeviceIdentifier,
+		Platform:         models.Platform(req.Platform),
+		PushToken:        req.PushToken,
+		DeviceName:       req.DeviceName,
+		AppVersion:       req.AppVersion,
+		OSVersion:        req.OSVersion,
+		LastSeenAt:       time.Now(),
+	}
+	if err := validation.Device(device); err != nil {
+		return nil, err
 	}
 
 	// Check device limit for non-premium users
@@ -50,21 +62,42 @@ func (s *DeviceService) Register(userID uuid.UUID, req dto.RegisterDeviceRequest
 		}
 	}
 
-	device := &models.Device{
-		UserID:     userID,
-		Platform:   models.Platform(req.Platform),
-		PushToken:  req.PushToken,
-		DeviceName: req.DeviceName,
-		AppVersion: req.AppVersion,
-		OSVersion:  req.OSVersion,
-		LastSeenAt: time.Now(),
+	if err := s.upsert(device); err != nil {
+		return nil, err
 	}
 
-	if err := s.deviceRepo.Upsert(device); err != nil {
-		return nil, apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to register device", http.StatusInternalServerError)
+	// Reload so an updated registration reports its stored timestamps
+	return s.GetByID(userID, device.ID)
+}
+
+// Upsert stores a device registration and announces it, along with any registrations
+// it displaced, to the affected users' other devices.
+func (s *DeviceService) Upsert(device *models.Device) error {
+	if err := validation.Device(device); err != nil {
+		return err
 	}
+	return s.upsert(device)
+}
 
-	return deviceToDTO(device), nil
+func (s *DeviceService) upsert(device *models.Device) error {
+	return s.uow.Do(func(tx *repository.Repositories) error {
+		result, err := tx.Devices.Upsert(device)
+		if err != nil {
+			return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to register device", http.StatusInternalServerError)
+		}
+
+		for _, removed := range result.Removed {
+			if err := recordDeviceChange(tx, removed.UserID, removed.ID, models.SyncActionDelete); err != nil {
+				return err
+			}
+		}
+
+		action := models.SyncActionUpdate
+		if result.Created {
+			action = models.SyncActionCreate
+		}
+		return recordDeviceChange(tx, device.UserID, device.ID, action)
+	})
 }
 
 // Unregister removes a device.
@@ -72,14 +105,89 @@ func (s *DeviceService) Unregister(userID, deviceID uuid.UUID) error {
 	// Verify the device belongs to the user
 	_, err := s.deviceRepo.FindByIDAndUser(deviceID, userID)
 	if err != nil {
-		return apperrors.New(apperrors.CodeNotFound, "Device not found", http.StatusNotFound)
+		return apperrors.ErrDeviceNotFound
+	}
+
+	return s.uow.Do(func(tx *repository.Repositories) error {
+		if err := tx.Devices.Delete(deviceID); err != nil {
+			return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to unregister device", http.StatusInternalServerError)
+		}
+		return recordDeviceChange(tx, userID, deviceID, models.SyncActionDelete)
+	})
+}
+
+// UpdateQuietHours sets whether a device overrides its user's quiet hours, and with
+// what, and notifies the user's devices.
+func (s *DeviceService) UpdateQuietHours(userID, deviceID uuid.UUID, req dto.UpdateDeviceQuietHoursRequest) (*models.Device, error) {
+	if err := validation.UpdateDeviceQuietHours(req); err != nil {
+		return nil, err
+	}
+
+	device, err := s.deviceRepo.FindByIDAndUser(deviceID, userID)
+	if err != nil {
+		return nil, apperrors.ErrDeviceNotFound
 	}
 
-	if err := s.deviceRepo.Delete(deviceID); err != nil {
-		return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to unregister device", http.StatusInternalServerError)
+	device.QuietHoursOverride = req.Override
+	device.QuietHoursStart, device.QuietHoursEnd = nil, nil
+	if req.Override {
+		device.QuietHoursStart, device.QuietHoursEnd = quietHoursMinutes(req.Start, req.End)
 	}
 
-	return nil
+	err = s.uow.Do(func(tx *repository.Repositories) error {
+		if err := tx.Devices.Update(device); err != nil {
+			return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to update quiet hours", http.StatusInternalServerError)
+		}
+		return recordDeviceChange(tx, userID, deviceID, models.SyncActionUpdate)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+// DeleteStaleDevices removes devices not seen in the given number of days
+// and tells each owner's remaining devices.
+func (s *DeviceService) DeleteStaleDevices(days int) (int64, error) {
+	var count int64
+	err := s.uow.Do(func(tx *repository.Repositories) error {
+		devices, err := tx.Devices.DeleteStaleDevices(days)
+		if err != nil {
+			return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to delete stale devices", http.StatusInternalServerError)
+		}
+
+		for _, device := range devices {
+			if err := recordDeviceChange(tx, device.UserID, device.ID, models.SyncActionDelete); err != nil {
+				return err
+			}
+		}
+		count = int64(len(devices))
+		return nil
+	})
+	return count, err
+}
+
+// PruneInvalidToken removes the registrations using a push token that APNs or FCM
+// permanently rejected, and tells each owner's remaining devices. If invalidSince is
+// set, registrations updated after it are kept: the app registered the token again
+// after the provider stopped accepting it. It reports whether any were removed.
+func (s *DeviceService) PruneInvalidToken(pushToken string, invalidSince *time.Time) (bool, error) {
+	pruned := false
+	err := s.uow.Do(func(tx *repository.Repositories) error {
+		devices, err := tx.Devices.DeleteByPushToken(pushToken, invalidSince)
+		if err != nil {
+			return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to delete device", http.StatusInternalServerError)
+		}
+
+		for _, device := range devices {
+			if err := recordDeviceChange(tx, device.UserID, device.ID, models.SyncActionDelete); err != nil {
+				return err
+			}
+		}
+		pruned = len(devices) > 0
+		return nil
+	})
+	return pruned, err
 }
 
 // ListByUser returns all devices for a user.
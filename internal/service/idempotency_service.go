@@ -0,0 +1,155 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/user/remind-me/backend/internal/models"
+	apperrors "github.com/user/remind-me/backend/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const (
+	// idempotencyKeyTTL is how long a stored response can be replayed
+	idempotencyKeyTTL = 24 * time.Hour
+	// idempotencyStaleAfter is how long an in-flight reservation whose owner stopped
+	// sending heartbeats blocks retries before it is assumed abandoned
+	idempotencyStaleAfter = time.Minute
+	// idempotencyHeartbeat is how often the owner refreshes its reservation; several
+	// fit in idempotencyStaleAfter so one failed refresh doesn't lose the key
+	idempotencyHeartbeat    = 15 * time.Second
+	maxIdempotencyKeyLength = 255
+)
+
+// IdempotencyStore keeps idempotency keys and their stored responses
+type IdempotencyStore interface {
+	Reserve(entry *models.IdempotencyKey, staleAfter time.Duration) (bool, error)
+	Find(userID uuid.UUID, key string) (*models.IdempotencyKey, error)
+	Heartbeat(userID uuid.UUID, key string, owner uuid.UUID) error
+	SaveResponse(userID uuid.UUID, key string, owner uuid.UUID, response []byte) error
+	Release(userID uuid.UUID, key string, owner uuid.UUID) error
+}
+
+type IdempotencyService struct {
+	idempotencyRepo IdempotencyStore
+	staleAfter      time.Duration
+	heartbeat       time.Duration
+}
+
+func NewIdempotencyService(idempotencyRepo IdempotencyStore) *IdempotencyService {
+	return &IdempotencyService{
+		idempotencyRepo: idempotencyRepo,
+		staleAfter:      idempotencyStaleAfter,
+		heartbeat:       idempotencyHeartbeat,
+	}
+}
+
+// Reservation is an idempotency key held by a request in flight. Until it is passed to
+// Complete or Release, a heartbeat keeps retries from taking the key over, however long
+// the request runs.
+type Reservation struct {
+	userID uuid.UUID
+	key    string
+	owner  uuid.UUID
+	stop   chan struct{}
+	once   sync.Once
+}
+
+func (r *Reservation) end() {
+	r.once.Do(func() { close(r.stop) })
+}
+
+// Begin reserves an idempotency key for a request.
+// If a response was already stored for the key it is returned and the request must not be
+// executed again. Otherwise the caller holds the returned reservation and must pass it to
+// Complete or Release.
+func (s *IdempotencyService) Begin(userID uuid.UUID, key string, requestHash string) (*Reservation, []byte, error) {
+	if len(key) > maxIdempotencyKeyLength {
+		return nil, nil, apperrors.ValidationError("Idempotency key must be at most 255 characters")
+	}
+
+	owner := uuid.New()
+	reserved, err := s.idempotencyRepo.Reserve(&models.IdempotencyKey{
+		UserID:      userID,
+		Key:         key,
+		RequestHash: requestHash,
+		Owner:       &owner,
+		ExpiresAt:   time.Now().Add(idempotencyKeyTTL),
+	}, s.staleAfter)
+	if err != nil {
+		return nil, nil, apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to reserve idempotency key", http.StatusInternalServerError)
+	}
+	if reserved {
+		reservation := &Reservation{userID: userID, key: key, owner: owner, stop: make(chan struct{})}
+		go s.keepAlive(reservation)
+		return reservation, nil, nil
+	}
+
+	existing, err := s.idempotencyRepo.Find(userID, key)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			// Purged between reserve and lookup; let the client retry
+			return nil, nil, apperrors.ErrIdempotencyKeyInFlight
+		}
+		return nil, nil, apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to load idempotency key", http.StatusInternalServerError)
+	}
+
+	if existing.RequestHash != requestHash {
+		return nil, nil, apperrors.ErrIdempotencyKeyReused
+	}
+	if existing.Response == nil {
+		return nil, nil, apperrors.ErrIdempotencyKeyInFlight
+	}
+
+	return nil, existing.Response, nil
+}
+
+// keepAlive refreshes the reservation's heartbeat until it ends
+func (s *IdempotencyService) keepAlive(reservation *Reservation) {
+	ticker := time.NewTicker(s.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-reservation.stop:
+			return
+		case <-ticker.C:
+			if err := s.idempotencyRepo.Heartbeat(reservation.userID, reservation.key, reservation.owner); err != nil {
+				log.Printf("Failed to refresh idempotency key for user %s: %v", reservation.userID, err)
+			}
+		}
+	}
+}
+
+// Complete stores the response for a reservation made by Begin
+func (s *IdempotencyService) Complete(reservation *Reservation, response []byte) error {
+	reservation.end()
+	if err := s.idempotencyRepo.SaveResponse(reservation.userID, reservation.key, reservation.owner, response); err != nil {
+		return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to store idempotent response", http.StatusInternalServerError)
+	}
+	return nil
+}
+
+// Release frees a reservation made by Begin without storing a response, so a retry runs again
+func (s *IdempotencyService) Release(reservation *Reservation) error {
+	reservation.end()
+	if err := s.idempotencyRepo.Release(reservation.userID, reservation.key, reservation.owner); err != nil {
+		return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to release idempotency key", http.StatusInternalServerError)
+	}
+	return nil
+}
+
+// HashRequest fingerprints a request so a key reused for a different request is rejected
+func HashRequest(parts ...[]byte) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write(p)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
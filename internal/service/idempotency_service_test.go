@@ -0,0 +1,121 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/user/remind-me/backend/internal/service/servicetest"
+	apperrors "github.com/user/remind-me/backend/pkg/errors"
+)
+
+func newTestIdempotencyService(staleAfter, heartbeat time.Duration) (*IdempotencyService, *servicetest.IdempotencyKeys) {
+	store := servicetest.NewIdempotencyKeys()
+	s := NewIdempotencyService(store)
+	s.staleAfter, s.heartbeat = staleAfter, heartbeat
+	return s, store
+}
+
+func mustBegin(t *testing.T, s *IdempotencyService, userID uuid.UUID, key, hash string) *Reservation {
+	t.Helper()
+	reservation, stored, err := s.Begin(userID, key, hash)
+	if err != nil || stored != nil || reservation == nil {
+		t.Fatalf("Begin = %v, %q, %v; want a reservation", reservation, stored, err)
+	}
+	return reservation
+}
+
+func TestIdempotencyReplaysCompletedResponse(t *testing.T) {
+	s, _ := newTestIdempotencyService(time.Minute, time.Minute)
+	userID := uuid.New()
+
+	reservation := mustBegin(t, s, userID, "key-1", "hash")
+	if err := s.Complete(reservation, []byte(`{"ok":true}`)); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+
+	again, stored, err := s.Begin(userID, "key-1", "hash")
+	if err != nil || again != nil || string(stored) != `{"ok":true}` {
+		t.Errorf("retry = %v, %q, %v; want the stored response", again, stored, err)
+	}
+
+	// Keys are scoped to the user
+	other := mustBegin(t, s, uuid.New(), "key-1", "hash")
+	s.Release(other)
+}
+
+func TestIdempotencyRejectsConflicts(t *testing.T) {
+	s, _ := newTestIdempotencyService(time.Minute, time.Minute)
+	userID := uuid.New()
+
+	reservation := mustBegin(t, s, userID, "key-1", "hash")
+	defer s.Release(reservation)
+
+	if _, _, err := s.Begin(userID, "key-1", "hash"); !errors.Is(err, apperrors.ErrIdempotencyKeyInFlight) {
+		t.Errorf("Begin while in flight = %v, want %v", err, apperrors.ErrIdempotencyKeyInFlight)
+	}
+	if _, _, err := s.Begin(userID, "key-1", "other-hash"); !errors.Is(err, apperrors.ErrIdempotencyKeyReused) {
+		t.Errorf("Begin with another request = %v, want %v", err, apperrors.ErrIdempotencyKeyReused)
+	}
+
+	long := string(make([]byte, maxIdempotencyKeyLength+1))
+	if _, _, err := s.Begin(userID, long, "hash"); apperrors.GetAppError(err) == nil || apperrors.GetAppError(err).Code != apperrors.CodeValidationError {
+		t.Errorf("Begin with a long key = %v, want a validation error", err)
+	}
+}
+
+func TestIdempotencyReleaseAllowsRetry(t *testing.T) {
+	s, store := newTestIdempotencyService(time.Minute, time.Minute)
+	userID := uuid.New()
+
+	if err := s.Release(mustBegin(t, s, userID, "key-1", "hash")); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if store.Len() != 0 {
+		t.Errorf("%d keys stored after release, want 0", store.Len())
+	}
+	s.Release(mustBegin(t, s, userID, "key-1", "hash"))
+}
+
+func TestIdempotencyHeartbeatKeepsLongRequestsReserved(t *testing.T) {
+	s, _ := newTestIdempotencyService(50*time.Millisecond, 10*time.Millisecond)
+	userID := uuid.New()
+
+	reservation := mustBegin(t, s, userID, "key-1", "hash")
+	time.Sleep(200 * time.Millisecond) // Several stale windows
+
+	if _, _, err := s.Begin(userID, "key-1", "hash"); !errors.Is(err, apperrors.ErrIdempotencyKeyInFlight) {
+		t.Fatalf("Begin during a long request = %v, want %v", err, apperrors.ErrIdempotencyKeyInFlight)
+	}
+	if err := s.Complete(reservation, []byte(`{}`)); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+}
+
+func TestIdempotencyTakesOverAbandonedReservations(t *testing.T) {
+	s, store := newTestIdempotencyService(20*time.Millisecond, time.Hour) // The owner never heartbeats
+	userID := uuid.New()
+
+	abandoned := mustBegin(t, s, userID, "key-1", "hash")
+	time.Sleep(50 * time.Millisecond)
+	retry := mustBegin(t, s, userID, "key-1", "hash")
+
+	// The original owner can no longer store a response or release the retry's key
+	if err := s.Complete(abandoned, []byte(`{"stale":true}`)); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if err := s.Release(abandoned); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if entry, err := store.Find(userID, "key-1"); err != nil || entry.Response != nil {
+		t.Fatalf("entry = %+v, %v; want the retry's reservation still in flight", entry, err)
+	}
+
+	if err := s.Complete(retry, []byte(`{"ok":true}`)); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if _, stored, _ := s.Begin(userID, "key-1", "hash"); string(stored) != `{"ok":true}` {
+		t.Errorf("stored response = %q, want the retry's", stored)
+	}
+}
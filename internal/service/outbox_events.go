@@ -0,0 +1,81 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/user/remind-me/backend/internal/dto"
+	"github.com/user/remind-me/backend/internal/models"
+	"github.com/user/remind-me/backend/internal/notification"
+	"github.com/user/remind-me/backend/internal/repository"
+	apperrors "github.com/user/remind-me/backend/pkg/errors"
+)
+
+// recordReminderChange writes the sync event and the subscription broadcast for a
+// reminder change in the same transaction as the change itself
+func recordReminderChange(tx *repository.Repositories, userID uuid.UUID, reminder *models.Reminder, action models.SyncAction, deviceID *uuid.UUID) error {
+	return recordReminderMove(tx, userID, reminder, reminder.ListID, action, deviceID)
+}
+
+// recordReminderMove is recordReminderChange for a change that may have moved the reminder
+// out of previousListID, so subscribers watching that list still hear about it
+func recordReminderMove(tx *repository.Repositories, userID uuid.UUID, reminder *models.Reminder, previousListID *uuid.UUID, action models.SyncAction, deviceID *uuid.UUID) error {
+	if err := tx.Sync.RecordReminderChange(userID, reminder, action, deviceID); err != nil {
+		return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to record sync event", http.StatusInternalServerError)
+	}
+
+	payload := dto.ReminderChangedPayload{
+		Action:     string(action),
+		ReminderID: reminder.ID,
+		ListID:     reminder.ListID,
+	}
+	if previousListID != nil && (reminder.ListID == nil || *reminder.ListID != *previousListID) {
+		payload.PreviousListID = previousListID
+	}
+	if action != models.SyncActionDelete {
+		reminderDTO := dto.ReminderToDTO(reminder)
+		payload.Reminder = &reminderDTO
+	}
+	return enqueue(tx, userID, models.OutboxKindReminderChanged, payload)
+}
+
+// recordReminderListChange writes the subscription broadcast for a reminder list change
+func recordReminderListChange(tx *repository.Repositories, userID uuid.UUID, list *models.ReminderList, action models.SyncAction) error {
+	payload := dto.ReminderListChangedPayload{
+		Action:         string(action),
+		ReminderListID: list.ID,
+	}
+	if action != models.SyncActionDelete {
+		count, err := tx.ReminderLists.GetReminderCountForList(list.ID)
+		if err != nil {
+			return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to count reminders", http.StatusInternalServerError)
+		}
+		listDTO := dto.ReminderListToDTO(list, count)
+		payload.ReminderList = &listDTO
+	}
+	return enqueue(tx, userID, models.OutboxKindReminderListChanged, payload)
+}
+
+// recordCrossDeviceAction queues a silent push telling the user's other devices about an action
+func recordCrossDeviceAction(tx *repository.Repositories, userID, reminderID uuid.UUID, action notification.CrossDeviceAction, deviceID *uuid.UUID) error {
+	return enqueue(tx, userID, models.OutboxKindCrossDeviceAction, dto.CrossDeviceActionPayload{
+		Action:         string(action),
+		ReminderID:     reminderID,
+		SourceDeviceID: deviceID,
+	})
+}
+
+// recordDeviceChange writes the subscription broadcast for a device registration change
+func recordDeviceChange(tx *repository.Repositories, userID, deviceID uuid.UUID, action models.SyncAction) error {
+	return enqueue(tx, userID, models.OutboxKindDeviceChanged, dto.DeviceChangedPayload{
+		Action:   string(action),
+		DeviceID: deviceID,
+	})
+}
+
+func enqueue(tx *repository.Repositories, userID uuid.UUID, kind models.OutboxKind, payload interface{}) error {
+	if err := tx.Outbox.Enqueue(userID, kind, payload); err != nil {
+		return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to queue change notification", http.StatusInternalServerError)
+	}
+	return nil
+}
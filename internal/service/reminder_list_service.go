@@ -6,26 +6,35 @@ import (
 	"github.com/google/uuid"
 	"github.com/user/remind-me/backend/internal/dto"
 	"github.com/user/remind-me/backend/internal/models"
+	"github.com/user/remind-me/backend/internal/notification"
 	"github.com/user/remind-me/backend/internal/repository"
+	"github.com/user/remind-me/backend/internal/validation"
 	apperrors "github.com/user/remind-me/backend/pkg/errors"
 )
 
 type ReminderListService struct {
 	listRepo     *repository.ReminderListRepository
 	reminderRepo *repository.ReminderRepository
+	uow          *repository.UnitOfWork
 }
 
 func NewReminderListService(
 	listRepo *repository.ReminderListRepository,
 	reminderRepo *repository.ReminderRepository,
+	uow *repository.UnitOfWork,
 ) *ReminderListService {
 	return &ReminderListService{
 		listRepo:     listRepo,
 		reminderRepo: reminderRepo,
+		uow:          uow,
 	}
 }
 
 func (s *ReminderListService) Create(userID uuid.UUID, req dto.CreateReminderListRequest) (*dto.ReminderListDTO, error) {
+	if err := validation.CreateReminderList(req); err != nil {
+		return nil, err
+	}
+
 	// Get the count to determine sort order
 	count, _ := s.listRepo.CountByUser(userID)
 
@@ -38,8 +47,14 @@ func (s *ReminderListService) Create(userID uuid.UUID, req dto.CreateReminderLis
 		IsDefault: false,
 	}
 
-	if err := s.listRepo.Create(list); err != nil {
-		return nil, apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to create list", http.StatusInternalServerError)
+	err := s.uow.Do(func(tx *repository.Repositories) error {
+		if err := tx.ReminderLists.Create(list); err != nil {
+			return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to create list", http.StatusInternalServerError)
+		}
+		return recordReminderListChange(tx, userID, list, models.SyncActionCreate)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	result := dto.ReminderListToDTO(list, 0)
@@ -76,6 +91,10 @@ func (s *ReminderListService) List(userID uuid.UUID) ([]dto.ReminderListDTO, err
 }
 
 func (s *ReminderListService) Update(userID, listID uuid.UUID, req dto.UpdateReminderListRequest) (*dto.ReminderListDTO, error) {
+	if err := validation.UpdateReminderList(req); err != nil {
+		return nil, err
+	}
+
 	list, err := s.listRepo.FindByIDAndUser(listID, userID)
 	if err != nil {
 		return nil, apperrors.ErrReminderListNotFound
@@ -95,8 +114,14 @@ func (s *ReminderListService) Update(userID, listID uuid.UUID, req dto.UpdateRem
 		list.SortOrder = *req.SortOrder
 	}
 
-	if err := s.listRepo.Update(list); err != nil {
-		return nil, apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to update list", http.StatusInternalServerError)
+	err = s.uow.Do(func(tx *repository.Repositories) error {
+		if err := tx.ReminderLists.Update(list); err != nil {
+			return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to update list", http.StatusInternalServerError)
+		}
+		return recordReminderListChange(tx, userID, list, models.SyncActionUpdate)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	count, _ := s.listRepo.GetReminderCountForList(list.ID)
@@ -115,17 +140,34 @@ func (s *ReminderListService) Delete(userID, listID uuid.UUID) error {
 		return apperrors.ErrCannotDeleteDefaultList
 	}
 
-	// Cascade delete all reminders in this list
-	if err := s.listRepo.DeleteRemindersByListID(listID); err != nil {
-		return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to delete reminders", http.StatusInternalServerError)
-	}
-
-	// Soft delete the list
-	if err := s.listRepo.SoftDelete(listID); err != nil {
-		return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to delete list", http.StatusInternalServerError)
-	}
-
-	return nil
+	return s.uow.Do(func(tx *repository.Repositories) error {
+		// Cascade delete all reminders in this list
+		reminders, err := tx.ReminderLists.DeleteRemindersByListID(listID)
+		if err != nil {
+			return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to delete reminders", http.StatusInternalServerError)
+		}
+
+		// Soft delete the list
+		if err := tx.ReminderLists.SoftDelete(listID); err != nil {
+			return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to delete list", http.StatusInternalServerError)
+		}
+
+		for i := range reminders {
+			reminder := &reminders[i]
+			if err := recordReminderChange(tx, userID, reminder, models.SyncActionDelete, nil); err != nil {
+				return err
+			}
+			if reminder.Status != models.StatusActive && reminder.Status != models.StatusSnoozed {
+				continue
+			}
+			// Dismiss the notification on every device
+			if err := recordCrossDeviceAction(tx, userID, reminder.ID, notification.ActionDelete, nil); err != nil {
+				return err
+			}
+		}
+
+		return recordReminderListChange(tx, userID, list, models.SyncActionDelete)
+	})
 }
 
 func (s *ReminderListService) Reorder(userID uuid.UUID, listIDs []uuid.UUID) ([]dto.ReminderListDTO, error) {
@@ -135,9 +177,25 @@ func (s *ReminderListService) Reorder(userID uuid.UUID, listIDs []uuid.UUID) ([]
 		idOrders[id] = i
 	}
 
-	// Update all sort orders
-	if err := s.listRepo.UpdateSortOrders(userID, idOrders); err != nil {
-		return nil, apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to reorder lists", http.StatusInternalServerError)
+	err := s.uow.Do(func(tx *repository.Repositories) error {
+		// Update all sort orders
+		if err := tx.ReminderLists.UpdateSortOrders(userID, idOrders); err != nil {
+			return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to reorder lists", http.StatusInternalServerError)
+		}
+
+		lists, err := tx.ReminderLists.ListByUser(userID)
+		if err != nil {
+			return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to list reminder lists", http.StatusInternalServerError)
+		}
+		for i := range lists {
+			if err := recordReminderListChange(tx, userID, &lists[i], models.SyncActionUpdate); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Return the updated list
@@ -155,11 +213,6 @@ func (s *ReminderListService) EnsureDefaultList(userID uuid.UUID) (*dto.Reminder
 	return &result, nil
 }
 
-// DeleteAllByUser soft-deletes all reminder lists belonging to a user
-func (s *ReminderListService) DeleteAllByUser(userID uuid.UUID) error {
-	return s.listRepo.DeleteByUserID(userID)
-}
-
 func defaultString(ptr *string, defaultVal string) string {
 	if ptr == nil || *ptr == "" {
 		return defaultVal
@@ -8,7 +8,9 @@ import (
 	"github.com/google/uuid"
 	"github.com/user/remind-me/backend/internal/dto"
 	"github.com/user/remind-me/backend/internal/models"
+	"github.com/user/remind-me/backend/internal/notification"
 	"github.com/user/remind-me/backend/internal/repository"
+	"github.com/user/remind-me/backend/internal/validation"
 	apperrors "github.com/user/remind-me/backend/pkg/errors"
 )
 
@@ -16,21 +18,28 @@ type ReminderService struct {
 	reminderRepo *repository.ReminderRepository
 	syncRepo     *repository.SyncRepository
 	userRepo     *repository.UserRepository
+	uow          *repository.UnitOfWork
 }
 
 func NewReminderService(
 	reminderRepo *repository.ReminderRepository,
 	syncRepo *repository.SyncRepository,
 	userRepo *repository.UserRepository,
+	uow *repository.UnitOfWork,
 ) *ReminderService {
 	return &ReminderService{
 		reminderRepo: reminderRepo,
 		syncRepo:     syncRepo,
 		userRepo:     userRepo,
+		uow:          uow,
 	}
 }
 
 func (s *ReminderService) Create(userID uuid.UUID, req dto.CreateReminderRequest, deviceID *uuid.UUID) (*dto.ReminderDTO, error) {
+	if err := validation.CreateReminder(req); err != nil {
+		return nil, err
+	}
+
 	// Check for duplicate local ID
 	if req.LocalID != nil {
 		existing, err := s.reminderRepo.FindByLocalID(userID, *req.LocalID)
@@ -83,13 +92,16 @@ func (s *ReminderService) Create(userID uuid.UUID, req dto.CreateReminderRequest
 		reminder.Tags = models.StringArray{}
 	}
 
-	if err := s.reminderRepo.Create(reminder); err != nil {
-		return nil, apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to create reminder", http.StatusInternalServerError)
+	err := s.uow.Do(func(tx *repository.Repositories) error {
+		if err := tx.Reminders.Create(reminder); err != nil {
+			return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to create reminder", http.StatusInternalServerError)
+		}
+		return recordReminderChange(tx, userID, reminder, models.SyncActionCreate, deviceID)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Record sync event
-	_ = s.syncRepo.RecordReminderChange(userID, reminder, models.SyncActionCreate, deviceID)
-
 	result := dto.ReminderToDTO(reminder)
 	return &result, nil
 }
@@ -136,6 +148,10 @@ func (s *ReminderService) List(userID uuid.UUID, page, pageSize int, status *str
 }
 
 func (s *ReminderService) Update(userID, reminderID uuid.UUID, req dto.UpdateReminderRequest, deviceID *uuid.UUID) (*dto.ReminderDTO, error) {
+	if err := validation.UpdateReminder(req); err != nil {
+		return nil, err
+	}
+
 	reminder, err := s.reminderRepo.FindByIDAndUser(reminderID, userID)
 	if err != nil {
 		return nil, apperrors.ErrReminderNotFound
@@ -152,6 +168,9 @@ func (s *ReminderService) Update(userID, reminderID uuid.UUID, req dto.UpdateRem
 		}
 	}
 
+	previousListID := reminder.ListID
+	previousStatus := reminder.Status
+
 	// Apply updates
 	if req.ListID != nil {
 		reminder.ListID = req.ListID
@@ -192,13 +211,23 @@ func (s *ReminderService) Update(userID, reminderID uuid.UUID, req dto.UpdateRem
 
 	reminder.LastModifiedBy = deviceID
 
-	if err := s.reminderRepo.Update(reminder); err != nil {
-		return nil, apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to update reminder", http.StatusInternalServerError)
+	err = s.uow.Do(func(tx *repository.Repositories) error {
+		if err := tx.Reminders.Update(reminder); err != nil {
+			return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to update reminder", http.StatusInternalServerError)
+		}
+		if err := recordReminderMove(tx, userID, reminder, previousListID, models.SyncActionUpdate, deviceID); err != nil {
+			return err
+		}
+		if reminder.Status == models.StatusCompleted && previousStatus != models.StatusCompleted {
+			// Completed by editing; dismiss the notification on other devices as Complete does
+			return recordCrossDeviceAction(tx, userID, reminderID, notification.ActionComplete, deviceID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Record sync event
-	_ = s.syncRepo.RecordReminderChange(userID, reminder, models.SyncActionUpdate, deviceID)
-
 	result := dto.ReminderToDTO(reminder)
 	return &result, nil
 }
@@ -209,14 +238,16 @@ func (s *ReminderService) Delete(userID, reminderID uuid.UUID, deviceID *uuid.UU
 		return apperrors.ErrReminderNotFound
 	}
 
-	if err := s.reminderRepo.SoftDelete(reminderID); err != nil {
-		return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to delete reminder", http.StatusInternalServerError)
-	}
-
-	// Record sync event
-	_ = s.syncRepo.RecordReminderChange(userID, reminder, models.SyncActionDelete, deviceID)
-
-	return nil
+	return s.uow.Do(func(tx *repository.Repositories) error {
+		if err := tx.Reminders.SoftDelete(reminderID); err != nil {
+			return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to delete reminder", http.StatusInternalServerError)
+		}
+		if err := recordReminderChange(tx, userID, reminder, models.SyncActionDelete, deviceID); err != nil {
+			return err
+		}
+		// Dismiss the notification on other devices
+		return recordCrossDeviceAction(tx, userID, reminderID, notification.ActionDelete, deviceID)
+	})
 }
 
 func (s *ReminderService) Snooze(userID, reminderID uuid.UUID, minutes int, deviceID *uuid.UUID) (*dto.ReminderDTO, error) {
@@ -237,16 +268,24 @@ func (s *ReminderService) Snooze(userID, reminderID uuid.UUID, minutes int, devi
 	}
 
 	until := time.Now().Add(time.Duration(minutes) * time.Minute)
-	if err := s.reminderRepo.Snooze(reminderID, until, deviceID); err != nil {
-		return nil, apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to snooze reminder", http.StatusInternalServerError)
+	err = s.uow.Do(func(tx *repository.Repositories) error {
+		if err := tx.Reminders.Snooze(reminderID, until, deviceID); err != nil {
+			return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to snooze reminder", http.StatusInternalServerError)
+		}
+		reminder, err = reloadReminder(tx, reminderID)
+		if err != nil {
+			return err
+		}
+		if err := recordReminderChange(tx, userID, reminder, models.SyncActionUpdate, deviceID); err != nil {
+			return err
+		}
+		// Dismiss the notification on other devices
+		return recordCrossDeviceAction(tx, userID, reminderID, notification.ActionSnooze, deviceID)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Reload the reminder
-	reminder, _ = s.reminderRepo.FindByID(reminderID)
-
-	// Record sync event
-	_ = s.syncRepo.RecordReminderChange(userID, reminder, models.SyncActionUpdate, deviceID)
-
 	result := dto.ReminderToDTO(reminder)
 	return &result, nil
 }
@@ -257,16 +296,24 @@ func (s *ReminderService) Complete(userID, reminderID uuid.UUID, deviceID *uuid.
 		return nil, apperrors.ErrReminderNotFound
 	}
 
-	if err := s.reminderRepo.Complete(reminderID, deviceID); err != nil {
-		return nil, apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to complete reminder", http.StatusInternalServerError)
+	err = s.uow.Do(func(tx *repository.Repositories) error {
+		if err := tx.Reminders.Complete(reminderID, deviceID); err != nil {
+			return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to complete reminder", http.StatusInternalServerError)
+		}
+		reminder, err = reloadReminder(tx, reminderID)
+		if err != nil {
+			return err
+		}
+		if err := recordReminderChange(tx, userID, reminder, models.SyncActionUpdate, deviceID); err != nil {
+			return err
+		}
+		// Dismiss the notification on other devices
+		return recordCrossDeviceAction(tx, userID, reminderID, notification.ActionComplete, deviceID)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Reload the reminder
-	reminder, _ = s.reminderRepo.FindByID(reminderID)
-
-	// Record sync event
-	_ = s.syncRepo.RecordReminderChange(userID, reminder, models.SyncActionUpdate, deviceID)
-
 	result := dto.ReminderToDTO(reminder)
 	return &result, nil
 }
@@ -277,20 +324,65 @@ func (s *ReminderService) Dismiss(userID, reminderID uuid.UUID, deviceID *uuid.U
 		return apperrors.ErrReminderNotFound
 	}
 
-	if err := s.reminderRepo.Dismiss(reminderID, deviceID); err != nil {
-		return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to dismiss reminder", http.StatusInternalServerError)
+	return s.uow.Do(func(tx *repository.Repositories) error {
+		if err := tx.Reminders.Dismiss(reminderID, deviceID); err != nil {
+			return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to dismiss reminder", http.StatusInternalServerError)
+		}
+		// Reload to get updated status
+		reminder, err = reloadReminder(tx, reminderID)
+		if err != nil {
+			return err
+		}
+		if err := recordReminderChange(tx, userID, reminder, models.SyncActionUpdate, deviceID); err != nil {
+			return err
+		}
+		// Dismiss the notification on other devices
+		return recordCrossDeviceAction(tx, userID, reminderID, notification.ActionDismiss, deviceID)
+	})
+}
+
+// MarkNotified records that the notification for the reminder's current due time has
+// been pushed and tells the user's devices, so they can drop the matching local
+// notification
+func (s *ReminderService) MarkNotified(reminder *models.Reminder) error {
+	err := s.uow.Do(func(tx *repository.Repositories) error {
+		if err := tx.Reminders.MarkNotificationSent(reminder.ID); err != nil {
+			return err
+		}
+		return tx.Outbox.Enqueue(reminder.UserID, models.OutboxKindReminderNotified, dto.ReminderNotifiedPayload{
+			ReminderID: reminder.ID,
+			DueAt:      reminder.DueAt,
+			NotifiedAt: time.Now(),
+		})
+	})
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to mark reminder notified", http.StatusInternalServerError)
 	}
+	return nil
+}
 
-	// Record sync event (reload to get updated status)
-	reminder, _ = s.reminderRepo.FindByID(reminderID)
-	_ = s.syncRepo.RecordReminderChange(userID, reminder, models.SyncActionUpdate, deviceID)
+// BadgeCount returns the number shown on the app icon badge of the user's devices: their
+// active reminders that are overdue or due later today in their timezone
+func (s *ReminderService) BadgeCount(userID uuid.UUID) (int, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return 0, apperrors.ErrUserNotFound
+	}
 
-	return nil
+	count, err := s.reminderRepo.CountDueBefore(userID, user.EndOfDay(time.Now()))
+	if err != nil {
+		return 0, apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to count reminders", http.StatusInternalServerError)
+	}
+	return int(count), nil
 }
 
-// DeleteAllByUser soft-deletes all reminders belonging to a user
-func (s *ReminderService) DeleteAllByUser(userID uuid.UUID) error {
-	return s.reminderRepo.DeleteByUserID(userID)
+// reloadReminder reads back a reminder after a partial column update
+func reloadReminder(tx *repository.Repositories, reminderID uuid.UUID) (*models.Reminder, error) {
+	reminder, err := tx.Reminders.FindByID(reminderID)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to reload reminder", http.StatusInternalServerError)
+	}
+	return reminder, nil
 }
 
 // isPresetSnooze checks if the snooze duration is a free preset
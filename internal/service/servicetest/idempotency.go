@@ -0,0 +1,110 @@
+// Package servicetest provides in-memory stores for exercising services without a
+// database
+package servicetest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/user/remind-me/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type idempotencyKeyID struct {
+	userID uuid.UUID
+	key    string
+}
+
+// IdempotencyKeys is an in-memory service.IdempotencyStore with the same reservation
+// rules as the repository
+type IdempotencyKeys struct {
+	mu      sync.Mutex
+	entries map[idempotencyKeyID]models.IdempotencyKey
+}
+
+// NewIdempotencyKeys creates an empty store
+func NewIdempotencyKeys() *IdempotencyKeys {
+	return &IdempotencyKeys{entries: make(map[idempotencyKeyID]models.IdempotencyKey)}
+}
+
+func (s *IdempotencyKeys) Reserve(entry *models.IdempotencyKey, staleAfter time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	id := idempotencyKeyID{entry.UserID, entry.Key}
+	if existing, ok := s.entries[id]; ok {
+		stale := existing.Response == nil && existing.HeartbeatAt.Before(now.Add(-staleAfter))
+		if !existing.ExpiresAt.Before(now) && !stale {
+			return false, nil
+		}
+	}
+
+	reserved := *entry
+	reserved.Response = nil
+	reserved.HeartbeatAt, reserved.CreatedAt = now, now
+	s.entries[id] = reserved
+	return true, nil
+}
+
+func (s *IdempotencyKeys) Find(userID uuid.UUID, key string) (*models.IdempotencyKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[idempotencyKeyID{userID, key}]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &entry, nil
+}
+
+func (s *IdempotencyKeys) Heartbeat(userID uuid.UUID, key string, owner uuid.UUID) error {
+	s.update(userID, key, owner, func(entry *models.IdempotencyKey) {
+		if entry.Response == nil {
+			entry.HeartbeatAt = time.Now()
+		}
+	})
+	return nil
+}
+
+func (s *IdempotencyKeys) SaveResponse(userID uuid.UUID, key string, owner uuid.UUID, response []byte) error {
+	s.update(userID, key, owner, func(entry *models.IdempotencyKey) {
+		entry.Response = response
+	})
+	return nil
+}
+
+func (s *IdempotencyKeys) Release(userID uuid.UUID, key string, owner uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := idempotencyKeyID{userID, key}
+	if entry, ok := s.entries[id]; ok && ownedBy(entry, owner) && entry.Response == nil {
+		delete(s.entries, id)
+	}
+	return nil
+}
+
+// Len returns how many keys are stored, in flight or completed
+func (s *IdempotencyKeys) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.entries)
+}
+
+func (s *IdempotencyKeys) update(userID uuid.UUID, key string, owner uuid.UUID, apply func(entry *models.IdempotencyKey)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := idempotencyKeyID{userID, key}
+	if entry, ok := s.entries[id]; ok && ownedBy(entry, owner) {
+		apply(&entry)
+		s.entries[id] = entry
+	}
+}
+
+func ownedBy(entry models.IdempotencyKey, owner uuid.UUID) bool {
+	return entry.Owner != nil && *entry.Owner == owner
+}
@@ -9,6 +9,8 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/user/remind-me/backend/internal/config"
+	"github.com/user/remind-me/backend/internal/dto"
+	"github.com/user/remind-me/backend/internal/models"
 	"github.com/user/remind-me/backend/internal/repository"
 	apperrors "github.com/user/remind-me/backend/pkg/errors"
 )
@@ -52,12 +54,14 @@ type RevenueCatResponse struct {
 type SubscriptionService struct {
 	config   *config.Config
 	userRepo *repository.UserRepository
+	uow      *repository.UnitOfWork
 }
 
-func NewSubscriptionService(cfg *config.Config, userRepo *repository.UserRepository) *SubscriptionService {
+func NewSubscriptionService(cfg *config.Config, userRepo *repository.UserRepository, uow *repository.UnitOfWork) *SubscriptionService {
 	return &SubscriptionService{
 		config:   cfg,
 		userRepo: userRepo,
+		uow:      uow,
 	}
 }
 
@@ -94,9 +98,15 @@ func (s *SubscriptionService) VerifySubscription(userID uuid.UUID) (bool, *time.
 		// If ExpiresDate is nil, it's a lifetime purchase - premiumUntil stays nil
 	}
 
-	// Update user's premium status in database
-	if err := s.userRepo.UpdatePremiumStatus(userID, isPremium, premiumUntil); err != nil {
-		return false, nil, apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to update premium status", http.StatusInternalServerError)
+	// Update user's premium status in database and notify the user's devices
+	err = s.uow.Do(func(tx *repository.Repositories) error {
+		if err := tx.Users.UpdatePremiumStatus(userID, isPremium, premiumUntil); err != nil {
+			return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to update premium status", http.StatusInternalServerError)
+		}
+		return enqueue(tx, userID, models.OutboxKindUserChanged, dto.UserChangedPayload{Action: string(models.SyncActionUpdate)})
+	})
+	if err != nil {
+		return false, nil, err
 	}
 
 	return isPremium, premiumUntil, nil
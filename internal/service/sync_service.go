@@ -1,11 +1,19 @@
 package service
 
 import (
+	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/user/remind-me/backend/internal/dto"
 	"github.com/user/remind-me/backend/internal/repository"
+	apperrors "github.com/user/remind-me/backend/pkg/errors"
+)
+
+const (
+	defaultSyncPageSize = 100
+	maxSyncPageSize     = 500
 )
 
 type SyncService struct {
@@ -20,17 +28,57 @@ func NewSyncService(syncRepo *repository.SyncRepository, reminderRepo *repositor
 	}
 }
 
-// GetChangesSince returns all sync events since the given timestamp.
-func (s *SyncService) GetChangesSince(userID uuid.UUID, since time.Time, limit int) (*dto.SyncResponse, error) {
-	events, hasMore, err := s.syncRepo.GetChangesSince(userID, since, limit)
+// GetChangesSince returns sync events after the given cursor.
+// An empty cursor starts from the beginning of the retained history. If the cursor
+// predates the retained window, no changes are returned and ResyncRequired is set
+// together with the current head cursor the client should resume from after a full fetch.
+func (s *SyncService) GetChangesSince(userID uuid.UUID, cursor string, limit int) (*dto.SyncResponse, error) {
+	if limit < 1 {
+		limit = defaultSyncPageSize
+	}
+	if limit > maxSyncPageSize {
+		limit = maxSyncPageSize
+	}
+
+	afterSeq, err := ParseSyncCursor(cursor)
 	if err != nil {
 		return nil, err
 	}
 
+	prunedThrough, err := s.syncRepo.GetPrunedThroughSeq(userID)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to read sync watermark", http.StatusInternalServerError)
+	}
+	if cursor == "" {
+		afterSeq = prunedThrough
+	}
+
+	if afterSeq < prunedThrough {
+		head, err := s.syncRepo.GetLatestSeq(userID)
+		if err != nil {
+			return nil, apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to read sync cursor", http.StatusInternalServerError)
+		}
+		if head < prunedThrough {
+			head = prunedThrough
+		}
+		return &dto.SyncResponse{
+			Changes:        []dto.SyncEvent{},
+			LastSyncAt:     time.Now(),
+			Cursor:         FormatSyncCursor(head),
+			ResyncRequired: true,
+		}, nil
+	}
+
+	events, hasMore, err := s.syncRepo.GetChangesAfter(userID, afterSeq, limit)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to load changes", http.StatusInternalServerError)
+	}
+
 	syncEvents := make([]dto.SyncEvent, len(events))
 	for i, e := range events {
 		syncEvents[i] = dto.SyncEvent{
 			ID:         e.ID,
+			Seq:        e.Seq,
 			EntityType: string(e.EntityType),
 			EntityID:   e.EntityID,
 			Action:     string(e.Action),
@@ -40,10 +88,15 @@ func (s *SyncService) GetChangesSince(userID uuid.UUID, since time.Time, limit i
 		}
 	}
 
+	nextSeq := afterSeq
+	if len(events) > 0 {
+		nextSeq = events[len(events)-1].Seq
+	}
+	next := FormatSyncCursor(nextSeq)
+
 	var nextCursor *string
-	if hasMore && len(events) > 0 {
-		cursor := events[len(events)-1].CreatedAt.Format(time.RFC3339Nano)
-		nextCursor = &cursor
+	if hasMore {
+		nextCursor = &next
 	}
 
 	return &dto.SyncResponse{
@@ -51,5 +104,24 @@ func (s *SyncService) GetChangesSince(userID uuid.UUID, since time.Time, limit i
 		LastSyncAt: time.Now(),
 		HasMore:    hasMore,
 		NextCursor: nextCursor,
+		Cursor:     next,
 	}, nil
 }
+
+// FormatSyncCursor encodes a sync event sequence as an opaque client cursor
+func FormatSyncCursor(seq int64) string {
+	return strconv.FormatInt(seq, 10)
+}
+
+// ParseSyncCursor decodes a client cursor back to a sync event sequence.
+// An empty cursor parses as 0; GetChangesSince starts it at the retained history.
+func ParseSyncCursor(cursor string) (int64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	seq, err := strconv.ParseInt(cursor, 10, 64)
+	if err != nil || seq < 0 {
+		return 0, apperrors.ValidationError("Invalid sync cursor")
+	}
+	return seq, nil
+}
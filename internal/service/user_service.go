@@ -5,18 +5,22 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/user/remind-me/backend/internal/dto"
 	"github.com/user/remind-me/backend/internal/models"
 	"github.com/user/remind-me/backend/internal/repository"
+	"github.com/user/remind-me/backend/internal/validation"
 	apperrors "github.com/user/remind-me/backend/pkg/errors"
 )
 
 type UserService struct {
 	userRepo *repository.UserRepository
+	uow      *repository.UnitOfWork
 }
 
-func NewUserService(userRepo *repository.UserRepository) *UserService {
+func NewUserService(userRepo *repository.UserRepository, uow *repository.UnitOfWork) *UserService {
 	return &UserService{
 		userRepo: userRepo,
+		uow:      uow,
 	}
 }
 
@@ -55,10 +59,103 @@ func (s *UserService) Update(id uuid.UUID, displayName, timezone *string) (*mode
 	if err := s.userRepo.Update(user); err != nil {
 		return nil, apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to update user", http.StatusInternalServerError)
 	}
+	if timezone != nil {
+		// The digest is sent at a local time
+		if err := s.userRepo.SetNextDigestAt(id, user.NextDigestAt(time.Now())); err != nil {
+			return nil, apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to schedule daily digest", http.StatusInternalServerError)
+		}
+	}
+
+	return user, nil
+}
+
+// UpdateQuietHours sets a user's quiet hours, and optionally their timezone, and
+// notifies the user's devices. A new timezone reschedules the user's daily digest.
+func (s *UserService) UpdateQuietHours(id uuid.UUID, req dto.UpdateQuietHoursRequest) (*models.User, error) {
+	if err := validation.UpdateQuietHours(req); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(id)
+	if err != nil {
+		return nil, apperrors.ErrUserNotFound
+	}
+
+	user.QuietHoursStart, user.QuietHoursEnd = quietHoursMinutes(req.Start, req.End)
+	if req.Timezone != nil {
+		user.Timezone = *req.Timezone
+	}
 
+	err = s.uow.Do(func(tx *repository.Repositories) error {
+		if err := tx.Users.Update(user); err != nil {
+			return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to update quiet hours", http.StatusInternalServerError)
+		}
+		if req.Timezone != nil {
+			// The digest is sent at a local time
+			if err := tx.Users.SetNextDigestAt(id, user.NextDigestAt(time.Now())); err != nil {
+				return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to schedule daily digest", http.StatusInternalServerError)
+			}
+		}
+		return enqueue(tx, id, models.OutboxKindUserChanged, dto.UserChangedPayload{Action: string(models.SyncActionUpdate)})
+	})
+	if err != nil {
+		return nil, err
+	}
 	return user, nil
 }
 
+// UpdateDigestSettings sets a user's daily digest preferences, schedules their next
+// digest and notifies the user's devices.
+func (s *UserService) UpdateDigestSettings(id uuid.UUID, req dto.UpdateDigestSettingsRequest) (*models.User, error) {
+	if err := validation.UpdateDigestSettings(req); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(id)
+	if err != nil {
+		return nil, apperrors.ErrUserNotFound
+	}
+
+	user.DigestEnabled = req.Enabled
+	if req.Time != nil {
+		user.DigestTime, _ = models.ParseTimeOfDay(*req.Time)
+	}
+	if req.Days != nil {
+		user.DigestDays = models.DigestDaysMask(req.Days)
+	}
+	if req.ListIDs != nil {
+		user.DigestListIDs = make(models.StringArray, len(req.ListIDs))
+		for i, listID := range req.ListIDs {
+			user.DigestListIDs[i] = listID.String()
+		}
+	}
+
+	err = s.uow.Do(func(tx *repository.Repositories) error {
+		if err := tx.Users.Update(user); err != nil {
+			return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to update daily digest", http.StatusInternalServerError)
+		}
+		if err := tx.Users.SetNextDigestAt(id, user.NextDigestAt(time.Now())); err != nil {
+			return apperrors.Wrap(err, apperrors.CodeInternalError, "Failed to schedule daily digest", http.StatusInternalServerError)
+		}
+		return enqueue(tx, id, models.OutboxKindUserChanged, dto.UserChangedPayload{Action: string(models.SyncActionUpdate)})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// quietHoursMinutes converts validated HH:MM quiet hours to the minutes stored on users
+// and devices
+func quietHoursMinutes(start, end *string) (*int, *int) {
+	if start == nil || end == nil {
+		return nil, nil
+	}
+	startMinute, _ := models.ParseTimeOfDay(*start)
+	endMinute, _ := models.ParseTimeOfDay(*end)
+	return &startMinute, &endMinute
+}
+
 // UpdatePremiumStatus updates a user's premium subscription status.
 func (s *UserService) UpdatePremiumStatus(id uuid.UUID, isPremium bool, premiumUntil *time.Time) error {
 	if err := s.userRepo.UpdatePremiumStatus(id, isPremium, premiumUntil); err != nil {
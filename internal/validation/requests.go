@@ -0,0 +1,215 @@
+package validation
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/user/remind-me/backend/internal/dto"
+	"github.com/user/remind-me/backend/internal/models"
+)
+
+// Column limits, mirroring the gorm size tags on the models
+const (
+	maxTitleLength            = 500
+	maxListNameLength         = 100
+	maxIconNameLength         = 50
+	maxSoundIDLength          = 50
+	maxLocalIDLength          = 255
+	maxDeviceIdentifierLength = 255
+	maxDeviceNameLength       = 255
+	maxVersionLength          = 20
+)
+
+// Limits on fields whose columns are unbounded (tags and digest lists are a text[], recurrence rules jsonb)
+const (
+	maxDigestLists        = 100
+	maxTags               = 20
+	maxTagLength          = 50
+	maxRecurrenceInterval = 999
+)
+
+// CreateReminder validates a reminder creation request
+func CreateReminder(req dto.CreateReminderRequest) error {
+	v := &Validator{}
+	v.Required("title", req.Title)
+	v.MaxLength("title", req.Title, maxTitleLength)
+	v.reminderFields(req.Priority, req.RecurrenceRule, req.SoundID, req.Tags)
+	if req.LocalID != nil {
+		v.MaxLength("local_id", *req.LocalID, maxLocalIDLength)
+	}
+	return v.Err()
+}
+
+// UpdateReminder validates a reminder update request. Omitted fields are left alone.
+func UpdateReminder(req dto.UpdateReminderRequest) error {
+	v := &Validator{}
+	if req.Title != nil {
+		v.Required("title", *req.Title)
+		v.MaxLength("title", *req.Title, maxTitleLength)
+	}
+	v.reminderFields(req.Priority, req.RecurrenceRule, req.SoundID, req.Tags)
+	if req.Status != nil {
+		switch models.ReminderStatus(*req.Status) {
+		case models.StatusActive, models.StatusCompleted, models.StatusSnoozed, models.StatusDismissed:
+		default:
+			v.Add("status", "must be one of active, completed, snoozed, dismissed")
+		}
+	}
+	return v.Err()
+}
+
+// reminderFields checks the fields shared by reminder create and update requests
+func (v *Validator) reminderFields(priority *int, rule *models.RecurrenceRule, soundID *string, tags []string) {
+	if priority != nil {
+		v.Range("priority", *priority, int(models.PriorityLow), int(models.PriorityHigh))
+	}
+	if rule != nil {
+		v.recurrenceRule("recurrence_rule", rule)
+	}
+	if soundID != nil {
+		v.MaxLength("sound_id", *soundID, maxSoundIDLength)
+	}
+
+	v.Check(len(tags) <= maxTags, "tags", fmt.Sprintf("must have at most %d tags", maxTags))
+	for i, tag := range tags {
+		field := fmt.Sprintf("tags[%d]", i)
+		v.Required(field, tag)
+		v.MaxLength(field, tag, maxTagLength)
+	}
+}
+
+func (v *Validator) recurrenceRule(field string, rule *models.RecurrenceRule) {
+	switch rule.Frequency {
+	case models.FrequencyHourly, models.FrequencyDaily, models.FrequencyWeekly, models.FrequencyMonthly, models.FrequencyYearly:
+	default:
+		v.Add(field+".frequency", "must be one of hourly, daily, weekly, monthly, yearly")
+	}
+	v.Range(field+".interval", rule.Interval, 1, maxRecurrenceInterval)
+
+	seen := make(map[int]bool, len(rule.DaysOfWeek))
+	for i, day := range rule.DaysOfWeek {
+		dayField := fmt.Sprintf("%s.days_of_week[%d]", field, i)
+		v.Range(dayField, day, 0, 6)
+		v.Check(!seen[day], dayField, "must not repeat a day")
+		seen[day] = true
+	}
+	if rule.DayOfMonth != nil {
+		v.Range(field+".day_of_month", *rule.DayOfMonth, 1, 31)
+	}
+	if rule.MonthOfYear != nil {
+		v.Range(field+".month_of_year", *rule.MonthOfYear, 1, 12)
+	}
+	if rule.EndAfterOccurrences != nil {
+		v.Min(field+".end_after_occurrences", *rule.EndAfterOccurrences, 1)
+	}
+}
+
+// CreateReminderList validates a reminder list creation request
+func CreateReminderList(req dto.CreateReminderListRequest) error {
+	v := &Validator{}
+	v.Required("name", req.Name)
+	v.MaxLength("name", req.Name, maxListNameLength)
+	if req.ColorHex != nil {
+		v.ColorHex("color_hex", *req.ColorHex)
+	}
+	if req.IconName != nil {
+		v.IconName("icon_name", *req.IconName)
+	}
+	return v.Err()
+}
+
+// UpdateReminderList validates a reminder list update request. Omitted fields are left alone.
+func UpdateReminderList(req dto.UpdateReminderListRequest) error {
+	v := &Validator{}
+	if req.Name != nil {
+		v.Required("name", *req.Name)
+		v.MaxLength("name", *req.Name, maxListNameLength)
+	}
+	if req.ColorHex != nil {
+		v.ColorHex("color_hex", *req.ColorHex)
+	}
+	if req.IconName != nil {
+		v.IconName("icon_name", *req.IconName)
+	}
+	if req.SortOrder != nil {
+		v.Min("sort_order", *req.SortOrder, 0)
+	}
+	return v.Err()
+}
+
+// Device validates a device registration before it is stored
+func Device(device *models.Device) error {
+	v := &Validator{}
+	v.Required("device_identifier", device.DeviceIdentifier)
+	v.MaxLength("device_identifier", device.DeviceIdentifier, maxDeviceIdentifierLength)
+	switch device.Platform {
+	case models.PlatformIOS, models.PlatformAndroid:
+	default:
+		v.Add("platform", "must be one of ios, android")
+	}
+	v.Required("push_token", device.PushToken)
+	v.MaxLength("device_name", device.DeviceName, maxDeviceNameLength)
+	v.MaxLength("app_version", device.AppVersion, maxVersionLength)
+	v.MaxLength("os_version", device.OSVersion, maxVersionLength)
+	return v.Err()
+}
+
+// UpdateQuietHours validates a change to a user's quiet hours
+func UpdateQuietHours(req dto.UpdateQuietHoursRequest) error {
+	v := &Validator{}
+	v.quietHours(req.Start, req.End)
+	if req.Timezone != nil {
+		if _, err := time.LoadLocation(*req.Timezone); err != nil || *req.Timezone == "" || *req.Timezone == "Local" {
+			v.Add("timezone", "must be an IANA time zone name")
+		}
+	}
+	return v.Err()
+}
+
+// UpdateDeviceQuietHours validates a change to a device's quiet hours
+func UpdateDeviceQuietHours(req dto.UpdateDeviceQuietHoursRequest) error {
+	v := &Validator{}
+	if req.Override {
+		v.quietHours(req.Start, req.End)
+	}
+	return v.Err()
+}
+
+// UpdateDigestSettings validates a change to a user's daily digest preferences
+func UpdateDigestSettings(req dto.UpdateDigestSettingsRequest) error {
+	v := &Validator{}
+	if req.Time != nil {
+		_, err := models.ParseTimeOfDay(*req.Time)
+		v.Check(err == nil, "time", "must be a time of day as HH:MM")
+	}
+	if req.Days != nil {
+		v.Check(len(req.Days) > 0, "days", "must include at least one day")
+		seen := make(map[int]bool, len(req.Days))
+		for i, day := range req.Days {
+			dayField := fmt.Sprintf("days[%d]", i)
+			v.Range(dayField, day, 0, 6)
+			v.Check(!seen[day], dayField, "must not repeat a day")
+			seen[day] = true
+		}
+	}
+	v.Check(len(req.ListIDs) <= maxDigestLists, "list_ids", fmt.Sprintf("must have at most %d lists", maxDigestLists))
+	return v.Err()
+}
+
+func (v *Validator) quietHours(start, end *string) {
+	if (start == nil) != (end == nil) {
+		v.Add("quiet_hours", "start and end must be set together")
+		return
+	}
+	if start == nil {
+		return
+	}
+
+	startMinute, startErr := models.ParseTimeOfDay(*start)
+	endMinute, endErr := models.ParseTimeOfDay(*end)
+	v.Check(startErr == nil, "start", "must be a time of day as HH:MM")
+	v.Check(endErr == nil, "end", "must be a time of day as HH:MM")
+	if startErr == nil && endErr == nil {
+		v.Check(startMinute != endMinute, "end", "must differ from start")
+	}
+}
@@ -0,0 +1,137 @@
+package validation
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/user/remind-me/backend/internal/dto"
+	"github.com/user/remind-me/backend/internal/models"
+	apperrors "github.com/user/remind-me/backend/pkg/errors"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// invalidFields returns the fields err reports as invalid, or nil if err is nil
+func invalidFields(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	appErr := apperrors.GetAppError(err)
+	if appErr == nil || appErr.Code != apperrors.CodeValidationError {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	fields := make([]string, len(appErr.Fields))
+	for i, field := range appErr.Fields {
+		fields[i] = field.Field
+	}
+	return slices.Compact(fields)
+}
+
+func assertFields(t *testing.T, err error, want []string) {
+	t.Helper()
+	if got := invalidFields(t, err); !slices.Equal(got, want) {
+		t.Errorf("invalid fields = %v, want %v", got, want)
+	}
+}
+
+func TestCreateReminder(t *testing.T) {
+	tests := []struct {
+		name string
+		req  dto.CreateReminderRequest
+		want []string
+	}{
+		{name: "minimal", req: dto.CreateReminderRequest{Title: "Buy milk"}},
+		{
+			name: "all fields",
+			req: dto.CreateReminderRequest{
+				Title:          "Water plants",
+				Priority:       ptr(int(models.PriorityHigh)),
+				RecurrenceRule: &models.RecurrenceRule{Frequency: models.FrequencyWeekly, Interval: 1, DaysOfWeek: []int{1, 3}},
+				SoundID:        ptr("ambient.wav"),
+				Tags:           []string{"home", "garden"},
+				LocalID:        ptr("local-1"),
+			},
+		},
+		{name: "blank title", req: dto.CreateReminderRequest{Title: "  "}, want: []string{"title"}},
+		{name: "title too long", req: dto.CreateReminderRequest{Title: strings.Repeat("a", maxTitleLength+1)}, want: []string{"title"}},
+		{name: "title at limit in runes", req: dto.CreateReminderRequest{Title: strings.Repeat("é", maxTitleLength)}},
+		{name: "priority below range", req: dto.CreateReminderRequest{Title: "x", Priority: ptr(0)}, want: []string{"priority"}},
+		{name: "priority above range", req: dto.CreateReminderRequest{Title: "x", Priority: ptr(4)}, want: []string{"priority"}},
+		{name: "sound id too long", req: dto.CreateReminderRequest{Title: "x", SoundID: ptr(strings.Repeat("s", maxSoundIDLength+1))}, want: []string{"sound_id"}},
+		{name: "too many tags", req: dto.CreateReminderRequest{Title: "x", Tags: slices.Repeat([]string{"tag"}, maxTags+1)}, want: []string{"tags"}},
+		{name: "blank and long tags", req: dto.CreateReminderRequest{Title: "x", Tags: []string{"ok", "", strings.Repeat("t", maxTagLength+1)}}, want: []string{"tags[1]", "tags[2]"}},
+		{name: "local id too long", req: dto.CreateReminderRequest{Title: "x", LocalID: ptr(strings.Repeat("l", maxLocalIDLength+1))}, want: []string{"local_id"}},
+		{name: "invalid recurrence rule", req: dto.CreateReminderRequest{Title: "x", RecurrenceRule: &models.RecurrenceRule{Frequency: models.FrequencyDaily}}, want: []string{"recurrence_rule.interval"}},
+		{name: "every error reported", req: dto.CreateReminderRequest{Priority: ptr(9), LocalID: ptr(strings.Repeat("l", maxLocalIDLength+1))}, want: []string{"title", "priority", "local_id"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertFields(t, CreateReminder(tt.req), tt.want)
+		})
+	}
+}
+
+func TestUpdateReminder(t *testing.T) {
+	tests := []struct {
+		name string
+		req  dto.UpdateReminderRequest
+		want []string
+	}{
+		{name: "empty", req: dto.UpdateReminderRequest{}},
+		{name: "title", req: dto.UpdateReminderRequest{Title: ptr("Renamed")}},
+		{name: "blank title", req: dto.UpdateReminderRequest{Title: ptr("")}, want: []string{"title"}},
+		{name: "title too long", req: dto.UpdateReminderRequest{Title: ptr(strings.Repeat("a", maxTitleLength+1))}, want: []string{"title"}},
+		{name: "priority", req: dto.UpdateReminderRequest{Priority: ptr(int(models.PriorityLow))}},
+		{name: "priority out of range", req: dto.UpdateReminderRequest{Priority: ptr(-1)}, want: []string{"priority"}},
+		{name: "completed status", req: dto.UpdateReminderRequest{Status: ptr(string(models.StatusCompleted))}},
+		{name: "unknown status", req: dto.UpdateReminderRequest{Status: ptr("archived")}, want: []string{"status"}},
+		{name: "status is case sensitive", req: dto.UpdateReminderRequest{Status: ptr("ACTIVE")}, want: []string{"status"}},
+		{name: "invalid recurrence rule", req: dto.UpdateReminderRequest{RecurrenceRule: &models.RecurrenceRule{Frequency: "fortnightly", Interval: 1}}, want: []string{"recurrence_rule.frequency"}},
+		{name: "long tag", req: dto.UpdateReminderRequest{Tags: []string{strings.Repeat("t", maxTagLength+1)}}, want: []string{"tags[0]"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertFields(t, UpdateReminder(tt.req), tt.want)
+		})
+	}
+}
+
+func TestRecurrenceRule(t *testing.T) {
+	tests := []struct {
+		name string
+		rule models.RecurrenceRule
+		want []string
+	}{
+		{name: "daily", rule: models.RecurrenceRule{Frequency: models.FrequencyDaily, Interval: 1}},
+		{name: "every weekday", rule: models.RecurrenceRule{Frequency: models.FrequencyWeekly, Interval: 1, DaysOfWeek: []int{1, 2, 3, 4, 5}}},
+		{name: "whole week", rule: models.RecurrenceRule{Frequency: models.FrequencyWeekly, Interval: 1, DaysOfWeek: []int{0, 1, 2, 3, 4, 5, 6}}},
+		{name: "monthly on the last possible day", rule: models.RecurrenceRule{Frequency: models.FrequencyMonthly, Interval: 1, DayOfMonth: ptr(31)}},
+		{name: "yearly", rule: models.RecurrenceRule{Frequency: models.FrequencyYearly, Interval: 1, MonthOfYear: ptr(12), DayOfMonth: ptr(25)}},
+		{name: "maximum interval", rule: models.RecurrenceRule{Frequency: models.FrequencyHourly, Interval: maxRecurrenceInterval}},
+		{name: "unknown frequency", rule: models.RecurrenceRule{Frequency: "fortnightly", Interval: 1}, want: []string{"r.frequency"}},
+		{name: "missing frequency", rule: models.RecurrenceRule{Interval: 1}, want: []string{"r.frequency"}},
+		{name: "zero interval", rule: models.RecurrenceRule{Frequency: models.FrequencyDaily, Interval: 0}, want: []string{"r.interval"}},
+		{name: "negative interval", rule: models.RecurrenceRule{Frequency: models.FrequencyDaily, Interval: -2}, want: []string{"r.interval"}},
+		{name: "interval too large", rule: models.RecurrenceRule{Frequency: models.FrequencyDaily, Interval: maxRecurrenceInterval + 1}, want: []string{"r.interval"}},
+		{name: "day below range", rule: models.RecurrenceRule{Frequency: models.FrequencyWeekly, Interval: 1, DaysOfWeek: []int{-1}}, want: []string{"r.days_of_week[0]"}},
+		{name: "day above range", rule: models.RecurrenceRule{Frequency: models.FrequencyWeekly, Interval: 1, DaysOfWeek: []int{1, 7}}, want: []string{"r.days_of_week[1]"}},
+		{name: "repeated day", rule: models.RecurrenceRule{Frequency: models.FrequencyWeekly, Interval: 1, DaysOfWeek: []int{1, 3, 1}}, want: []string{"r.days_of_week[2]"}},
+		{name: "day of month out of range", rule: models.RecurrenceRule{Frequency: models.FrequencyMonthly, Interval: 1, DayOfMonth: ptr(0)}, want: []string{"r.day_of_month"}},
+		{name: "month of year out of range", rule: models.RecurrenceRule{Frequency: models.FrequencyYearly, Interval: 1, MonthOfYear: ptr(13)}, want: []string{"r.month_of_year"}},
+		{name: "no occurrences", rule: models.RecurrenceRule{Frequency: models.FrequencyDaily, Interval: 1, EndAfterOccurrences: ptr(0)}, want: []string{"r.end_after_occurrences"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &Validator{}
+			v.recurrenceRule("r", &tt.rule)
+			assertFields(t, v.Err(), tt.want)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+// Package validation checks request inputs against the limits of the columns they are
+// stored in, so bad input is rejected with VALIDATION_ERROR and per-field details
+// instead of failing in Postgres.
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	apperrors "github.com/user/remind-me/backend/pkg/errors"
+)
+
+var (
+	colorHexPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+	iconNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+(\.[A-Za-z0-9_-]+)*$`)
+)
+
+// Validator collects the field errors found while checking one input
+type Validator struct {
+	fields []apperrors.FieldError
+}
+
+// Add records an error for field
+func (v *Validator) Add(field, message string) {
+	v.fields = append(v.fields, apperrors.FieldError{Field: field, Message: message})
+}
+
+// Check records an error for field unless ok
+func (v *Validator) Check(ok bool, field, message string) {
+	if !ok {
+		v.Add(field, message)
+	}
+}
+
+// Required checks that value is not blank
+func (v *Validator) Required(field, value string) {
+	v.Check(strings.TrimSpace(value) != "", field, "is required")
+}
+
+// MaxLength checks that value has at most max characters
+func (v *Validator) MaxLength(field, value string, max int) {
+	v.Check(utf8.RuneCountInString(value) <= max, field, fmt.Sprintf("must be at most %d characters", max))
+}
+
+// Range checks that min <= value <= max
+func (v *Validator) Range(field string, value, min, max int) {
+	v.Check(value >= min && value <= max, field, fmt.Sprintf("must be between %d and %d", min, max))
+}
+
+// Min checks that value >= min
+func (v *Validator) Min(field string, value, min int) {
+	v.Check(value >= min, field, fmt.Sprintf("must be at least %d", min))
+}
+
+// ColorHex checks that value is a #RRGGBB color
+func (v *Validator) ColorHex(field, value string) {
+	v.Check(colorHexPattern.MatchString(value), field, "must be a hex color like #007AFF")
+}
+
+// IconName checks that value is a dotted symbol name such as "list.bullet"
+func (v *Validator) IconName(field, value string) {
+	if !iconNamePattern.MatchString(value) {
+		v.Add(field, "must be a symbol name like list.bullet")
+		return
+	}
+	v.MaxLength(field, value, maxIconNameLength)
+}
+
+// Valid reports whether no errors were recorded
+func (v *Validator) Valid() bool {
+	return len(v.fields) == 0
+}
+
+// Err returns a VALIDATION_ERROR listing every recorded field, or nil if the input is valid
+func (v *Validator) Err() error {
+	if v.Valid() {
+		return nil
+	}
+	return apperrors.InvalidFieldsError("Invalid input", v.fields...)
+}
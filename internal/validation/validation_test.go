@@ -0,0 +1,66 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestColorHex(t *testing.T) {
+	tests := []struct {
+		value string
+		valid bool
+	}{
+		{"#007AFF", true},
+		{"#ff9500", true},
+		{"#000000", true},
+		{"007AFF", false},
+		{"#007AF", false},
+		{"#007AFF0", false},
+		{"#GG7AFF", false},
+		{"#fff", false},
+		{"", false},
+		{" #007AFF", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			v := &Validator{}
+			v.ColorHex("color_hex", tt.value)
+			if v.Valid() != tt.valid {
+				t.Errorf("ColorHex(%q) valid = %t, want %t", tt.value, v.Valid(), tt.valid)
+			}
+		})
+	}
+}
+
+func TestIconName(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		valid bool
+	}{
+		{"plain", "folder", true},
+		{"dotted", "list.bullet", true},
+		{"dashes and digits", "calendar.badge-clock.2", true},
+		{"at length limit", strings.Repeat("a", maxIconNameLength), true},
+		{"dotted at length limit", strings.Repeat("a.", maxIconNameLength/2-1) + "ab", true},
+		{"over length limit", strings.Repeat("a", maxIconNameLength+1), false},
+		{"dotted over length limit", strings.Repeat("ab.", maxIconNameLength/3+1) + "ab", false},
+		{"empty", "", false},
+		{"leading dot", ".list", false},
+		{"trailing dot", "list.", false},
+		{"double dot", "list..bullet", false},
+		{"space", "list bullet", false},
+		{"path", "../list", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &Validator{}
+			v.IconName("icon_name", tt.value)
+			if v.Valid() != tt.valid {
+				t.Errorf("IconName(%q) valid = %t, want %t", tt.value, v.Valid(), tt.valid)
+			}
+		})
+	}
+}
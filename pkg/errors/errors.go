@@ -8,10 +8,17 @@ import (
 
 // AppError represents an application-level error
 type AppError struct {
-	Code       string `json:"code"`
-	Message    string `json:"message"`
-	StatusCode int    `json:"-"`
-	Err        error  `json:"-"`
+	Code       string       `json:"code"`
+	Message    string       `json:"message"`
+	Fields     []FieldError `json:"fields,omitempty"`
+	StatusCode int          `json:"-"`
+	Err        error        `json:"-"`
+}
+
+// FieldError describes why one input field was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
 }
 
 func (e *AppError) Error() string {
@@ -44,6 +51,9 @@ const (
 	CodePremiumRequired         = "PREMIUM_REQUIRED"
 	CodeDeviceLimitExceeded     = "DEVICE_LIMIT_EXCEEDED"
 	CodeCannotDeleteDefaultList = "CANNOT_DELETE_DEFAULT_LIST"
+	CodeIdempotencyKeyReused    = "IDEMPOTENCY_KEY_REUSED"
+	CodeIdempotencyKeyInFlight  = "IDEMPOTENCY_KEY_IN_FLIGHT"
+	CodeEventsDropped           = "EVENTS_DROPPED"
 )
 
 // Common errors
@@ -131,6 +141,18 @@ var (
 		Message:    "Cannot delete the default list",
 		StatusCode: http.StatusForbidden,
 	}
+
+	ErrIdempotencyKeyReused = &AppError{
+		Code:       CodeIdempotencyKeyReused,
+		Message:    "Idempotency key was already used for a different request",
+		StatusCode: http.StatusUnprocessableEntity,
+	}
+
+	ErrIdempotencyKeyInFlight = &AppError{
+		Code:       CodeIdempotencyKeyInFlight,
+		Message:    "A request with this idempotency key is still being processed",
+		StatusCode: http.StatusConflict,
+	}
 )
 
 // New creates a new AppError
@@ -161,6 +183,16 @@ func ValidationError(message string) *AppError {
 	}
 }
 
+// InvalidFieldsError creates a validation error listing the offending input fields
+func InvalidFieldsError(message string, fields ...FieldError) *AppError {
+	return &AppError{
+		Code:       CodeValidationError,
+		Message:    message,
+		Fields:     fields,
+		StatusCode: http.StatusBadRequest,
+	}
+}
+
 // SyncConflictError creates a sync conflict error
 func SyncConflictError(message string) *AppError {
 	return &AppError{